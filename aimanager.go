@@ -0,0 +1,205 @@
+package simconnect
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// createObjectTimeout is the maximum time for waiting the object ID of a created object
+const createObjectTimeout = 10 * time.Second
+
+// AIManager create the AI objects and remove them on Close. It track the objects added and removed in the
+// simulator with the system events ObjectAdded and ObjectRemoved (the objects existing before the manager are unknown).
+//
+//	ai := esc.NewAIManager()
+//	defer ai.Close()
+//	objectID, err := ai.CreateParkedATCAircraft("Cessna Skyhawk", "N1234", "KSEA")
+//	ai.SetFlightPlan(objectID, "C:\\flights\\KSEA-KPDX")
+type AIManager struct {
+	esc      *EasySimConnect
+	mutex    sync.Mutex
+	spawned  map[uint32]bool
+	live     map[uint32]uint32
+	cAdded   <-chan ObjectEvent
+	cRemoved <-chan ObjectEvent
+	creating sync.WaitGroup // the creations waiting their object ID
+	closed   bool
+	done     chan struct{}
+	once     sync.Once
+}
+
+// NewAIManager return an AIManager, Close remove the objects created. The objects are not removed by the Close of
+// EasySimConnect, the caller must call AIManager.Close before.
+func (esc *EasySimConnect) NewAIManager() *AIManager {
+	m := &AIManager{
+		esc:      esc,
+		spawned:  make(map[uint32]bool),
+		live:     make(map[uint32]uint32),
+		cAdded:   esc.ConnectSysEventObjectAdded(),
+		cRemoved: esc.ConnectSysEventObjectRemoved(),
+		done:     make(chan struct{}),
+	}
+	go m.track()
+	return m
+}
+
+// CreateParkedATCAircraft create an aircraft parked at the airport (ex: "KSEA") and return his object ID
+func (m *AIManager) CreateParkedATCAircraft(title string, tailNumber string, airportID string) (uint32, error) {
+	return m.create("AICreateParkedATCAircraft", title, func(requestID uint32) (error, uint32) {
+		return m.esc.sc.AICreateParkedATCAircraft(title, tailNumber, airportID, requestID)
+	})
+}
+
+// CreateEnrouteATCAircraft create an aircraft following the flight plan (path without extension) at the position
+// flightPlanPosition (ex: 0.5 is the middle of the first leg) and return his object ID
+func (m *AIManager) CreateEnrouteATCAircraft(title string, tailNumber string, flightNumber int, flightPlanPath string, flightPlanPosition float64, touchAndGo bool) (uint32, error) {
+	touch := uint32(0)
+	if touchAndGo {
+		touch = 1
+	}
+	return m.create("AICreateEnrouteATCAircraft", title, func(requestID uint32) (error, uint32) {
+		return m.esc.sc.AICreateEnrouteATCAircraft(title, tailNumber, flightNumber, flightPlanPath, flightPlanPosition, touch, requestID)
+	})
+}
+
+// CreateNonATCAircraft create an aircraft at the position without ATC and return his object ID
+func (m *AIManager) CreateNonATCAircraft(title string, tailNumber string, position SIMCONNECT_DATA_INITPOSITION) (uint32, error) {
+	return m.create("AICreateNonATCAircraft", title, func(requestID uint32) (error, uint32) {
+		return m.esc.sc.AICreateNonATCAircraft(title, tailNumber, position, requestID)
+	})
+}
+
+// CreateSimulatedObject create an object (ex: a vehicle, a boat or an animal) at the position and return his object ID
+func (m *AIManager) CreateSimulatedObject(title string, position SIMCONNECT_DATA_INITPOSITION) (uint32, error) {
+	return m.create("AICreateSimulatedObject", title, func(requestID uint32) (error, uint32) {
+		return m.esc.sc.AICreateSimulatedObject(title, position, requestID)
+	})
+}
+
+// SetFlightPlan give a flight plan (path without extension) to an aircraft created by the manager
+func (m *AIManager) SetFlightPlan(objectID uint32, flightPlanPath string) error {
+	return m.esc.callWait("AISetAircraftFlightPlan", flightPlanPath, func() (error, uint32) {
+		return m.esc.sc.AISetAircraftFlightPlan(objectID, flightPlanPath, 0)
+	})
+}
+
+// ReleaseControl give the control of a non ATC aircraft to the AI of the simulator, the object is still removed by Close
+func (m *AIManager) ReleaseControl(objectID uint32) error {
+	return m.esc.callWait("AIReleaseControl", fmt.Sprint(objectID), func() (error, uint32) {
+		return m.esc.sc.AIReleaseControl(objectID, 0)
+	})
+}
+
+// Remove remove an object created by the manager
+func (m *AIManager) Remove(objectID uint32) error {
+	err := m.esc.callWait("AIRemoveObject", fmt.Sprint(objectID), func() (error, uint32) {
+		return m.esc.sc.AIRemoveObject(objectID, 0)
+	})
+	if err != nil {
+		// the object is kept for Close
+		return err
+	}
+	m.mutex.Lock()
+	delete(m.spawned, objectID)
+	m.mutex.Unlock()
+	return nil
+}
+
+// Objects return the object IDs created by the manager and not removed
+func (m *AIManager) Objects() []uint32 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	objectIDs := make([]uint32, 0, len(m.spawned))
+	for objectID := range m.spawned {
+		objectIDs = append(objectIDs, objectID)
+	}
+	sort.Slice(objectIDs, func(i, j int) bool { return objectIDs[i] < objectIDs[j] })
+	return objectIDs
+}
+
+// LiveObjects return the type (SIMCONNECT_SIMOBJECT_TYPE_*) of the objects added in the simulator and not removed, by object ID
+func (m *AIManager) LiveObjects() map[uint32]uint32 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	live := make(map[uint32]uint32, len(m.live))
+	for objectID, objectType := range m.live {
+		live[objectID] = objectType
+	}
+	return live
+}
+
+// Close remove all the objects created by the manager and stop the tracking. Call Close more than once do nothing.
+func (m *AIManager) Close() error {
+	var err error
+	m.once.Do(func() {
+		// the objects of the creations in progress are removed too
+		m.mutex.Lock()
+		m.closed = true
+		m.mutex.Unlock()
+		m.creating.Wait()
+		for _, objectID := range m.Objects() {
+			if e := m.Remove(objectID); e != nil && err == nil {
+				err = e
+			}
+		}
+		close(m.done)
+		for _, c := range []<-chan ObjectEvent{m.cAdded, m.cRemoved} {
+			if sub, e := m.esc.Subscription(c); e == nil {
+				sub.Unsubscribe()
+			}
+		}
+	})
+	return err
+}
+
+// create send the request and wait the object ID of SIMCONNECT_RECV_ASSIGNED_OBJECT_ID
+func (m *AIManager) create(op string, title string, f func(requestID uint32) (error, uint32)) (uint32, error) {
+	m.mutex.Lock()
+	if m.closed {
+		m.mutex.Unlock()
+		return 0, errors.New("AIManager is closed")
+	}
+	m.creating.Add(1)
+	m.mutex.Unlock()
+	defer m.creating.Done()
+	recv, err := m.esc.callRequest(op, title, createObjectTimeout, f)
+	if err != nil {
+		return 0, err
+	}
+	objectID := recv.(*AssignedObjectIDMessage).ObjectID
+	m.mutex.Lock()
+	m.spawned[objectID] = true
+	m.mutex.Unlock()
+	return objectID, nil
+}
+
+// track keep the objects of ObjectAdded and ObjectRemoved
+func (m *AIManager) track() {
+	for {
+		select {
+		case event, ok := <-m.cAdded:
+			if !ok {
+				return
+			}
+			m.mutex.Lock()
+			m.live[event.ObjectID] = event.ObjectType
+			m.mutex.Unlock()
+		case event, ok := <-m.cRemoved:
+			if !ok {
+				return
+			}
+			m.mutex.Lock()
+			delete(m.live, event.ObjectID)
+			// the object can be removed by the simulator (ex: a crash)
+			delete(m.spawned, event.ObjectID)
+			m.mutex.Unlock()
+		case <-m.done:
+			return
+		case <-m.esc.ctx.Done():
+			return
+		}
+	}
+}
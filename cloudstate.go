@@ -0,0 +1,135 @@
+package simconnect
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+)
+
+// CloudGrid is the cloud density of a zone in SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH x SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH
+// cells, 0 is no cloud and 255 the densest. The row 0 is the minimum latitude and the column 0 the minimum longitude.
+type CloudGrid struct {
+	MinLatitude  float32 // degrees
+	MinLongitude float32
+	MinAltitude  float32 // feet
+	MaxLatitude  float32
+	MaxLongitude float32
+	MaxAltitude  float32
+	Density      [SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH][SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH]byte
+}
+
+// newCloudGrid decode the data of SIMCONNECT_RECV_CLOUD_STATE for the zone
+func newCloudGrid(data []byte, minLat float32, minLon float32, minAlt float32, maxLat float32, maxLon float32, maxAlt float32) (*CloudGrid, error) {
+	if len(data) != SIMCONNECT_CLOUD_STATE_ARRAY_SIZE {
+		return nil, fmt.Errorf("cloud state of %d bytes, want %d", len(data), SIMCONNECT_CLOUD_STATE_ARRAY_SIZE)
+	}
+	if !(maxLat > minLat) || !(maxLon > minLon) {
+		return nil, fmt.Errorf("cloud state zone %g %g %g %g is empty", minLat, minLon, maxLat, maxLon)
+	}
+	g := &CloudGrid{
+		MinLatitude:  minLat,
+		MinLongitude: minLon,
+		MinAltitude:  minAlt,
+		MaxLatitude:  maxLat,
+		MaxLongitude: maxLon,
+		MaxAltitude:  maxAlt,
+	}
+	for row := range g.Density {
+		copy(g.Density[row][:], data[row*SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH:])
+	}
+	return g, nil
+}
+
+// Cell return the row and the column of the position, ok is false outside the zone or if the zone is empty
+func (g *CloudGrid) Cell(latitude float32, longitude float32) (row int, col int, ok bool) {
+	if !(g.MaxLatitude > g.MinLatitude) || !(g.MaxLongitude > g.MinLongitude) {
+		return 0, 0, false
+	}
+	// NaN is outside the zone
+	if !(latitude >= g.MinLatitude && latitude <= g.MaxLatitude && longitude >= g.MinLongitude && longitude <= g.MaxLongitude) {
+		return 0, 0, false
+	}
+	row = int((latitude - g.MinLatitude) / (g.MaxLatitude - g.MinLatitude) * SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH)
+	col = int((longitude - g.MinLongitude) / (g.MaxLongitude - g.MinLongitude) * SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH)
+	// the maximum is in the last cell
+	if row == SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH {
+		row--
+	}
+	if col == SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH {
+		col--
+	}
+	return row, col, true
+}
+
+// At return the density of the cell of the position, ok is false outside the zone
+func (g *CloudGrid) At(latitude float32, longitude float32) (density byte, ok bool) {
+	row, col, ok := g.Cell(latitude, longitude)
+	if !ok {
+		return 0, false
+	}
+	return g.Density[row][col], true
+}
+
+// Image return a heatmap of the densities with the north at the top, each cell is a square of scale pixels. No cloud is
+// transparent, the densities go from blue to red.
+func (g *CloudGrid) Image(scale int) *image.NRGBA {
+	if scale < 1 {
+		scale = 1
+	}
+	const width = SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH
+	img := image.NewNRGBA(image.Rect(0, 0, width*scale, width*scale))
+	for row := 0; row < width; row++ {
+		c := make([]color.NRGBA, width)
+		for col := 0; col < width; col++ {
+			c[col] = heatColor(g.Density[row][col])
+		}
+		// the row 0 is the south
+		y := (width - 1 - row) * scale
+		for dy := 0; dy < scale; dy++ {
+			for col := 0; col < width; col++ {
+				for dx := 0; dx < scale; dx++ {
+					img.SetNRGBA(col*scale+dx, y+dy, c[col])
+				}
+			}
+		}
+	}
+	return img
+}
+
+// WritePNG write the heatmap of Image in PNG
+func (g *CloudGrid) WritePNG(w io.Writer, scale int) error {
+	return png.Encode(w, g.Image(scale))
+}
+
+// heatColor return the color of a density: blue, cyan, green, yellow then red, more opaque with the density
+func heatColor(density byte) color.NRGBA {
+	if density == 0 {
+		return color.NRGBA{}
+	}
+	// 4 segments of the gradient
+	stops := [...]color.NRGBA{
+		{0, 0, 255, 0},
+		{0, 255, 255, 0},
+		{0, 255, 0, 0},
+		{255, 255, 0, 0},
+		{255, 0, 0, 0},
+	}
+	position := int(density) * 4
+	i := position / 255
+	if i >= 4 {
+		i = 3
+	}
+	t := position - i*255
+	lerp := func(a, b uint8) uint8 {
+		return uint8((int(a)*(255-t) + int(b)*t) / 255)
+	}
+	from, to := stops[i], stops[i+1]
+	return color.NRGBA{
+		R: lerp(from.R, to.R),
+		G: lerp(from.G, to.G),
+		B: lerp(from.B, to.B),
+		A: uint8(64 + int(density)*191/255),
+	}
+}
@@ -0,0 +1,74 @@
+package simconnect
+
+import (
+	"bytes"
+	"image/png"
+	"math"
+	"testing"
+)
+
+func TestCloudGrid(t *testing.T) {
+	data := make([]byte, SIMCONNECT_CLOUD_STATE_ARRAY_SIZE)
+	// a cloud in the south west corner and one in the north east corner
+	data[0] = 10
+	data[SIMCONNECT_CLOUD_STATE_ARRAY_SIZE-1] = 255
+	if _, err := newCloudGrid(data[:100], 0, 0, 0, 1, 1, 1000); err == nil {
+		t.Error("want error for a short cloud state")
+	}
+	for _, zone := range [][4]float32{{45, 5, 45, 6}, {45, 5, 46, 5}, {46, 5, 45, 6}} {
+		if _, err := newCloudGrid(data, zone[0], zone[1], 0, zone[2], zone[3], 1000); err == nil {
+			t.Errorf("zone %v: want error for an empty zone", zone)
+		}
+	}
+	// a CloudGrid built without newCloudGrid
+	empty := CloudGrid{MinLatitude: 45, MinLongitude: 5, MaxLatitude: 45, MaxLongitude: 6}
+	if _, ok := empty.At(45, 5.5); ok {
+		t.Error("At of an empty zone is ok")
+	}
+	g, err := newCloudGrid(data, 45, 5, 0, 46, 6, 10000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		latitude, longitude float32
+		density             byte
+		ok                  bool
+	}{
+		{45, 5, 10, true},
+		{45.01, 5.01, 10, true},
+		{46, 6, 255, true},
+		{45.999, 5.999, 255, true},
+		{45.5, 5.5, 0, true},
+		{44.9, 5.5, 0, false},
+		{45.5, 6.1, 0, false},
+		{float32(math.NaN()), 5.5, 0, false},
+	}
+	for _, test := range tests {
+		density, ok := g.At(test.latitude, test.longitude)
+		if density != test.density || ok != test.ok {
+			t.Errorf("At(%g, %g) = %d, %v, want %d, %v", test.latitude, test.longitude, density, ok, test.density, test.ok)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := g.WritePNG(&buf, 4); err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size := img.Bounds().Size(); size.X != 256 || size.Y != 256 {
+		t.Errorf("size = %v, want 256x256", size)
+	}
+	// the north is at the top
+	if _, _, _, a := img.At(255, 0).RGBA(); a != 0xffff {
+		t.Errorf("north east alpha = %x, want opaque", a)
+	}
+	if r, _, b, _ := img.At(0, 255).RGBA(); b == 0 || r != 0 {
+		t.Errorf("south west = %v, want blue", img.At(0, 255))
+	}
+	if _, _, _, a := img.At(128, 128).RGBA(); a != 0 {
+		t.Errorf("center alpha = %x, want transparent", a)
+	}
+}
@@ -1,9 +1,11 @@
 package simconnect
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"sync"
 	"time"
-	"unsafe"
 
 	"github.com/sirupsen/logrus"
 )
@@ -22,39 +24,100 @@ const (
 // EasySimConnect for easy use of SimConnect in golang
 // Please show example_test.go for use case
 type EasySimConnect struct {
-	sc           *SimConnect
-	delay        time.Duration
-	listSimVar   [][]SimVar
-	listChan     []chan []SimVar
-	indexEvent   uint32
-	listEvent    map[uint32]func(interface{})
-	listSimEvent map[KeySimEvent]SimEvent
-	logLevel     EasySimConnectLogLevel
-	cOpen        chan bool
-	alive        bool
-	cException   chan *SIMCONNECT_RECV_EXCEPTION
-}
-
-// NewEasySimConnect create instance of EasySimConnect
+	sc               *SimConnect
+	delay            time.Duration
+	mutex            sync.Mutex
+	indexSimVar      uint32
+	listSimVar       map[uint32]*simVarRequest
+	indexEvent       uint32
+	listEvent        map[uint32]func(interface{})
+	listSimEvent     map[KeySimEvent]SimEvent
+	indexRequest     uint32
+	listRequest      map[uint32]func(interface{})
+	indexGroup       uint32
+	indexInputGroup  uint32
+	listSubscription map[uintptr]*Subscription
+	indexRestore     uint32
+	listRestore      map[uint32]func()
+	appName          string
+	reconnectDelay   time.Duration
+	reconnectMax     time.Duration
+	logLevel         EasySimConnectLogLevel
+	cOpen            chan bool
+	ctx              context.Context
+	cancel           context.CancelFunc
+	handler          func(msg Message, raw []byte) bool
+	sendMutex        sync.Mutex
+	listSend         map[uint32]*sentCall
+	listSendOrder    []uint32
+	cError           chan error
+	reserveMutex     sync.Mutex
+	cReservedKey     chan *ReservedKeyMessage
+	setMutex         sync.Mutex // SetSimObjectByID use the same data definition for all the SimVars
+}
+
+// sentCall is a call of SimConnect waiting a possible exception
+type sentCall struct {
+	op     string
+	detail string
+	c      chan *SimConnectError
+}
+
+// maxSentCall is the number of send IDs kept for finding the call of an exception
+const maxSentCall = 1024
+
+// maxRadius is the maximum radius in meters of RequestDataOnSimObjectType
+const maxRadius = 200000
+
+// exceptionDelay is the time for waiting the exceptions of a call before considering it successful
+const exceptionDelay = 100 * time.Millisecond
+
+// simVarRequest is the SimVars of a data definition
+type simVarRequest struct {
+	listSimVar []SimVar
+	options    SimVarOptions
+	sub        *Subscription
+	byType     *byTypeRequest // nil for the user aircraft
+}
+
+// byTypeRequest is a request of ConnectToSimVarByType, the objects are kept until the last entry of the batch
+type byTypeRequest struct {
+	radius     uint32
+	objectType uint32
+	batch      map[uint32][]SimVar
+}
+
+// NewEasySimConnect create instance of EasySimConnect using SimConnect.dll (only on windows)
 func NewEasySimConnect() (*EasySimConnect, error) {
-	sc, err := NewSimConnect()
+	transport, err := newDefaultTransport()
 	if err != nil {
 		return nil, err
 	}
+	return NewEasySimConnectWithTransport(transport), nil
+}
+
+// NewEasySimConnectWithTransport create instance of EasySimConnect using your Transport
+func NewEasySimConnectWithTransport(transport Transport) *EasySimConnect {
 	logrus.SetFormatter(&logrus.TextFormatter{ForceColors: true})
+	ctx, cancel := context.WithCancel(context.Background())
 	return &EasySimConnect{
-		sc,
-		100 * time.Millisecond,
-		make([][]SimVar, 0),
-		make([]chan []SimVar, 0),
-		0,
-		make(map[uint32]func(interface{})),
-		make(map[KeySimEvent]SimEvent),
-		LogNo,
-		make(chan bool, 1),
-		true,
-		make(chan *SIMCONNECT_RECV_EXCEPTION),
-	}, nil
+		sc:               NewSimConnectWithTransport(transport),
+		delay:            100 * time.Millisecond,
+		listSimVar:       make(map[uint32]*simVarRequest),
+		listEvent:        make(map[uint32]func(interface{})),
+		listSimEvent:     make(map[KeySimEvent]SimEvent),
+		listRequest:      make(map[uint32]func(interface{})),
+		indexGroup:       simEventGroupID,
+		listSubscription: make(map[uintptr]*Subscription),
+		listRestore:      make(map[uint32]func()),
+		logLevel:         LogNo,
+		cOpen:            make(chan bool, 1),
+		ctx:              ctx,
+		cancel:           cancel,
+		listSend:         make(map[uint32]*sentCall),
+		cError:           make(chan error, 16),
+		cReservedKey:     make(chan *ReservedKeyMessage, 1),
+	}
 }
 
 // SetLoggerLevel you can set log level in EasySimConnect
@@ -64,13 +127,103 @@ func (esc *EasySimConnect) SetLoggerLevel(level EasySimConnectLogLevel) {
 
 // Close Finishing EasySimConnect, All object created with this EasySimConnect's instance is perished after call this function
 func (esc *EasySimConnect) Close() <-chan bool {
-	esc.alive = false
+	esc.cancel()
 	return esc.cOpen
 }
 
 // IsAlive return true if connected
 func (esc *EasySimConnect) IsAlive() bool {
-	return esc.alive
+	return esc.ctx.Err() == nil
+}
+
+// SetReconnect enable the reconnection when the simulator quit. Open is called again after delay, the delay is doubled
+// after each error up to maxDelay. When the simulator is open again all the SimVars, system events and SimEvents are
+// subscribed again and the chans continue to work. The chan returned by Connect receive false on the quit and true on
+// the reconnection. A delay of 0 disable the reconnection (default).
+func (esc *EasySimConnect) SetReconnect(delay time.Duration, maxDelay time.Duration) {
+	if maxDelay < delay {
+		maxDelay = delay
+	}
+	esc.mutex.Lock()
+	defer esc.mutex.Unlock()
+	esc.reconnectDelay = delay
+	esc.reconnectMax = maxDelay
+}
+
+// SetMessageHandler set a function called for each message received from the simulator before EasySimConnect.
+// raw is the buffer of GetNextDispatch. If handler return true the message is not used by EasySimConnect.
+func (esc *EasySimConnect) SetMessageHandler(handler func(msg Message, raw []byte) bool) {
+	esc.mutex.Lock()
+	defer esc.mutex.Unlock()
+	esc.handler = handler
+}
+
+// Errors return a chan with the exceptions not returned by a function, like the exceptions of SetSimObject, NewSimEvent,
+// ShowText or ConnectSysEvent*. The errors are *SimConnectError, use errors.Is with ErrNameUnrecognized... for the
+// type of exception. The dispatch never wait this chan, when 16 errors are not read the next errors are lost.
+func (esc *EasySimConnect) Errors() <-chan error {
+	return esc.cError
+}
+
+// call run f, the SimConnect function op, and register the send ID of f. The exception of this call is sent in c or in
+// the chan of Errors if c is nil. detail is the SimVar, event or text used by the call.
+func (esc *EasySimConnect) call(op string, detail string, c chan *SimConnectError, f func() (error, uint32)) (uint32, error) {
+	// the lock prevent the reading of an exception before the registration of the send ID
+	esc.sendMutex.Lock()
+	defer esc.sendMutex.Unlock()
+	err, id := f()
+	if err != nil {
+		return 0, err
+	}
+	esc.listSend[id] = &sentCall{op, detail, c}
+	esc.listSendOrder = append(esc.listSendOrder, id)
+	if len(esc.listSendOrder) > maxSentCall {
+		delete(esc.listSend, esc.listSendOrder[0])
+		esc.listSendOrder = esc.listSendOrder[1:]
+	}
+	return id, nil
+}
+
+// release send the next exceptions of the send IDs in the chan of Errors
+func (esc *EasySimConnect) release(sendIDs ...uint32) {
+	esc.sendMutex.Lock()
+	defer esc.sendMutex.Unlock()
+	for _, id := range sendIDs {
+		if call, found := esc.listSend[id]; found {
+			call.c = nil
+		}
+	}
+}
+
+// exception send the exception to the call at the origin or in the chan of Errors
+func (esc *EasySimConnect) exception(recv *ExceptionMessage) {
+	err := &SimConnectError{Code: Exception(recv.Exception), Param: recv.Index, SendID: recv.SendID}
+	var c chan *SimConnectError
+	esc.sendMutex.Lock()
+	if call, found := esc.listSend[recv.SendID]; found {
+		err.Op = call.op
+		err.Detail = call.detail
+		c = call.c
+	}
+	esc.sendMutex.Unlock()
+	esc.logf(LogInfo, "SimConnect Exception : %v", err)
+	if c != nil {
+		select {
+		case c <- err:
+			return
+		default:
+		}
+	}
+	esc.reportError(err)
+}
+
+// reportError send the error in the chan of Errors without waiting
+func (esc *EasySimConnect) reportError(err error) {
+	select {
+	case esc.cError <- err:
+	default:
+		esc.logf(LogWarn, "Errors chan is full, error lost : %v", err)
+	}
 }
 
 // SetDelay Select delay update SimVar and
@@ -80,10 +233,28 @@ func (esc *EasySimConnect) SetDelay(t time.Duration) {
 
 // Connect to sim and run dispatch or return error
 func (esc *EasySimConnect) Connect(appName string) (<-chan bool, error) {
+	return esc.ConnectContext(context.Background(), appName)
+}
+
+// ConnectContext is Connect with a context, the cancellation of ctx close EasySimConnect like Close
+func (esc *EasySimConnect) ConnectContext(ctx context.Context, appName string) (<-chan bool, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	err, _ := esc.sc.Open(appName)
 	if err != nil {
 		return nil, err
 	}
+	esc.appName = appName
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				esc.Close()
+			case <-esc.ctx.Done():
+			}
+		}()
+	}
 	go esc.runDispatch()
 	return esc.cOpen, nil
 }
@@ -103,124 +274,555 @@ func (esc *EasySimConnect) logf(level EasySimConnectLogLevel, format string, arg
 	}
 }
 
+// setOpen replace the waiting state in cOpen by open
+func (esc *EasySimConnect) setOpen(open bool) {
+	select {
+	case <-esc.cOpen:
+	default:
+	}
+	esc.cOpen <- open
+}
+
+// addRestore register f for calling after a reconnection and return his ID for removeRestore
+func (esc *EasySimConnect) addRestore(f func()) uint32 {
+	esc.mutex.Lock()
+	defer esc.mutex.Unlock()
+	esc.indexRestore++
+	esc.listRestore[esc.indexRestore] = f
+	return esc.indexRestore
+}
+
+func (esc *EasySimConnect) removeRestore(id uint32) {
+	esc.mutex.Lock()
+	defer esc.mutex.Unlock()
+	delete(esc.listRestore, id)
+}
+
+// reconnect call Open until success with the delays of SetReconnect and subscribe again.
+// Return false if the reconnection is disabled or EasySimConnect is closed.
+func (esc *EasySimConnect) reconnect() bool {
+	esc.mutex.Lock()
+	delay, maxDelay := esc.reconnectDelay, esc.reconnectMax
+	esc.mutex.Unlock()
+	if delay == 0 {
+		return false
+	}
+	esc.setOpen(false)
+	for {
+		select {
+		case <-time.After(delay):
+		case <-esc.ctx.Done():
+			return false
+		}
+		esc.logf(LogInfo, "Reconnect to the simulator")
+		err, _ := esc.sc.Open(esc.appName)
+		if err == nil {
+			break
+		}
+		esc.logf(LogWarn, "Error reconnect to the simulator : %v", err)
+		delay *= 2
+		if delay > maxDelay {
+			delay = maxDelay
+		}
+	}
+	// the send IDs of the new connection restart from the beginning
+	esc.sendMutex.Lock()
+	esc.listSend = make(map[uint32]*sentCall)
+	esc.listSendOrder = nil
+	esc.sendMutex.Unlock()
+	esc.mutex.Lock()
+	listRestore := make([]func(), 0, len(esc.listRestore))
+	for _, f := range esc.listRestore {
+		listRestore = append(listRestore, f)
+	}
+	esc.mutex.Unlock()
+	for _, f := range listRestore {
+		f()
+	}
+	return true
+}
+
 func (esc *EasySimConnect) runDispatch() {
-	for esc.alive {
-		var ppdata unsafe.Pointer
-		var pcbData uint32
-		err, _ := esc.sc.GetNextDispatch(&ppdata, &pcbData)
-		//créer un buffer en copy les data ppdata avec longueur pcbdata et utiliser le buffer pour la suite
+	for esc.IsAlive() {
+		buf, err := esc.sc.transport.GetNextDispatch()
 		if err != nil {
-			time.Sleep(esc.delay / 2)
+			select {
+			case <-time.After(esc.delay / 2):
+			case <-esc.ctx.Done():
+			}
 			continue
 		}
-		buf, err := convCBytesToGoBytes(ppdata, int(pcbData))
+		msg, err := DecodeMessage(buf)
 		if err != nil {
-			esc.logf(LogError, "%v#", err)
+			esc.logf(LogError, "%v", err)
 			continue
 		}
-		recvInfo := *(*SIMCONNECT_RECV)(ppdata)
-		switch recvInfo.dwID {
-		case SIMCONNECT_RECV_ID_OPEN:
-			recv := *(*SIMCONNECT_RECV_OPEN)(ppdata)
-			esc.logf(LogInfo, "Connected to %s", convStrToGoString(recv.szApplicationName[:]))
-			esc.cOpen <- true
-		case SIMCONNECT_RECV_ID_EVENT:
-			recv := *(*SIMCONNECT_RECV_EVENT)(ppdata)
-			cb, found := esc.listEvent[recv.uEventID]
-			if !found {
-				esc.logf(LogInfo, "Ignored event : %#v\n", recv)
+		esc.mutex.Lock()
+		handler := esc.handler
+		esc.mutex.Unlock()
+		if handler != nil && handler(msg, buf) {
+			continue
+		}
+		switch recv := msg.(type) {
+		case *OpenMessage:
+			esc.logf(LogInfo, "Connected to %s", recv.ApplicationName)
+			esc.setOpen(true)
+		case *QuitMessage:
+			esc.sc.Close()
+			if esc.reconnect() {
 				continue
 			}
-			cb(recv)
-		case SIMCONNECT_RECV_ID_QUIT:
-			esc.sc.Close()
-			esc.cOpen <- false
+			esc.cancel()
+			esc.setOpen(false)
 			return
-		case SIMCONNECT_RECV_ID_EVENT_FILENAME:
-			recv := *(*SIMCONNECT_RECV_EVENT_FILENAME)(ppdata)
-			esc.listEvent[recv.uEventID](recv)
-		case SIMCONNECT_RECV_ID_EXCEPTION:
-			recv := (*SIMCONNECT_RECV_EXCEPTION)(ppdata)
+		case *ExceptionMessage:
+			esc.exception(recv)
+		case *ReservedKeyMessage:
 			select {
-			case esc.cException <- recv:
-			case <-time.After(100 * time.Millisecond):
+			case esc.cReservedKey <- recv:
+			default:
+				esc.logf(LogInfo, "Ignored reserved key : %#v\n", recv)
 			}
-			esc.logf(LogInfo, "SimConnect Exception : %s %#v\n", getTextException(recv.dwException), *recv)
-		case SIMCONNECT_RECV_ID_SIMOBJECT_DATA, SIMCONNECT_RECV_ID_SIMOBJECT_DATA_BYTYPE:
-			recv := *(*SIMCONNECT_RECV_SIMOBJECT_DATA)(ppdata)
-			if len(esc.listSimVar) < int(recv.dwDefineID) {
-				esc.logf(LogWarn, "ListSimVar not found: %#v\n %#v\n %d>=%d", recv, esc.listSimVar, len(esc.listSimVar), int(recv.dwDefineID))
-				continue
-			}
-			listSimVar := esc.listSimVar[recv.dwDefineID]
-			if len(listSimVar) != int(recv.dwDefineCount) {
-				esc.logf(LogWarn, "ListSimVar size not equal %#v ?= %#v\n", int(recv.dwDefineCount), len(listSimVar))
+		case *SystemStateMessage:
+			esc.dispatchRequest(recv.RequestID, recv)
+		case *AssignedObjectIDMessage:
+			esc.dispatchRequest(recv.RequestID, recv)
+		case *WeatherObservationMessage:
+			esc.dispatchRequest(recv.RequestID, recv)
+		case *CloudStateMessage:
+			esc.dispatchRequest(recv.RequestID, recv)
+		case *AirportListMessage:
+			esc.dispatchRequest(recv.RequestID, recv)
+		case *WaypointListMessage:
+			esc.dispatchRequest(recv.RequestID, recv)
+		case *NDBListMessage:
+			esc.dispatchRequest(recv.RequestID, recv)
+		case *VORListMessage:
+			esc.dispatchRequest(recv.RequestID, recv)
+		case *SimObjectDataMessage:
+			esc.dispatchSimObjectData(recv)
+		case *SimObjectDataByTypeMessage:
+			esc.dispatchSimObjectData(&recv.SimObjectDataMessage)
+		case eventMessage:
+			esc.mutex.Lock()
+			cb, found := esc.listEvent[recv.eventMessage().EventID]
+			esc.mutex.Unlock()
+			if !found {
+				esc.logf(LogInfo, "Ignored event : %#v\n", recv)
 				continue
 			}
-			position := int(unsafe.Offsetof(recv.dwData))
-			returnSimVar := make([]SimVar, len(listSimVar))
-			for i, simVar := range listSimVar {
-				size := simVar.GetSize()
-				if position+size > int(pcbData) {
-					esc.logf(LogError, "slice bounds out of range")
-					break
-				}
-				simVar.data = buf[position : position+size]
-				returnSimVar[i] = simVar
-				position = position + size
-			}
-			select {
-			case esc.listChan[recv.dwDefineID] <- returnSimVar:
-			case <-time.After(esc.delay):
-			}
-			go func() {
-				time.Sleep(esc.delay)
-				esc.sc.RequestDataOnSimObjectType(uint32(0), recv.dwDefineID, uint32(0), uint32(0))
-			}()
-
+			cb(recv)
 		default:
-			esc.logf(LogInfo, "%#v\n", recvInfo)
+			esc.logf(LogInfo, "%#v\n", recv)
 		}
 	}
 	esc.sc.Close()
-	esc.cOpen <- false
+	esc.setOpen(false)
+}
+
+// newRequest return a request ID, the responses with this ID are given to cb until removeRequest
+func (esc *EasySimConnect) newRequest(cb func(interface{})) uint32 {
+	esc.mutex.Lock()
+	defer esc.mutex.Unlock()
+	esc.indexRequest++
+	esc.listRequest[esc.indexRequest] = cb
+	return esc.indexRequest
+}
+
+func (esc *EasySimConnect) removeRequest(requestID uint32) {
+	esc.mutex.Lock()
+	delete(esc.listRequest, requestID)
+	esc.mutex.Unlock()
+}
+
+// dispatchRequest give the response to the callback of his request
+func (esc *EasySimConnect) dispatchRequest(requestID uint32, recv interface{}) {
+	esc.mutex.Lock()
+	cb, found := esc.listRequest[requestID]
+	esc.mutex.Unlock()
+	if !found {
+		esc.logf(LogInfo, "Ignored response : %#v\n", recv)
+		return
+	}
+	cb(recv)
+}
+
+// callRequest call f with a new request ID and wait the response of the request, an exception or the timeout
+func (esc *EasySimConnect) callRequest(op string, detail string, timeout time.Duration, f func(requestID uint32) (error, uint32)) (interface{}, error) {
+	cResponse := make(chan interface{}, 1)
+	requestID := esc.newRequest(func(data interface{}) {
+		select {
+		case cResponse <- data:
+		default:
+		}
+	})
+	defer esc.removeRequest(requestID)
+	cException := make(chan *SimConnectError, 1)
+	id, err := esc.call(op, detail, cException, func() (error, uint32) {
+		return f(requestID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer esc.release(id)
+	select {
+	case response := <-cResponse:
+		return response, nil
+	case exception := <-cException:
+		return nil, exception
+	case <-time.After(timeout):
+		return nil, fmt.Errorf("no response of the simulator for %s ( %s )", op, detail)
+	case <-esc.ctx.Done():
+		return nil, errors.New("EasySimConnect is closed")
+	}
+}
+
+// callWait call f and return the exception raised during exceptionDelay, SimConnect don't confirm a success
+func (esc *EasySimConnect) callWait(op string, detail string, f func() (error, uint32)) error {
+	cException := make(chan *SimConnectError, 1)
+	id, err := esc.call(op, detail, cException, f)
+	if err != nil {
+		return err
+	}
+	defer esc.release(id)
+	select {
+	case exception := <-cException:
+		return exception
+	case <-time.After(exceptionDelay):
+		return nil
+	}
+}
+
+// dispatchSimObjectData send the SimVars of the message in the chan of his definition
+func (esc *EasySimConnect) dispatchSimObjectData(recv *SimObjectDataMessage) {
+	esc.mutex.Lock()
+	request, found := esc.listSimVar[recv.DefineID]
+	esc.mutex.Unlock()
+	if !found {
+		esc.logf(LogWarn, "ListSimVar not found for DefineID %d", recv.DefineID)
+		return
+	}
+	if request.byType != nil {
+		esc.dispatchByType(request, recv)
+		return
+	}
+	returnSimVar := esc.decodeSimVars(request.listSimVar, recv)
+	if returnSimVar == nil {
+		return
+	}
+	request.sub.send(returnSimVar, esc.delay)
+	if request.options.Period != SIMCONNECT_PERIOD_NEVER {
+		return
+	}
+	defineID := recv.DefineID
+	esc.requestAgain(defineID, func() error {
+		return esc.requestSimVar(defineID, request.options)
+	})
+}
+
+// dispatchByType keep the SimVars of the object and send all the objects with the last entry of the batch
+func (esc *EasySimConnect) dispatchByType(request *simVarRequest, recv *SimObjectDataMessage) {
+	byType := request.byType
+	if recv.EntryNumber <= 1 {
+		byType.batch = make(map[uint32][]SimVar)
+	}
+	// without object in the radius the simulator send an empty entry with dwoutof 0
+	if recv.OutOf != 0 {
+		simVars := esc.decodeSimVars(request.listSimVar, recv)
+		if simVars == nil {
+			return
+		}
+		byType.batch[recv.ObjectID] = simVars
+		if recv.EntryNumber < recv.OutOf {
+			return
+		}
+	}
+	batch := byType.batch
+	byType.batch = nil
+	request.sub.send(batch, esc.delay)
+	defineID := recv.DefineID
+	esc.requestAgain(defineID, func() error {
+		_, err := esc.call("RequestDataOnSimObjectType", "", nil, func() (error, uint32) {
+			return esc.sc.RequestDataOnSimObjectType(uint32(0), defineID, byType.radius, byType.objectType)
+		})
+		return err
+	})
+}
+
+// decodeSimVars return the SimVars with the data of the message or nil if the data is invalid
+func (esc *EasySimConnect) decodeSimVars(listSimVar []SimVar, recv *SimObjectDataMessage) []SimVar {
+	if len(listSimVar) != int(recv.DefineCount) {
+		esc.logf(LogWarn, "ListSimVar size not equal %#v ?= %#v\n", int(recv.DefineCount), len(listSimVar))
+		return nil
+	}
+	position := 0
+	returnSimVar := make([]SimVar, len(listSimVar))
+	for i, simVar := range listSimVar {
+		size := simVar.GetSize()
+		if position+size > len(recv.Data) {
+			esc.logf(LogError, "slice bounds out of range")
+			return nil
+		}
+		simVar.data = recv.Data[position : position+size]
+		returnSimVar[i] = simVar
+		position = position + size
+	}
+	return returnSimVar
+}
+
+// requestAgain call request after the delay if the definition is not removed
+func (esc *EasySimConnect) requestAgain(defineID uint32, request func() error) {
+	go func() {
+		time.Sleep(esc.delay)
+		esc.mutex.Lock()
+		_, found := esc.listSimVar[defineID]
+		esc.mutex.Unlock()
+		if found {
+			request()
+		}
+	}()
+}
+
+// SimVarOptions select when the simulator send the SimVars connected with ConnectToSimVarWithOptions
+type SimVarOptions struct {
+	// Period is SIMCONNECT_PERIOD_ONCE, SIMCONNECT_PERIOD_VISUAL_FRAME, SIMCONNECT_PERIOD_SIM_FRAME or SIMCONNECT_PERIOD_SECOND.
+	// With SIMCONNECT_PERIOD_NEVER (default) the SimVars are requested again after each response with the delay of SetDelay.
+	Period uint32
+	// Changed send the SimVars only when a value is changed (not used with SIMCONNECT_PERIOD_NEVER)
+	Changed bool
+	// Origin is the number of periods before the first send
+	Origin uint32
+	// Interval is the number of periods between two sends, 0 for each period
+	Interval uint32
+	// Limit is the number of sends before stopping, 0 for never
+	Limit uint32
+	// ObjectID is the object of the SimVars (ex: an AI aircraft), 0 (SIMCONNECT_OBJECT_ID_USER) for the user aircraft
+	ObjectID uint32
 }
 
 // ConnectToSimVar return a chan. This chan return an array when updating they SimVars in order of argument of this function
 func (esc *EasySimConnect) ConnectToSimVar(listSimVar ...SimVar) (<-chan []SimVar, error) {
-	defineID := uint32(len(esc.listSimVar))
-	addedSimVar := make([]SimVar, 0)
+	return esc.connectToSimVar(context.Background(), SimVarOptions{}, listSimVar...)
+}
+
+// ConnectToSimVarContext is ConnectToSimVar with a context, the cancellation of ctx unsubscribe the SimVars and close the chan
+func (esc *EasySimConnect) ConnectToSimVarContext(ctx context.Context, listSimVar ...SimVar) (<-chan []SimVar, error) {
+	return esc.connectToSimVar(ctx, SimVarOptions{}, listSimVar...)
+}
+
+// ConnectToSimVarWithOptions is ConnectToSimVar with the period of update selected by options.
+//
+//	cSimVar, err := esc.ConnectToSimVarWithOptions(
+//		SimVarOptions{Period: SIMCONNECT_PERIOD_SIM_FRAME, Changed: true},
+//		SimVarPlaneAltitude(),
+//	)
+func (esc *EasySimConnect) ConnectToSimVarWithOptions(options SimVarOptions, listSimVar ...SimVar) (<-chan []SimVar, error) {
+	return esc.connectToSimVar(context.Background(), options, listSimVar...)
+}
+
+// ConnectToSimVarByID is ConnectToSimVar for the object objectID (ex: an AI aircraft of ConnectToSimVarByType).
+// An unknown object is refused by the simulator, the exception is sent in the chan of Errors.
+func (esc *EasySimConnect) ConnectToSimVarByID(objectID uint32, listSimVar ...SimVar) (<-chan []SimVar, error) {
+	return esc.connectToSimVar(context.Background(), SimVarOptions{ObjectID: objectID}, listSimVar...)
+}
+
+func (esc *EasySimConnect) connectToSimVar(ctx context.Context, options SimVarOptions, listSimVar ...SimVar) (<-chan []SimVar, error) {
+	defineID, err := esc.addDataDefinition(ctx, listSimVar)
+	if err != nil {
+		return nil, err
+	}
+	addedSimVar := append([]SimVar{}, listSimVar...)
+	chanSimVar := make(chan []SimVar)
+	var restoreID uint32
+	sub := esc.newSubscription(chanSimVar, func() error {
+		esc.removeRestore(restoreID)
+		esc.mutex.Lock()
+		delete(esc.listSimVar, defineID)
+		esc.mutex.Unlock()
+		if options.Period != SIMCONNECT_PERIOD_NEVER {
+			_, err := esc.call("RequestDataOnSimObject", "", nil, func() (error, uint32) {
+				return esc.sc.RequestDataOnSimObject(defineID, defineID, options.ObjectID, SIMCONNECT_PERIOD_NEVER, 0, 0, 0, 0)
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return esc.clearDataDefinition(defineID)
+	})
+	esc.mutex.Lock()
+	esc.listSimVar[defineID] = &simVarRequest{addedSimVar, options, sub, nil}
+	esc.mutex.Unlock()
+	restoreID = esc.addRestore(func() {
+		esc.defineSimVars(defineID, addedSimVar)
+		esc.requestSimVar(defineID, options)
+	})
+	esc.unsubscribeOnDone(ctx, sub)
+	err = esc.requestSimVar(defineID, options)
+	if err != nil {
+		sub.Unsubscribe()
+		return nil, err
+	}
+	return chanSimVar, nil
+}
+
+// ConnectToSimVarByType return a chan receiving the SimVars of all the objects of the type (SIMCONNECT_SIMOBJECT_TYPE_*)
+// in the radius around the user aircraft, by object ID. A radius of 0 return only the user aircraft, the maximum is 200000.
+// The objects are requested again with the delay of SetDelay after each response.
+//
+//	cTraffic, err := esc.ConnectToSimVarByType(50000, SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, SimVarTitle(), SimVarPlaneAltitude())
+//	for objects := range cTraffic {
+//		for objectID, simVars := range objects {
+//			// your radar logic
+//		}
+//	}
+func (esc *EasySimConnect) ConnectToSimVarByType(radiusMeters uint32, objectType uint32, listSimVar ...SimVar) (<-chan map[uint32][]SimVar, error) {
+	if radiusMeters > maxRadius {
+		return nil, fmt.Errorf("The radius %d is bigger than %d meters", radiusMeters, maxRadius)
+	}
+	if objectType > SIMCONNECT_SIMOBJECT_TYPE_GROUND {
+		return nil, fmt.Errorf("Unknown object type %d", objectType)
+	}
+	defineID, err := esc.addDataDefinition(context.Background(), listSimVar)
+	if err != nil {
+		return nil, err
+	}
+	addedSimVar := append([]SimVar{}, listSimVar...)
+	chanObjects := make(chan map[uint32][]SimVar)
+	var restoreID uint32
+	sub := esc.newSubscription(chanObjects, func() error {
+		esc.removeRestore(restoreID)
+		esc.mutex.Lock()
+		delete(esc.listSimVar, defineID)
+		esc.mutex.Unlock()
+		return esc.clearDataDefinition(defineID)
+	})
+	esc.mutex.Lock()
+	esc.listSimVar[defineID] = &simVarRequest{addedSimVar, SimVarOptions{}, sub, &byTypeRequest{radiusMeters, objectType, nil}}
+	esc.mutex.Unlock()
+	request := func() error {
+		_, err := esc.call("RequestDataOnSimObjectType", "", nil, func() (error, uint32) {
+			return esc.sc.RequestDataOnSimObjectType(uint32(0), defineID, radiusMeters, objectType)
+		})
+		return err
+	}
+	restoreID = esc.addRestore(func() {
+		esc.defineSimVars(defineID, addedSimVar)
+		request()
+	})
+	if err := request(); err != nil {
+		sub.Unsubscribe()
+		return nil, err
+	}
+	return chanObjects, nil
+}
+
+// defineSimVars add the SimVars in the definition without waiting the exceptions
+func (esc *EasySimConnect) defineSimVars(defineID uint32, listSimVar []SimVar) {
 	for i, simVar := range listSimVar {
-		err, id := esc.sc.AddToDataDefinition(defineID, simVar.getNameForDataDefinition(), simVar.getUnitForDataDefinition(), simVar.GetDatumType(), 0, uint32(i))
+		esc.call("AddToDataDefinition", simVar.Name, nil, func() (error, uint32) {
+			return esc.sc.AddToDataDefinition(defineID, simVar.getNameForDataDefinition(), simVar.getUnitForDataDefinition(), simVar.GetDatumType(), 0, uint32(i))
+		})
+	}
+}
+
+// addDataDefinition create a definition with the SimVars and return an error if the simulator raise an exception
+func (esc *EasySimConnect) addDataDefinition(ctx context.Context, listSimVar []SimVar) (uint32, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	esc.mutex.Lock()
+	defineID := esc.indexSimVar
+	esc.indexSimVar++
+	esc.mutex.Unlock()
+	cException := make(chan *SimConnectError, len(listSimVar))
+	sendIDs := make([]uint32, len(listSimVar))
+	for i, simVar := range listSimVar {
+		id, err := esc.call("AddToDataDefinition", simVar.Name, cException, func() (error, uint32) {
+			return esc.sc.AddToDataDefinition(defineID, simVar.getNameForDataDefinition(), simVar.getUnitForDataDefinition(), simVar.GetDatumType(), 0, uint32(i))
+		})
 		if err != nil {
+			esc.release(sendIDs[:i]...)
+			esc.clearDataDefinition(defineID)
 			esc.logf(LogInfo, "Error add SimVar ( %s ) in AddToDataDefinition error : %#v", simVar.Name, err)
-			return nil, fmt.Errorf(
-				"Error add SimVar ( %s ) in AddToDataDefinition error : %#v",
+			return 0, fmt.Errorf(
+				"Error add SimVar ( %s ) in AddToDataDefinition error : %w",
 				simVar.Name,
 				err,
 			)
 		}
-		var exception *SIMCONNECT_RECV_EXCEPTION
-		select {
-		case exception = <-esc.cException:
-		case <-time.After(100 * time.Millisecond):
-		}
-		if exception != nil && exception.dwSendID == id {
-			return nil, fmt.Errorf(
-				"Error add SimVar ( %s ) in AddToDataDefinition : %s. Please control name ( %s ) and unit ( %s )",
-				simVar.Name,
-				getTextException(exception.dwException),
-				simVar.Name,
-				simVar.Unit,
-			)
+		sendIDs[i] = id
+	}
+	// SimConnect don't confirm the definitions, no exception during exceptionDelay is a success
+	select {
+	case exception := <-cException:
+		esc.release(sendIDs...)
+		esc.clearDataDefinition(defineID)
+		simVar := listSimVar[0]
+		for i, id := range sendIDs {
+			if id == exception.SendID {
+				simVar = listSimVar[i]
+			}
 		}
-		addedSimVar = append(addedSimVar, simVar)
+		return 0, fmt.Errorf(
+			"Error add SimVar ( %s ) in AddToDataDefinition : %w. Please control name ( %s ) and unit ( %s )",
+			simVar.Name,
+			exception,
+			simVar.Name,
+			simVar.Unit,
+		)
+	case <-time.After(exceptionDelay):
+	case <-ctx.Done():
+		esc.release(sendIDs...)
+		esc.clearDataDefinition(defineID)
+		return 0, ctx.Err()
 	}
-	esc.listSimVar = append(esc.listSimVar, addedSimVar)
-	chanSimVar := make(chan []SimVar)
-	esc.listChan = append(esc.listChan, chanSimVar)
-	esc.sc.RequestDataOnSimObjectType(uint32(0), defineID, uint32(0), uint32(0))
-	return chanSimVar, nil
+	esc.release(sendIDs...)
+	return defineID, nil
+}
+
+// requestSimVar request the data of the definition with the period of options
+func (esc *EasySimConnect) requestSimVar(defineID uint32, options SimVarOptions) error {
+	if options.Period == SIMCONNECT_PERIOD_NEVER && options.ObjectID == SIMCONNECT_OBJECT_ID_USER {
+		_, err := esc.call("RequestDataOnSimObjectType", "", nil, func() (error, uint32) {
+			return esc.sc.RequestDataOnSimObjectType(uint32(0), defineID, uint32(0), uint32(0))
+		})
+		return err
+	}
+	// RequestDataOnSimObjectType give only the user aircraft, the other objects are requested once
+	if options.Period == SIMCONNECT_PERIOD_NEVER {
+		options.Period = SIMCONNECT_PERIOD_ONCE
+	}
+	flags := uint32(SIMCONNECT_DATA_REQUEST_FLAG_DEFAULT)
+	if options.Changed {
+		flags |= SIMCONNECT_DATA_REQUEST_FLAG_CHANGED
+	}
+	_, err := esc.call("RequestDataOnSimObject", "", nil, func() (error, uint32) {
+		return esc.sc.RequestDataOnSimObject(defineID, defineID, options.ObjectID, options.Period, flags, options.Origin, options.Interval, options.Limit)
+	})
+	return err
+}
+
+// clearDataDefinition remove the definition in the simulator
+func (esc *EasySimConnect) clearDataDefinition(defineID uint32) error {
+	_, err := esc.call("ClearDataDefinition", "", nil, func() (error, uint32) {
+		return esc.sc.ClearDataDefinition(defineID)
+	})
+	return err
+}
+
+// unsubscribeOnDone call sub.Unsubscribe when ctx is done
+func (esc *EasySimConnect) unsubscribeOnDone(ctx context.Context, sub *Subscription) {
+	if ctx.Done() == nil {
+		return
+	}
+	go func() {
+		select {
+		case <-ctx.Done():
+			sub.Unsubscribe()
+		case <-sub.done:
+		case <-esc.ctx.Done():
+		}
+	}()
 }
 
 // ConnectToSimVarObject return a chan. This chan return an array when updating they SimVars in order of argument of this function
@@ -237,216 +839,584 @@ func (esc *EasySimConnect) ConnectToSimVarObject(listSimVar ...SimVar) <-chan []
 
 // ConnectInterfaceToSimVar return a chan. This chan return interface when updating
 func (esc *EasySimConnect) ConnectInterfaceToSimVar(iFace interface{}) (<-chan interface{}, error) {
+	return esc.ConnectInterfaceToSimVarByID(SIMCONNECT_OBJECT_ID_USER, iFace)
+}
+
+// ConnectInterfaceToSimVarByID is ConnectInterfaceToSimVar for the object objectID
+func (esc *EasySimConnect) ConnectInterfaceToSimVarByID(objectID uint32, iFace interface{}) (<-chan interface{}, error) {
 	simVars, err := SimVarGenerator(iFace)
 	if err != nil {
 		return nil, err
 	}
-	csimVars, err := esc.ConnectToSimVar(simVars...)
+	csimVars, err := esc.ConnectToSimVarByID(objectID, simVars...)
+	if err != nil {
+		return nil, err
+	}
+	subSimVars, err := esc.Subscription(csimVars)
 	if err != nil {
 		return nil, err
 	}
 	cInterface := make(chan interface{})
+	sub := esc.newSubscription(cInterface, subSimVars.Unsubscribe)
 	go func() {
-		for {
-			cInterface <- SimVarAssignInterface(iFace, <-csimVars)
+		for simVars := range csimVars {
+			if !sub.send(SimVarAssignInterface(iFace, simVars), 0) {
+				return
+			}
 		}
 	}()
 	return cInterface, nil
 }
 
 func (esc *EasySimConnect) SetSimVarInterfaceInSim(iFace interface{}) error {
+	return esc.SetSimVarInterfaceInSimByID(SIMCONNECT_OBJECT_ID_USER, iFace)
+}
+
+// SetSimVarInterfaceInSimByID is SetSimVarInterfaceInSim for the object objectID. The SimVars are set one by one, the
+// first error stop and the next SimVars are not set.
+func (esc *EasySimConnect) SetSimVarInterfaceInSimByID(objectID uint32, iFace interface{}) error {
 	simvars, err := SimVarGenerator(iFace)
 	if err != nil {
 		return err
 	}
 	InterfaceAssignSimVar(simvars, iFace)
 	for _, simvar := range simvars {
-		esc.SetSimObject(simvar)
+		if err := esc.SetSimObjectByID(objectID, simvar); err != nil {
+			return err
+		}
 	}
 	return nil
 }
 
-// SetSimObject edit the SimVar in the simulator
+// SetSimObject edit the SimVar in the simulator. The errors are logged and the exceptions of the simulator are sent in
+// the chan of Errors, use SetSimObjectErr for having the error.
 func (esc *EasySimConnect) SetSimObject(simVar SimVar) {
+	esc.SetSimObjectErr(simVar)
+}
+
+// SetSimObjectErr is SetSimObject returning the error of the Transport
+func (esc *EasySimConnect) SetSimObjectErr(simVar SimVar) error {
+	return esc.SetSimObjectByID(SIMCONNECT_OBJECT_ID_USER, simVar)
+}
+
+// SetSimObjectByID is SetSimObject for the object objectID (ex: an AI aircraft created by this client)
+func (esc *EasySimConnect) SetSimObjectByID(objectID uint32, simVar SimVar) error {
 	defineID := uint32(1 << 30)
-	err, _ := esc.sc.AddToDataDefinition(defineID, simVar.Name, simVar.getUnitForDataDefinition(), simVar.GetDatumType(), 0, 0)
+	esc.setMutex.Lock()
+	defer esc.setMutex.Unlock()
+	_, err := esc.call("AddToDataDefinition", simVar.Name, nil, func() (error, uint32) {
+		return esc.sc.AddToDataDefinition(defineID, simVar.Name, simVar.getUnitForDataDefinition(), simVar.GetDatumType(), 0, 0)
+	})
 	if err != nil {
 		esc.logf(LogInfo, "Error add SimVar ( %s ) in AddToDataDefinition error : %#v", simVar.Name, err)
-		return
+		return err
 	}
-	//esc.listSimVar = append(esc.listSimVar, []*SimVar{&simVar})
-	err, _ = esc.sc.SetDataOnSimObject(defineID, SIMCONNECT_OBJECT_ID_USER, 0, 0, uint32(len(simVar.data)), simVar.data)
+	_, err = esc.call("SetDataOnSimObject", simVar.Name, nil, func() (error, uint32) {
+		return esc.sc.SetDataOnSimObject(defineID, objectID, 0, 0, uint32(len(simVar.data)), simVar.data)
+	})
 	if err != nil {
 		esc.logf(LogInfo, "Error add SimVar ( %s ) in SetDataOnSimObject error : %#v", simVar.Name, err)
-		return
+		return err
 	}
-	err, _ = esc.sc.ClearDataDefinition(uint32(defineID))
+	err = esc.clearDataDefinition(defineID)
 	if err != nil {
 		esc.logf(LogInfo, "Error add SimVar ( %s ) in ClearDataDefinition error : %#v", simVar.Name, err)
-		return
+		return err
 	}
+	return nil
 }
-func (esc *EasySimConnect) connectSysEvent(name SystemEvent, cb func(interface{})) {
+
+// connectSysEvent subscribe to the system event and send the events converted by value in c, return c
+func (esc *EasySimConnect) connectSysEvent(name SystemEvent, c interface{}, value func(data interface{}) interface{}) interface{} {
+	esc.mutex.Lock()
 	esc.indexEvent++
-	esc.listEvent[esc.indexEvent] = cb
-	err, _ := esc.sc.SubscribeToSystemEvent(uint32(esc.indexEvent), name)
+	eventID := esc.indexEvent
+	esc.mutex.Unlock()
+	var restoreID uint32
+	sub := esc.newSubscription(c, func() error {
+		esc.removeRestore(restoreID)
+		esc.mutex.Lock()
+		delete(esc.listEvent, eventID)
+		esc.mutex.Unlock()
+		_, err := esc.call("UnsubscribeFromSystemEvent", string(name), nil, func() (error, uint32) {
+			return esc.sc.UnsubscribeFromSystemEvent(eventID)
+		})
+		return err
+	})
+	esc.mutex.Lock()
+	esc.listEvent[eventID] = func(data interface{}) {
+		sub.send(value(data), 0)
+	}
+	esc.mutex.Unlock()
+	subscribe := func() error {
+		_, err := esc.call("SubscribeToSystemEvent", string(name), nil, func() (error, uint32) {
+			return esc.sc.SubscribeToSystemEvent(eventID, name)
+		})
+		return err
+	}
+	restoreID = esc.addRestore(func() {
+		subscribe()
+	})
+	err := subscribe()
 	if err != nil {
-		esc.logf(LogInfo, "Error connect to Event %s in ConnectSysEventCrashed error : %#v", name, err)
+		esc.logf(LogInfo, "Error connect to Event %s error : %#v", name, err)
 	}
+	return c
+}
+
+// eventTrue return true for the events without data
+func eventTrue(data interface{}) interface{} {
+	return true
+}
+
+// eventState return true if dwData is not 0
+func eventState(data interface{}) interface{} {
+	return eventData(data) > 0
+}
+
+// eventFilename return the filename of an EventFilenameMessage
+func eventFilename(data interface{}) interface{} {
+	return data.(*EventFilenameMessage).Filename
+}
+
+// eventValue return dwData
+func eventValue(data interface{}) interface{} {
+	return eventData(data)
+}
+
+// eventRecv return the message
+func eventRecv(data interface{}) interface{} {
+	return data
 }
 
 // ConnectSysEventCrashed Request a notification if the user aircraft crashes.
 func (esc *EasySimConnect) ConnectSysEventCrashed() <-chan bool {
-	c := make(chan bool)
-	esc.connectSysEvent(SystemEventCrashed, func(data interface{}) {
-		c <- true
-	})
-	return c
+	return esc.connectSysEvent(SystemEventCrashed, make(chan bool), eventTrue).(chan bool)
 }
 
 // ConnectSysEventCrashReset Request a notification when the crash cut-scene has completed.
 func (esc *EasySimConnect) ConnectSysEventCrashReset() <-chan bool {
-	c := make(chan bool)
-	esc.connectSysEvent(SystemEventCrashReset, func(data interface{}) {
-		c <- true
-	})
-	return c
+	return esc.connectSysEvent(SystemEventCrashReset, make(chan bool), eventTrue).(chan bool)
 }
 
 // ConnectSysEventPause Request notifications when the flight is paused or unpaused, and also immediately returns the current pause state (1 = paused or 0 = unpaused). The state is returned in the dwData parameter.
 func (esc *EasySimConnect) ConnectSysEventPause() <-chan bool {
-	c := make(chan bool)
-	esc.connectSysEvent(SystemEventPause, func(data interface{}) {
-		event := data.(SIMCONNECT_RECV_EVENT)
-		c <- event.dwData > 0
-	})
-	return c
+	return esc.connectSysEvent(SystemEventPause, make(chan bool), eventState).(chan bool)
 }
 
 // ConnectSysEventPaused Request a notification when the flight is paused.
 func (esc *EasySimConnect) ConnectSysEventPaused() <-chan bool {
-	c := make(chan bool)
-	esc.connectSysEvent(SystemEventPaused, func(data interface{}) {
-		c <- true
-	})
-	return c
+	return esc.connectSysEvent(SystemEventPaused, make(chan bool), eventTrue).(chan bool)
 }
 
 // ConnectSysEventSim Request a notification when Sim start and stop.
 func (esc *EasySimConnect) ConnectSysEventSim() <-chan bool {
-	c := make(chan bool)
-	esc.connectSysEvent(SystemEventSim, func(data interface{}) {
-		event := data.(SIMCONNECT_RECV_EVENT)
-		c <- event.dwData > 0
-	})
-	return c
+	return esc.connectSysEvent(SystemEventSim, make(chan bool), eventState).(chan bool)
 }
 
 // ConnectSysEventFlightPlanDeactivated Request a notification when the active flight plan is de-activated.
 func (esc *EasySimConnect) ConnectSysEventFlightPlanDeactivated() <-chan bool {
-	c := make(chan bool)
-	esc.connectSysEvent(SystemEventFlightPlanDeactivated, func(data interface{}) {
-		c <- true
-	})
-	return c
+	return esc.connectSysEvent(SystemEventFlightPlanDeactivated, make(chan bool), eventTrue).(chan bool)
 }
 
 // ConnectSysEventAircraftLoaded Request a notification when the aircraft flight dynamics file is changed. These files have a .AIR extension. The filename is returned in a string.
 func (esc *EasySimConnect) ConnectSysEventAircraftLoaded() <-chan string {
-	c := make(chan string)
-	esc.connectSysEvent(SystemEventAircraftLoaded, func(data interface{}) {
-		event := data.(SIMCONNECT_RECV_EVENT_FILENAME)
-		c <- convStrToGoString(event.szFileName[:])
-	})
-	return c
+	return esc.connectSysEvent(SystemEventAircraftLoaded, make(chan string), eventFilename).(chan string)
 }
 
 // ConnectSysEventFlightLoaded 	Request a notification when a flight is loaded. Note that when a flight is ended, a default flight is typically loaded, so these events will occur when flights and missions are started and finished. The filename of the flight loaded is returned in a string
 func (esc *EasySimConnect) ConnectSysEventFlightLoaded() <-chan string {
-	c := make(chan string)
-	esc.connectSysEvent(SystemEventFlightLoaded, func(data interface{}) {
-		event := data.(SIMCONNECT_RECV_EVENT_FILENAME)
-		c <- convStrToGoString(event.szFileName[:])
-	})
-	return c
+	return esc.connectSysEvent(SystemEventFlightLoaded, make(chan string), eventFilename).(chan string)
 }
 
 // ConnectSysEventFlightSaved 	Request a notification when a flight is saved correctly. The filename of the flight saved is returned in a string
 func (esc *EasySimConnect) ConnectSysEventFlightSaved() <-chan string {
-	c := make(chan string)
-	esc.connectSysEvent(SystemEventFlightSaved, func(data interface{}) {
-		event := data.(SIMCONNECT_RECV_EVENT_FILENAME)
-		c <- convStrToGoString(event.szFileName[:])
-	})
-	return c
+	return esc.connectSysEvent(SystemEventFlightSaved, make(chan string), eventFilename).(chan string)
 }
 
 // ConnectSysEventFlightPlanActivated Request a notification when a new flight plan is activated. The filename of the activated flight plan is returned in a string.
 func (esc *EasySimConnect) ConnectSysEventFlightPlanActivated() <-chan string {
-	c := make(chan string)
-	esc.connectSysEvent(SystemEventFlightPlanActivated, func(data interface{}) {
-		event := data.(SIMCONNECT_RECV_EVENT_FILENAME)
-		c <- convStrToGoString(event.szFileName[:])
-	})
-	return c
+	return esc.connectSysEvent(SystemEventFlightPlanActivated, make(chan string), eventFilename).(chan string)
+}
+
+// eventData return dwData of an event message
+func eventData(data interface{}) uint32 {
+	return data.(eventMessage).eventMessage().Data
+}
+
+// ConnectSysEvent1sec Request a notification every second.
+func (esc *EasySimConnect) ConnectSysEvent1sec() <-chan bool {
+	return esc.connectSysEvent(SystemEvent1sec, make(chan bool), eventTrue).(chan bool)
+}
+
+// ConnectSysEvent4sec Request a notification every four seconds.
+func (esc *EasySimConnect) ConnectSysEvent4sec() <-chan bool {
+	return esc.connectSysEvent(SystemEvent4sec, make(chan bool), eventTrue).(chan bool)
+}
+
+// ConnectSysEvent6Hz Request notifications six times per second. This is the same rate that joystick movement events are transmitted.
+func (esc *EasySimConnect) ConnectSysEvent6Hz() <-chan bool {
+	return esc.connectSysEvent(SystemEvent6Hz, make(chan bool), eventTrue).(chan bool)
+}
+
+// FrameEvent is the frame rate and the simulation speed of the system events Frame and PauseFrame
+type FrameEvent struct {
+	FrameRate float32 // Visual frame rate in frames per second
+	SimSpeed  float32 // Simulation rate (ex: 0.5 for half speed)
+}
+
+func frameEvent(data interface{}) interface{} {
+	event := data.(*EventFrameMessage)
+	return FrameEvent{event.FrameRate, event.SimSpeed}
+}
+
+// ConnectSysEventFrame Request notifications every visual frame with the frame rate and the simulation speed.
+func (esc *EasySimConnect) ConnectSysEventFrame() <-chan FrameEvent {
+	return esc.connectSysEvent(SystemEventFrame, make(chan FrameEvent), frameEvent).(chan FrameEvent)
+}
+
+// ConnectSysEventPauseFrame Request notifications for every visual frame that the simulation is paused.
+func (esc *EasySimConnect) ConnectSysEventPauseFrame() <-chan FrameEvent {
+	return esc.connectSysEvent(SystemEventPauseFrame, make(chan FrameEvent), frameEvent).(chan FrameEvent)
+}
+
+// ObjectEvent is an AI object added or removed in the simulation
+type ObjectEvent struct {
+	ObjectID   uint32
+	ObjectType uint32 // see SIMCONNECT_SIMOBJECT_TYPE
+}
+
+func objectEvent(data interface{}) interface{} {
+	event := data.(*EventObjectAddRemoveMessage)
+	return ObjectEvent{event.Data, event.ObjectType}
+}
+
+// ConnectSysEventObjectAdded Request a notification when an AI object is added to the simulation.
+func (esc *EasySimConnect) ConnectSysEventObjectAdded() <-chan ObjectEvent {
+	return esc.connectSysEvent(SystemEventObjectAdded, make(chan ObjectEvent), objectEvent).(chan ObjectEvent)
+}
+
+// ConnectSysEventObjectRemoved Request a notification when an AI object is removed from the simulation.
+func (esc *EasySimConnect) ConnectSysEventObjectRemoved() <-chan ObjectEvent {
+	return esc.connectSysEvent(SystemEventObjectRemoved, make(chan ObjectEvent), objectEvent).(chan ObjectEvent)
+}
+
+// View is the flags SIMCONNECT_VIEW_SYSTEM_EVENT_DATA_* of the system event View
+type View uint32
+
+// Cockpit2D return true for the 2D Panels in cockpit view
+func (v View) Cockpit2D() bool {
+	return v&SIMCONNECT_VIEW_SYSTEM_EVENT_DATA_COCKPIT_2D != 0
+}
+
+// CockpitVirtual return true for the Virtual (3D) panels in cockpit view
+func (v View) CockpitVirtual() bool {
+	return v&SIMCONNECT_VIEW_SYSTEM_EVENT_DATA_COCKPIT_VIRTUAL != 0
+}
+
+// Orthogonal return true for the Orthogonal (Map) view
+func (v View) Orthogonal() bool {
+	return v&SIMCONNECT_VIEW_SYSTEM_EVENT_DATA_ORTHOGONAL != 0
+}
+
+func eventView(data interface{}) interface{} {
+	return View(eventData(data))
+}
+
+// ConnectSysEventView Requests a notification when the user aircraft view is changed. This request will also return the current view immediately.
+func (esc *EasySimConnect) ConnectSysEventView() <-chan View {
+	return esc.connectSysEvent(SystemEventView, make(chan View), eventView).(chan View)
+}
+
+func eventSound(data interface{}) interface{} {
+	return eventData(data)&SIMCONNECT_SOUND_SYSTEM_EVENT_DATA_MASTER != 0
+}
+
+// ConnectSysEventSound Requests a notification when the master sound switch is changed. This request will also return the current state of the master sound switch immediately (true = on).
+func (esc *EasySimConnect) ConnectSysEventSound() <-chan bool {
+	return esc.connectSysEvent(SystemEventSound, make(chan bool), eventSound).(chan bool)
+}
+
+// ConnectSysEventPositionChanged Request a notification when the user changes the position of their aircraft through a dialog.
+func (esc *EasySimConnect) ConnectSysEventPositionChanged() <-chan bool {
+	return esc.connectSysEvent(SystemEventPositionChanged, make(chan bool), eventTrue).(chan bool)
+}
+
+// ConnectSysEventSimStart Request a notification when the simulator is running. Typically the user is actively controlling the aircraft on the ground or in the air.
+func (esc *EasySimConnect) ConnectSysEventSimStart() <-chan bool {
+	return esc.connectSysEvent(SystemEventSimStart, make(chan bool), eventTrue).(chan bool)
+}
+
+// ConnectSysEventSimStop Request a notification when the simulator is not running. Typically the user is loading a flight, navigating the shell or in a dialog.
+func (esc *EasySimConnect) ConnectSysEventSimStop() <-chan bool {
+	return esc.connectSysEvent(SystemEventSimStop, make(chan bool), eventTrue).(chan bool)
+}
+
+// ConnectSysEventUnpaused Request a notification when the flight is un-paused.
+func (esc *EasySimConnect) ConnectSysEventUnpaused() <-chan bool {
+	return esc.connectSysEvent(SystemEventUnpaused, make(chan bool), eventTrue).(chan bool)
+}
+
+// ConnectSysEventWeatherModeChanged Request a notification when the weather mode is changed. The new mode is returned (SIMCONNECT_WEATHER_MODE_*).
+func (esc *EasySimConnect) ConnectSysEventWeatherModeChanged() <-chan uint32 {
+	return esc.connectSysEvent(SystemEventWeatherModeChanged, make(chan uint32), eventValue).(chan uint32)
+}
+
+// ConnectSysEventMissionCompleted Request a notification when the user has completed a mission. The result is returned (SIMCONNECT_MISSION_FAILED, SIMCONNECT_MISSION_CRASHED or SIMCONNECT_MISSION_SUCCEEDED).
+func (esc *EasySimConnect) ConnectSysEventMissionCompleted() <-chan uint32 {
+	return esc.connectSysEvent(SystemEventMissionCompleted, make(chan uint32), eventValue).(chan uint32)
+}
+
+// ConnectSysEventCustomMissionActionExecuted Request a notification when a mission action has been executed.
+func (esc *EasySimConnect) ConnectSysEventCustomMissionActionExecuted() <-chan *CustomActionMessage {
+	return esc.connectSysEvent(SystemEventCustomMissionActionExecuted, make(chan *CustomActionMessage), eventRecv).(chan *CustomActionMessage)
+}
+
+// ConnectSysEventMultiplayerClientStarted Request a notification when the client has successfully joined a multiplayer race. This event is only sent to the client, not the host of the session.
+func (esc *EasySimConnect) ConnectSysEventMultiplayerClientStarted() <-chan bool {
+	return esc.connectSysEvent(SystemEventMultiplayerClientStarted, make(chan bool), eventTrue).(chan bool)
+}
+
+// ConnectSysEventMultiplayerServerStarted Request a notification for the host of a multiplayer race when the race is open to other players in the lobby.
+func (esc *EasySimConnect) ConnectSysEventMultiplayerServerStarted() <-chan bool {
+	return esc.connectSysEvent(SystemEventMultiplayerServerStarted, make(chan bool), eventTrue).(chan bool)
+}
+
+// ConnectSysEventMultiplayerSessionEnded Request a notification when the mutliplayer race session is terminated.
+func (esc *EasySimConnect) ConnectSysEventMultiplayerSessionEnded() <-chan bool {
+	return esc.connectSysEvent(SystemEventMultiplayerSessionEnded, make(chan bool), eventTrue).(chan bool)
+}
+
+// ConnectSysEventRaceEnd Request a notification of the race results for each racer, one message for each player.
+func (esc *EasySimConnect) ConnectSysEventRaceEnd() <-chan *EventRaceEndMessage {
+	return esc.connectSysEvent(SystemEventRaceEnd, make(chan *EventRaceEndMessage), eventRecv).(chan *EventRaceEndMessage)
+}
+
+// ConnectSysEventRaceLap Request a notification of the lap results for each racer, one message for each player.
+func (esc *EasySimConnect) ConnectSysEventRaceLap() <-chan *EventRaceLapMessage {
+	return esc.connectSysEvent(SystemEventRaceLap, make(chan *EventRaceLapMessage), eventRecv).(chan *EventRaceLapMessage)
 }
 
 // ShowText display a text on the screen in the simulator.
 //
 // ime is in second and return chan a confirmation for the simulator
 func (esc *EasySimConnect) ShowText(str string, time float32, color PrintColor) (<-chan int, error) {
-	cReturn := make(chan int)
+	return esc.ShowTextContext(context.Background(), str, time, color)
+}
+
+// ShowTextContext is ShowText with a context, after the cancellation of ctx the confirmations are ignored. The chan
+// keep one confirmation not read, the next are lost until it is read. The entry of the text is removed at the last
+// confirmation (removed, replaced or timeout).
+func (esc *EasySimConnect) ShowTextContext(ctx context.Context, str string, time float32, color PrintColor) (<-chan int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	cReturn := make(chan int, 1)
+	cRemoved := make(chan struct{})
+	esc.mutex.Lock()
 	esc.indexEvent++
-	esc.listEvent[esc.indexEvent] = func(data interface{}) {
-		cReturn <- int(data.(SIMCONNECT_RECV_EVENT).dwData)
+	eventID := esc.indexEvent
+	esc.listEvent[eventID] = func(data interface{}) {
+		result := int(data.(*EventMessage).Data)
+		switch result {
+		case SIMCONNECT_TEXT_RESULT_REMOVED, SIMCONNECT_TEXT_RESULT_REPLACED, SIMCONNECT_TEXT_RESULT_TIMEOUT:
+			// the last confirmation of the text, called by the dispatch with esc.mutex unlocked
+			esc.mutex.Lock()
+			if _, found := esc.listEvent[eventID]; found {
+				delete(esc.listEvent, eventID)
+				close(cRemoved)
+			}
+			esc.mutex.Unlock()
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case cReturn <- result:
+		default:
+		}
 	}
-	err, _ := esc.sc.Text(uint32(color), time, esc.indexEvent, str)
+	esc.mutex.Unlock()
+	if ctx.Done() != nil {
+		go func() {
+			select {
+			case <-ctx.Done():
+				esc.mutex.Lock()
+				delete(esc.listEvent, eventID)
+				esc.mutex.Unlock()
+			case <-cRemoved:
+			case <-esc.ctx.Done():
+			}
+		}()
+	}
+	_, err := esc.call("Text", str, nil, func() (error, uint32) {
+		return esc.sc.Text(uint32(color), time, eventID, str)
+	})
 	return cReturn, err
 }
-func (esc *EasySimConnect) runSimEvent(simEvent SimEvent) {
-	esc.sc.TransmitClientEvent(SIMCONNECT_OBJECT_ID_USER, simEvent.eventID, simEvent.Value, SIMCONNECT_GROUP_PRIORITY_HIGHEST, SIMCONNECT_EVENT_FLAG_GROUPID_IS_PRIORITY)
+
+// runSimEvent transmit the event of the run c, the errors are sent in the chan of Errors. An exception of the call
+// remove and close c.
+func (esc *EasySimConnect) runSimEvent(simEvent SimEvent, c chan int32) error {
+	cException := make(chan *SimConnectError, 1)
+	id, err := esc.call("TransmitClientEvent", string(simEvent.Mapping), cException, func() (error, uint32) {
+		return esc.sc.TransmitClientEvent(simEvent.objectID, simEvent.eventID, simEvent.Value, SIMCONNECT_GROUP_PRIORITY_HIGHEST, SIMCONNECT_EVENT_FLAG_GROUPID_IS_PRIORITY)
+	})
+	if err != nil {
+		esc.reportError(fmt.Errorf("TransmitClientEvent ( %s ) : %w", simEvent.Mapping, err))
+		return err
+	}
+	go func() {
+		defer esc.release(id)
+		select {
+		case exception := <-cException:
+			if simEvent.waiters.remove(c) {
+				close(c)
+			}
+			esc.reportError(exception)
+		case <-time.After(exceptionDelay):
+		case <-esc.ctx.Done():
+		}
+	}()
+	return nil
 }
 
 // NewSimEvent return new instance of SimEvent and you can run SimEvent.Run()
 func (esc *EasySimConnect) NewSimEvent(simEventStr KeySimEvent) SimEvent {
+	esc.mutex.Lock()
 	instance, found := esc.listSimEvent[simEventStr]
 	if found {
+		esc.mutex.Unlock()
 		return instance
 	}
 
 	esc.indexEvent++
-	c := make(chan int32)
+	waiters := new(simEventWaiters)
 	simEvent := SimEvent{
 		simEventStr,
 		0,
 		esc.runSimEvent,
-		c,
+		waiters,
 		esc.indexEvent,
+		esc.ctx.Done(),
+		SIMCONNECT_OBJECT_ID_USER,
 	}
-	esc.listEvent[esc.indexEvent] = func(data interface{}) {
-		recv := data.(SIMCONNECT_RECV_EVENT)
-		c <- int32(recv.dwData)
+	esc.listEvent[simEvent.eventID] = func(data interface{}) {
+		recv := data.(*EventMessage)
+		if c := waiters.next(recv.Data); c != nil {
+			c <- int32(recv.Data)
+		}
 	}
-	esc.sc.MapClientEventToSimEvent(esc.indexEvent, string(simEventStr))
-	esc.sc.AddClientEventToNotificationGroup(0, esc.indexEvent, false)
-	esc.sc.SetNotificationGroupPriority(0, SIMCONNECT_GROUP_PRIORITY_HIGHEST)
 	esc.listSimEvent[simEventStr] = simEvent
+	esc.mutex.Unlock()
+	mapSimEvent := func() {
+		esc.call("MapClientEventToSimEvent", string(simEventStr), nil, func() (error, uint32) {
+			return esc.sc.MapClientEventToSimEvent(simEvent.eventID, string(simEventStr))
+		})
+		esc.call("AddClientEventToNotificationGroup", string(simEventStr), nil, func() (error, uint32) {
+			return esc.sc.AddClientEventToNotificationGroup(simEventGroupID, simEvent.eventID, false)
+		})
+		esc.call("SetNotificationGroupPriority", "", nil, func() (error, uint32) {
+			return esc.sc.SetNotificationGroupPriority(simEventGroupID, SIMCONNECT_GROUP_PRIORITY_HIGHEST)
+		})
+	}
+	esc.addRestore(mapSimEvent)
+	mapSimEvent()
 	return simEvent
 }
 
 // SimEvent Use for generate action in the simulator
 type SimEvent struct {
-	Mapping KeySimEvent
-	Value   int
-	run     func(simEvent SimEvent)
-	cb      <-chan int32
-	eventID uint32
+	Mapping  KeySimEvent
+	Value    int
+	run      func(simEvent SimEvent, c chan int32) error
+	waiters  *simEventWaiters
+	eventID  uint32
+	done     <-chan struct{}
+	objectID uint32
 }
 
-// Run return chan bool when receive the event is finish
+// Run return chan bool when receive the event is finish. If the event fail the chan is closed without value and the
+// error is sent in the chan of Errors.
 func (s SimEvent) Run() <-chan int32 {
-	s.run(s)
-	return s.cb
+	c := s.waiters.add(uint32(s.Value))
+	if err := s.run(s, c); err != nil {
+		// the next confirmations are for the next runs
+		if s.waiters.remove(c) {
+			close(c)
+		}
+	}
+	return c
+}
+
+// RunContext is Run with a context, the chan is closed without value if ctx is done before the event
+func (s SimEvent) RunContext(ctx context.Context) <-chan int32 {
+	c := make(chan int32, 1)
+	if ctx.Err() != nil {
+		close(c)
+		return c
+	}
+	cb := s.Run()
+	go func() {
+		defer close(c)
+		select {
+		case value, ok := <-cb:
+			if ok {
+				c <- value
+			}
+		case <-ctx.Done():
+		case <-s.done:
+		}
+	}()
+	return c
+}
+
+// simEventWaiters is the chans of the runs of a SimEvent waiting the confirmation of the simulator, in the order of
+// the runs. The chans have a buffer, a confirmation without reader (ex: RunContext canceled) don't block the dispatch.
+type simEventWaiters struct {
+	mutex sync.Mutex
+	list  []simEventWaiter
+}
+
+type simEventWaiter struct {
+	c     chan int32
+	value uint32
+}
+
+// add return the chan of a new run of the value
+func (w *simEventWaiters) add(value uint32) chan int32 {
+	c := make(chan int32, 1)
+	w.mutex.Lock()
+	w.list = append(w.list, simEventWaiter{c, value})
+	w.mutex.Unlock()
+	return c
+}
+
+// remove the chan of a failed run, return false if the chan is not waiting
+func (w *simEventWaiters) remove(c chan int32) bool {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for i, waiter := range w.list {
+		if waiter.c == c {
+			w.list = append(w.list[:i:i], w.list[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
+
+// next return the chan of the oldest run of the value or nil. The events of the pilot have the same ID, an event
+// without run of his value is not a confirmation.
+func (w *simEventWaiters) next(value uint32) chan int32 {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for i, waiter := range w.list {
+		if waiter.value == value {
+			w.list = append(w.list[:i:i], w.list[i+1:]...)
+			return waiter.c
+		}
+	}
+	return nil
 }
 
 // RunWithValue return chan bool when receive the event is finish
@@ -454,3 +1424,10 @@ func (s SimEvent) RunWithValue(value int) <-chan int32 {
 	s.Value = value
 	return s.Run()
 }
+
+// RunOnObject is RunWithValue for the object objectID (ex: KeyGearUp on an AI aircraft created by this client)
+func (s SimEvent) RunOnObject(objectID uint32, value int) <-chan int32 {
+	s.Value = value
+	s.objectID = objectID
+	return s.Run()
+}
@@ -0,0 +1,25 @@
+package simconnect
+
+import "testing"
+
+func TestSimEventWaiters(t *testing.T) {
+	var w simEventWaiters
+	first, second := w.add(10), w.add(20)
+	// an event of the pilot is not a confirmation
+	if c := w.next(30); c != nil {
+		t.Error("next of an event without run")
+	}
+	if c := w.next(20); c != second {
+		t.Error("next(20) is not the run of 20")
+	}
+	third := w.add(10)
+	if !w.remove(first) || w.remove(first) {
+		t.Error("remove of the first run")
+	}
+	if c := w.next(10); c != third {
+		t.Error("next(10) is not the last run of 10")
+	}
+	if c := w.next(10); c != nil {
+		t.Error("next without run")
+	}
+}
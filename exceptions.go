@@ -1,5 +1,7 @@
 package simconnect
 
+import "fmt"
+
 func getTextException(i uint32) string {
 	switch i {
 	case 0:
@@ -82,3 +84,80 @@ func getTextException(i uint32) string {
 		return "Unknow exception"
 	}
 }
+
+// Exception is a SIMCONNECT_EXCEPTION, the Err* constants can be used with errors.Is
+//
+//	if errors.Is(err, ErrNameUnrecognized) {
+//		// the SimVar or the unit is unknown
+//	}
+type Exception uint32
+
+func (e Exception) Error() string {
+	return getTextException(uint32(e))
+}
+
+// Exceptions of SimConnect
+const (
+	ErrError                         = Exception(SIMCONNECT_EXCEPTION_ERROR)
+	ErrSizeMismatch                  = Exception(SIMCONNECT_EXCEPTION_SIZE_MISMATCH)
+	ErrUnrecognizedID                = Exception(SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID)
+	ErrUnopened                      = Exception(SIMCONNECT_EXCEPTION_UNOPENED)
+	ErrVersionMismatch               = Exception(SIMCONNECT_EXCEPTION_VERSION_MISMATCH)
+	ErrTooManyGroups                 = Exception(SIMCONNECT_EXCEPTION_TOO_MANY_GROUPS)
+	ErrNameUnrecognized              = Exception(SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED)
+	ErrTooManyEventNames             = Exception(SIMCONNECT_EXCEPTION_TOO_MANY_EVENT_NAMES)
+	ErrEventIDDuplicate              = Exception(SIMCONNECT_EXCEPTION_EVENT_ID_DUPLICATE)
+	ErrTooManyMaps                   = Exception(SIMCONNECT_EXCEPTION_TOO_MANY_MAPS)
+	ErrTooManyObjects                = Exception(SIMCONNECT_EXCEPTION_TOO_MANY_OBJECTS)
+	ErrTooManyRequests               = Exception(SIMCONNECT_EXCEPTION_TOO_MANY_REQUESTS)
+	ErrWeatherInvalidPort            = Exception(SIMCONNECT_EXCEPTION_WEATHER_INVALID_PORT)
+	ErrWeatherInvalidMetar           = Exception(SIMCONNECT_EXCEPTION_WEATHER_INVALID_METAR)
+	ErrWeatherUnableToGetObservation = Exception(SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_GET_OBSERVATION)
+	ErrWeatherUnableToCreateStation  = Exception(SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_CREATE_STATION)
+	ErrWeatherUnableToRemoveStation  = Exception(SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_REMOVE_STATION)
+	ErrInvalidDataType               = Exception(SIMCONNECT_EXCEPTION_INVALID_DATA_TYPE)
+	ErrInvalidDataSize               = Exception(SIMCONNECT_EXCEPTION_INVALID_DATA_SIZE)
+	ErrDataError                     = Exception(SIMCONNECT_EXCEPTION_DATA_ERROR)
+	ErrInvalidArray                  = Exception(SIMCONNECT_EXCEPTION_INVALID_ARRAY)
+	ErrCreateObjectFailed            = Exception(SIMCONNECT_EXCEPTION_CREATE_OBJECT_FAILED)
+	ErrLoadFlightplanFailed          = Exception(SIMCONNECT_EXCEPTION_LOAD_FLIGHTPLAN_FAILED)
+	ErrOperationInvalidForObjectType = Exception(SIMCONNECT_EXCEPTION_OPERATION_INVALID_FOR_OBJECT_TYPE)
+	ErrIllegalOperation              = Exception(SIMCONNECT_EXCEPTION_ILLEGAL_OPERATION)
+	ErrAlreadySubscribed             = Exception(SIMCONNECT_EXCEPTION_ALREADY_SUBSCRIBED)
+	ErrInvalidEnum                   = Exception(SIMCONNECT_EXCEPTION_INVALID_ENUM)
+	ErrDefinitionError               = Exception(SIMCONNECT_EXCEPTION_DEFINITION_ERROR)
+	ErrDuplicateID                   = Exception(SIMCONNECT_EXCEPTION_DUPLICATE_ID)
+	ErrDatumID                       = Exception(SIMCONNECT_EXCEPTION_DATUM_ID)
+	ErrOutOfBounds                   = Exception(SIMCONNECT_EXCEPTION_OUT_OF_BOUNDS)
+	ErrAlreadyCreated                = Exception(SIMCONNECT_EXCEPTION_ALREADY_CREATED)
+	ErrObjectOutsideRealityBubble    = Exception(SIMCONNECT_EXCEPTION_OBJECT_OUTSIDE_REALITY_BUBBLE)
+	ErrObjectContainer               = Exception(SIMCONNECT_EXCEPTION_OBJECT_CONTAINER)
+	ErrObjectAI                      = Exception(SIMCONNECT_EXCEPTION_OBJECT_AI)
+	ErrObjectATC                     = Exception(SIMCONNECT_EXCEPTION_OBJECT_ATC)
+	ErrObjectSchedule                = Exception(SIMCONNECT_EXCEPTION_OBJECT_SCHEDULE)
+)
+
+// SimConnectError is an exception of the simulator with the call of EasySimConnect at the origin of the exception
+type SimConnectError struct {
+	Code   Exception
+	Op     string // SimConnect function of the call (ex: AddToDataDefinition), empty if the call is unknown
+	Param  uint32 // index of the parameter in error (dwIndex)
+	SendID uint32
+	Detail string // SimVar, event or text used in the call
+}
+
+func (e *SimConnectError) Error() string {
+	op := e.Op
+	if op == "" {
+		op = "unknown call"
+	}
+	if e.Detail != "" {
+		op = fmt.Sprintf("%s ( %s )", op, e.Detail)
+	}
+	return fmt.Sprintf("SimConnect %s : %s (parameter %d)", op, e.Code, e.Param)
+}
+
+// Unwrap return the Exception for errors.Is
+func (e *SimConnectError) Unwrap() error {
+	return e.Code
+}
@@ -0,0 +1,483 @@
+package simconnect
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// facilitiesTimeout is the maximum time for waiting all the messages of a facilities list
+const facilitiesTimeout = 10 * time.Second
+
+// FacilitiesOptions is the options of NewFacilities
+type FacilitiesOptions struct {
+	// Refresh is the period of the full request of the subscribed lists, default 30s. The subscription of SimConnect
+	// report only the facilities entering the reality bubble, the refresh remove the facilities leaving it.
+	Refresh time.Duration
+}
+
+func (o FacilitiesOptions) withDefault() FacilitiesOptions {
+	if o.Refresh <= 0 {
+		o.Refresh = 30 * time.Second
+	}
+	return o
+}
+
+// Facilities request the airports, waypoints, NDBs and VORs of the reality bubble. The lists subscribed by Subscribe are
+// kept in a cache updated by the simulator.
+//
+//	facilities := esc.NewFacilities(FacilitiesOptions{})
+//	defer facilities.Close()
+//	vors, err := facilities.RequestVORs()
+//	facilities.Subscribe(SIMCONNECT_FACILITY_LIST_TYPE_AIRPORT)
+//	airport, found := facilities.Airport("LFPG")
+type Facilities struct {
+	esc           *EasySimConnect
+	options       FacilitiesOptions
+	mutex         sync.Mutex
+	cache         map[uint32]map[facilityKey]interface{} // facilities of the subscribed types
+	subscriptions map[uint32]facilitiesSubscription
+	done          chan struct{}
+	closed        bool
+}
+
+// facilityKey is the key of the cache, the waypoints, NDBs and VORs can have the same ICAO at different positions
+type facilityKey struct {
+	icao      string
+	latitude  float64
+	longitude float64
+}
+
+type facilitiesSubscription struct {
+	requestID uint32
+	restoreID uint32
+}
+
+// NewFacilities return a Facilities, Close unsubscribe the lists
+func (esc *EasySimConnect) NewFacilities(options FacilitiesOptions) *Facilities {
+	f := &Facilities{
+		esc:           esc,
+		options:       options.withDefault(),
+		cache:         make(map[uint32]map[facilityKey]interface{}),
+		subscriptions: make(map[uint32]facilitiesSubscription),
+		done:          make(chan struct{}),
+	}
+	go f.runRefresh()
+	return f
+}
+
+// RequestAirports return the airports of the reality bubble sorted by ICAO
+func (f *Facilities) RequestAirports() ([]FacilityAirport, error) {
+	list, err := f.requestList(SIMCONNECT_FACILITY_LIST_TYPE_AIRPORT)
+	if err != nil {
+		return nil, err
+	}
+	return toAirports(list), nil
+}
+
+// RequestWaypoints return the waypoints of the reality bubble sorted by ICAO
+func (f *Facilities) RequestWaypoints() ([]FacilityWaypoint, error) {
+	list, err := f.requestList(SIMCONNECT_FACILITY_LIST_TYPE_WAYPOINT)
+	if err != nil {
+		return nil, err
+	}
+	return toWaypoints(list), nil
+}
+
+// RequestNDBs return the NDBs of the reality bubble sorted by ICAO
+func (f *Facilities) RequestNDBs() ([]FacilityNDB, error) {
+	list, err := f.requestList(SIMCONNECT_FACILITY_LIST_TYPE_NDB)
+	if err != nil {
+		return nil, err
+	}
+	return toNDBs(list), nil
+}
+
+// RequestVORs return the VORs of the reality bubble sorted by ICAO
+func (f *Facilities) RequestVORs() ([]FacilityVOR, error) {
+	list, err := f.requestList(SIMCONNECT_FACILITY_LIST_TYPE_VOR)
+	if err != nil {
+		return nil, err
+	}
+	return toVORs(list), nil
+}
+
+// Subscribe keep the facilities of the type (SIMCONNECT_FACILITY_LIST_TYPE_*) in the cache. The facilities entering the
+// reality bubble are added by the simulator, the facilities leaving it are removed at the next refresh.
+func (f *Facilities) Subscribe(listType uint32) error {
+	f.mutex.Lock()
+	if f.closed {
+		f.mutex.Unlock()
+		return errors.New("Facilities is closed")
+	}
+	if _, found := f.subscriptions[listType]; found {
+		f.mutex.Unlock()
+		return nil
+	}
+	requestID := f.esc.newRequest(func(data interface{}) {
+		if t, _, list, ok := facilitiesOf(data); ok && t == listType {
+			f.add(listType, list)
+		}
+	})
+	// the subscription is reserved before the call, a concurrent Subscribe of the type do nothing
+	f.subscriptions[listType] = facilitiesSubscription{requestID: requestID}
+	// the simulator send the facilities of the bubble at the subscription
+	f.cache[listType] = make(map[facilityKey]interface{})
+	f.mutex.Unlock()
+	err := f.esc.callWait("SubscribeToFacilities", fmt.Sprint(listType), func() (error, uint32) {
+		return f.esc.sc.SubscribeToFacilities(listType, requestID)
+	})
+	if err != nil {
+		f.esc.removeRequest(requestID)
+		f.mutex.Lock()
+		if sub, found := f.subscriptions[listType]; found && sub.requestID == requestID {
+			delete(f.subscriptions, listType)
+			delete(f.cache, listType)
+		}
+		f.mutex.Unlock()
+		return err
+	}
+	restoreID := f.esc.addRestore(func() {
+		f.esc.call("SubscribeToFacilities", fmt.Sprint(listType), nil, func() (error, uint32) {
+			return f.esc.sc.SubscribeToFacilities(listType, requestID)
+		})
+	})
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	sub, found := f.subscriptions[listType]
+	if !found || sub.requestID != requestID {
+		// unsubscribed during the call
+		f.esc.removeRestore(restoreID)
+		return nil
+	}
+	sub.restoreID = restoreID
+	f.subscriptions[listType] = sub
+	return nil
+}
+
+// Unsubscribe stop the subscription of the type and clear his cache
+func (f *Facilities) Unsubscribe(listType uint32) error {
+	f.mutex.Lock()
+	sub, found := f.subscriptions[listType]
+	delete(f.subscriptions, listType)
+	delete(f.cache, listType)
+	f.mutex.Unlock()
+	if !found {
+		return nil
+	}
+	f.esc.removeRestore(sub.restoreID)
+	f.esc.removeRequest(sub.requestID)
+	return f.esc.callWait("UnsubscribeToFacilities", fmt.Sprint(listType), func() (error, uint32) {
+		return f.esc.sc.UnsubscribeToFacilities(listType)
+	})
+}
+
+// Airports return the airports of the cache sorted by ICAO, empty if the airports are not subscribed
+func (f *Facilities) Airports() []FacilityAirport {
+	return toAirports(f.cached(SIMCONNECT_FACILITY_LIST_TYPE_AIRPORT))
+}
+
+// Waypoints return the waypoints of the cache sorted by ICAO, empty if the waypoints are not subscribed
+func (f *Facilities) Waypoints() []FacilityWaypoint {
+	return toWaypoints(f.cached(SIMCONNECT_FACILITY_LIST_TYPE_WAYPOINT))
+}
+
+// NDBs return the NDBs of the cache sorted by ICAO, empty if the NDBs are not subscribed
+func (f *Facilities) NDBs() []FacilityNDB {
+	return toNDBs(f.cached(SIMCONNECT_FACILITY_LIST_TYPE_NDB))
+}
+
+// VORs return the VORs of the cache sorted by ICAO, empty if the VORs are not subscribed
+func (f *Facilities) VORs() []FacilityVOR {
+	return toVORs(f.cached(SIMCONNECT_FACILITY_LIST_TYPE_VOR))
+}
+
+// Airport return the airport of the cache
+func (f *Facilities) Airport(icao string) (FacilityAirport, bool) {
+	airports := toAirports(f.lookup(SIMCONNECT_FACILITY_LIST_TYPE_AIRPORT, icao))
+	if len(airports) == 0 {
+		return FacilityAirport{}, false
+	}
+	return airports[0], true
+}
+
+// Waypoint return the waypoints of the cache with the ICAO, more than one if the ICAO is used at different positions
+func (f *Facilities) Waypoint(icao string) []FacilityWaypoint {
+	return toWaypoints(f.lookup(SIMCONNECT_FACILITY_LIST_TYPE_WAYPOINT, icao))
+}
+
+// NDB return the NDBs of the cache with the ICAO, more than one if the ICAO is used at different positions
+func (f *Facilities) NDB(icao string) []FacilityNDB {
+	return toNDBs(f.lookup(SIMCONNECT_FACILITY_LIST_TYPE_NDB, icao))
+}
+
+// VOR return the VORs of the cache with the ICAO, more than one if the ICAO is used at different positions
+func (f *Facilities) VOR(icao string) []FacilityVOR {
+	return toVORs(f.lookup(SIMCONNECT_FACILITY_LIST_TYPE_VOR, icao))
+}
+
+// Close unsubscribe the lists and stop the refresh. Call Close more than once do nothing.
+func (f *Facilities) Close() error {
+	f.mutex.Lock()
+	if f.closed {
+		f.mutex.Unlock()
+		return nil
+	}
+	f.closed = true
+	close(f.done)
+	listTypes := make([]uint32, 0, len(f.subscriptions))
+	for listType := range f.subscriptions {
+		listTypes = append(listTypes, listType)
+	}
+	f.mutex.Unlock()
+	var err error
+	for _, listType := range listTypes {
+		if e := f.Unsubscribe(listType); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// requestList request the list of the type and wait all his messages
+func (f *Facilities) requestList(listType uint32) ([]interface{}, error) {
+	cList := make(chan []interface{}, 1)
+	var chunks facilityChunks
+	requestID := f.esc.newRequest(func(data interface{}) {
+		_, header, list, ok := facilitiesOf(data)
+		if !ok {
+			return
+		}
+		if complete, done := chunks.add(header, list); done {
+			select {
+			case cList <- complete:
+			default:
+			}
+		}
+	})
+	defer f.esc.removeRequest(requestID)
+	cException := make(chan *SimConnectError, 1)
+	id, err := f.esc.call("RequestFacilitiesList", fmt.Sprint(listType), cException, func() (error, uint32) {
+		return f.esc.sc.RequestFacilitiesList(listType, requestID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer f.esc.release(id)
+	select {
+	case list := <-cList:
+		sortFacilities(list)
+		return list, nil
+	case exception := <-cException:
+		return nil, exception
+	case <-time.After(facilitiesTimeout):
+		return nil, fmt.Errorf("no response of the simulator for RequestFacilitiesList ( %d )", listType)
+	case <-f.esc.ctx.Done():
+		return nil, errors.New("EasySimConnect is closed")
+	}
+}
+
+// runRefresh replace periodically the cache of the subscribed types by a full list
+func (f *Facilities) runRefresh() {
+	ticker := time.NewTicker(f.options.Refresh)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-f.done:
+			return
+		case <-f.esc.ctx.Done():
+			return
+		}
+		f.mutex.Lock()
+		listTypes := make([]uint32, 0, len(f.subscriptions))
+		for listType := range f.subscriptions {
+			listTypes = append(listTypes, listType)
+		}
+		f.mutex.Unlock()
+		for _, listType := range listTypes {
+			list, err := f.requestList(listType)
+			if err != nil {
+				f.esc.logf(LogWarn, "Error refresh facilities %d : %v", listType, err)
+				continue
+			}
+			f.replace(listType, list)
+		}
+	}
+}
+
+// add put the facilities in the cache of the type if it is subscribed
+func (f *Facilities) add(listType uint32, list []interface{}) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	cache, found := f.cache[listType]
+	if !found {
+		return
+	}
+	for _, facility := range list {
+		cache[keyOf(facility)] = facility
+	}
+}
+
+// replace set the cache of the type if it is subscribed
+func (f *Facilities) replace(listType uint32, list []interface{}) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if _, found := f.cache[listType]; !found {
+		return
+	}
+	cache := make(map[facilityKey]interface{}, len(list))
+	for _, facility := range list {
+		cache[keyOf(facility)] = facility
+	}
+	f.cache[listType] = cache
+}
+
+func (f *Facilities) cached(listType uint32) []interface{} {
+	f.mutex.Lock()
+	list := make([]interface{}, 0, len(f.cache[listType]))
+	for _, facility := range f.cache[listType] {
+		list = append(list, facility)
+	}
+	f.mutex.Unlock()
+	sortFacilities(list)
+	return list
+}
+
+// lookup return the facilities of the cache with the ICAO sorted by position
+func (f *Facilities) lookup(listType uint32, icao string) []interface{} {
+	f.mutex.Lock()
+	var list []interface{}
+	for key, facility := range f.cache[listType] {
+		if key.icao == icao {
+			list = append(list, facility)
+		}
+	}
+	f.mutex.Unlock()
+	sortFacilities(list)
+	return list
+}
+
+// facilityChunks reassemble the messages of a list, in any order. A new list start after a complete list or when
+// OutOf change.
+type facilityChunks struct {
+	outOf    uint32
+	received map[uint32][]interface{}
+}
+
+// add the message of the list and return the complete list when all the messages are received
+func (c *facilityChunks) add(header FacilitiesListHeader, list []interface{}) ([]interface{}, bool) {
+	outOf := header.OutOf
+	if outOf == 0 {
+		outOf = 1
+	}
+	if c.received == nil || outOf != c.outOf {
+		c.outOf = outOf
+		c.received = make(map[uint32][]interface{})
+	}
+	if header.EntryNumber >= outOf {
+		return nil, false
+	}
+	c.received[header.EntryNumber] = list
+	if uint32(len(c.received)) < outOf {
+		return nil, false
+	}
+	var complete []interface{}
+	for entry := uint32(0); entry < outOf; entry++ {
+		complete = append(complete, c.received[entry]...)
+	}
+	c.received = nil
+	return complete, true
+}
+
+// facilitiesOf return the type, the header and the facilities of a list message
+func facilitiesOf(recv interface{}) (uint32, FacilitiesListHeader, []interface{}, bool) {
+	var list []interface{}
+	switch recv := recv.(type) {
+	case *AirportListMessage:
+		for _, facility := range recv.Airports {
+			list = append(list, facility)
+		}
+		return SIMCONNECT_FACILITY_LIST_TYPE_AIRPORT, recv.FacilitiesListHeader, list, true
+	case *WaypointListMessage:
+		for _, facility := range recv.Waypoints {
+			list = append(list, facility)
+		}
+		return SIMCONNECT_FACILITY_LIST_TYPE_WAYPOINT, recv.FacilitiesListHeader, list, true
+	case *NDBListMessage:
+		for _, facility := range recv.NDBs {
+			list = append(list, facility)
+		}
+		return SIMCONNECT_FACILITY_LIST_TYPE_NDB, recv.FacilitiesListHeader, list, true
+	case *VORListMessage:
+		for _, facility := range recv.VORs {
+			list = append(list, facility)
+		}
+		return SIMCONNECT_FACILITY_LIST_TYPE_VOR, recv.FacilitiesListHeader, list, true
+	}
+	return 0, FacilitiesListHeader{}, nil, false
+}
+
+// keyOf return the key of the facility in the cache
+func keyOf(facility interface{}) facilityKey {
+	var airport FacilityAirport
+	switch facility := facility.(type) {
+	case FacilityAirport:
+		airport = facility
+	case FacilityWaypoint:
+		airport = facility.FacilityAirport
+	case FacilityNDB:
+		airport = facility.FacilityAirport
+	case FacilityVOR:
+		airport = facility.FacilityAirport
+	}
+	return facilityKey{airport.ICAO, airport.Latitude, airport.Longitude}
+}
+
+// sortFacilities sort the facilities by ICAO, the same ICAO by position
+func sortFacilities(list []interface{}) {
+	sort.Slice(list, func(i, j int) bool {
+		a, b := keyOf(list[i]), keyOf(list[j])
+		if a.icao != b.icao {
+			return a.icao < b.icao
+		}
+		if a.latitude != b.latitude {
+			return a.latitude < b.latitude
+		}
+		return a.longitude < b.longitude
+	})
+}
+
+func toAirports(list []interface{}) []FacilityAirport {
+	airports := make([]FacilityAirport, len(list))
+	for i, facility := range list {
+		airports[i] = facility.(FacilityAirport)
+	}
+	return airports
+}
+
+func toWaypoints(list []interface{}) []FacilityWaypoint {
+	waypoints := make([]FacilityWaypoint, len(list))
+	for i, facility := range list {
+		waypoints[i] = facility.(FacilityWaypoint)
+	}
+	return waypoints
+}
+
+func toNDBs(list []interface{}) []FacilityNDB {
+	ndbs := make([]FacilityNDB, len(list))
+	for i, facility := range list {
+		ndbs[i] = facility.(FacilityNDB)
+	}
+	return ndbs
+}
+
+func toVORs(list []interface{}) []FacilityVOR {
+	vors := make([]FacilityVOR, len(list))
+	for i, facility := range list {
+		vors[i] = facility.(FacilityVOR)
+	}
+	return vors
+}
@@ -0,0 +1,50 @@
+package simconnect
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFacilityChunks(t *testing.T) {
+	var c facilityChunks
+	header := func(entry, outOf uint32) FacilitiesListHeader {
+		return FacilitiesListHeader{EntryNumber: entry, OutOf: outOf}
+	}
+	if _, done := c.add(header(0, 3), []interface{}{"A", "B"}); done {
+		t.Error("done after 1 of 3")
+	}
+	// the messages can be in any order
+	if _, done := c.add(header(2, 3), []interface{}{"E"}); done {
+		t.Error("done after 2 of 3")
+	}
+	list, done := c.add(header(1, 3), []interface{}{"C", "D"})
+	if !done || !reflect.DeepEqual(list, []interface{}{"A", "B", "C", "D", "E"}) {
+		t.Errorf("list = %v, %v", list, done)
+	}
+
+	// the first message can come after the others
+	c.add(header(1, 3), []interface{}{"B"})
+	if _, done := c.add(header(0, 3), []interface{}{"A"}); done {
+		t.Error("done after 2 of 3")
+	}
+	if list, done := c.add(header(2, 3), []interface{}{"C"}); !done || !reflect.DeepEqual(list, []interface{}{"A", "B", "C"}) {
+		t.Errorf("list 1, 0, 2 = %v, %v", list, done)
+	}
+
+	// another OutOf restart the reassembly
+	c.add(header(0, 3), []interface{}{"A"})
+	if _, done := c.add(header(0, 2), []interface{}{"X"}); done {
+		t.Error("done after a restart")
+	}
+	if list, done := c.add(header(1, 2), []interface{}{"Y"}); !done || !reflect.DeepEqual(list, []interface{}{"X", "Y"}) {
+		t.Errorf("list = %v, %v", list, done)
+	}
+
+	// OutOf 0 is one message
+	if list, done := c.add(header(0, 0), nil); !done || len(list) != 0 {
+		t.Errorf("empty list = %v, %v", list, done)
+	}
+	if _, done := c.add(header(5, 2), []interface{}{"Z"}); done {
+		t.Error("done with an entry out of the list")
+	}
+}
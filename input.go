@@ -0,0 +1,519 @@
+package simconnect
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var inputModifiers = map[string]string{
+	"shift":   "Shift",
+	"ctrl":    "Ctrl",
+	"control": "Ctrl",
+	"alt":     "Alt",
+}
+
+var inputKeys = map[string]string{
+	"space":     "VK_SPACE",
+	"enter":     "VK_RETURN",
+	"return":    "VK_RETURN",
+	"esc":       "VK_ESCAPE",
+	"escape":    "VK_ESCAPE",
+	"tab":       "VK_TAB",
+	"backspace": "VK_BACK",
+	"delete":    "VK_DELETE",
+	"del":       "VK_DELETE",
+	"insert":    "VK_INSERT",
+	"ins":       "VK_INSERT",
+	"home":      "VK_HOME",
+	"end":       "VK_END",
+	"pageup":    "VK_PRIOR",
+	"pgup":      "VK_PRIOR",
+	"pagedown":  "VK_NEXT",
+	"pgdn":      "VK_NEXT",
+	"up":        "VK_UP",
+	"down":      "VK_DOWN",
+	"left":      "VK_LEFT",
+	"right":     "VK_RIGHT",
+}
+
+var joystickAxes = map[string]string{
+	"xaxis":  "XAxis",
+	"yaxis":  "YAxis",
+	"zaxis":  "ZAxis",
+	"rxaxis": "RxAxis",
+	"ryaxis": "RyAxis",
+	"rzaxis": "RzAxis",
+	"pov":    "POV",
+}
+
+// ParseInput check an input of the user and return it in the format of SimConnect_MapInputEventToClientEvent.
+// The input is a keyboard key with the modifiers (ex: "shift+ctrl+G", "alt+F5", "ctrl+space", "VK_NUMPAD8") or a joystick
+// button or axis (ex: "joystick:0:button:3", "joystick:1:XAxis", "joystick:0:POV"). The input is not case sensitive.
+func ParseInput(input string) (string, error) {
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return "", errors.New("input is empty")
+	}
+	if strings.HasPrefix(strings.ToLower(input), "joystick:") {
+		return parseJoystickInput(input)
+	}
+	parts := strings.Split(input, "+")
+	modifiers := make([]string, 0, len(parts)-1)
+	found := make(map[string]bool)
+	for _, part := range parts[:len(parts)-1] {
+		part = strings.TrimSpace(part)
+		modifier, ok := inputModifiers[strings.ToLower(part)]
+		if !ok && strings.HasPrefix(strings.ToUpper(part), "VK_") {
+			modifier, ok = strings.ToUpper(part), true
+		}
+		if !ok {
+			return "", fmt.Errorf("invalid input %q : unknown modifier %q", input, part)
+		}
+		if found[modifier] {
+			return "", fmt.Errorf("invalid input %q : modifier %s is repeated", input, modifier)
+		}
+		found[modifier] = true
+		modifiers = append(modifiers, modifier)
+	}
+	// the order of SimConnect for the modifiers is Shift, Ctrl, Alt
+	ordered := make([]string, 0, len(parts))
+	for _, modifier := range []string{"Shift", "Ctrl", "Alt"} {
+		if found[modifier] {
+			ordered = append(ordered, modifier)
+		}
+	}
+	for _, modifier := range modifiers {
+		if strings.HasPrefix(modifier, "VK_") {
+			ordered = append(ordered, modifier)
+		}
+	}
+	key, err := parseKey(strings.TrimSpace(parts[len(parts)-1]))
+	if err != nil {
+		return "", fmt.Errorf("invalid input %q : %v", input, err)
+	}
+	return strings.Join(append(ordered, key), "+"), nil
+}
+
+// parseKey return the name of the key for SimConnect
+func parseKey(key string) (string, error) {
+	lower := strings.ToLower(key)
+	switch {
+	case key == "":
+		return "", errors.New("key is empty")
+	case len(key) == 1 && strings.ContainsAny(strings.ToUpper(key), "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"):
+		return strings.ToUpper(key), nil
+	case inputKeys[lower] != "":
+		return inputKeys[lower], nil
+	case inputModifiers[lower] != "":
+		return "", fmt.Errorf("modifier %s without key", key)
+	case strings.HasPrefix(lower, "numpad"):
+		if n, err := strconv.Atoi(lower[6:]); err == nil && n >= 0 && n <= 9 {
+			return "VK_NUMPAD" + lower[6:], nil
+		}
+	case strings.HasPrefix(lower, "f"):
+		if n, err := strconv.Atoi(lower[1:]); err == nil && n >= 1 && n <= 24 {
+			return "VK_F" + lower[1:], nil
+		}
+	case strings.HasPrefix(lower, "vk_") && len(key) > 3:
+		return strings.ToUpper(key), nil
+	}
+	return "", fmt.Errorf("unknown key %q", key)
+}
+
+// parseJoystickInput return joystick:N:button:M, joystick:N:POV or joystick:N:XAxis...
+func parseJoystickInput(input string) (string, error) {
+	parts := strings.Split(input, ":")
+	if len(parts) < 3 {
+		return "", fmt.Errorf("invalid input %q : want joystick:N:button:M or joystick:N:axis", input)
+	}
+	joystick, err := strconv.ParseUint(parts[1], 10, 32)
+	if err != nil {
+		return "", fmt.Errorf("invalid input %q : bad joystick number %q", input, parts[1])
+	}
+	control := strings.ToLower(parts[2])
+	if control == "button" {
+		if len(parts) != 4 {
+			return "", fmt.Errorf("invalid input %q : want joystick:N:button:M", input)
+		}
+		button, err := strconv.ParseUint(parts[3], 10, 32)
+		if err != nil {
+			return "", fmt.Errorf("invalid input %q : bad button number %q", input, parts[3])
+		}
+		return fmt.Sprintf("joystick:%d:button:%d", joystick, button), nil
+	}
+	axis, found := joystickAxes[control]
+	if !found || len(parts) != 3 {
+		return "", fmt.Errorf("invalid input %q : unknown joystick control %q", input, parts[2])
+	}
+	return fmt.Sprintf("joystick:%d:%s", joystick, axis), nil
+}
+
+// InputEvent is an input of the user received by an InputGroup
+type InputEvent struct {
+	Input string // input returned by ParseInput
+	Down  bool   // false when the key or the button is released
+	Value int32  // position of a joystick axis
+}
+
+// InputGroup map the keys and the joystick buttons of the user to callbacks or chans. With a maskable input the
+// simulator and the groups with a lower priority don't receive the input.
+//
+//	inputs := esc.NewInputGroup(SIMCONNECT_GROUP_PRIORITY_HIGHEST)
+//	inputs.Bind("shift+ctrl+G", true, func(event InputEvent) {
+//		if event.Down {
+//			// your function
+//		}
+//	})
+//	defer inputs.Close()
+type InputGroup struct {
+	esc       *EasySimConnect
+	groupID   uint32
+	mutex     sync.Mutex
+	priority  GroupPriority
+	disabled  bool
+	closed    bool
+	listInput map[string]*inputBinding
+	restoreID uint32
+}
+
+// inputBinding is the client events of an input
+type inputBinding struct {
+	downEventID uint32
+	upEventID   uint32
+	maskable    bool
+	sub         *Subscription
+}
+
+// NewInputGroup create an enabled group of inputs with the priority (SIMCONNECT_GROUP_PRIORITY_*)
+func (esc *EasySimConnect) NewInputGroup(priority GroupPriority) *InputGroup {
+	esc.mutex.Lock()
+	esc.indexInputGroup++
+	groupID := esc.indexInputGroup
+	esc.mutex.Unlock()
+	g := &InputGroup{
+		esc:       esc,
+		groupID:   groupID,
+		priority:  priority,
+		listInput: make(map[string]*inputBinding),
+	}
+	g.restoreID = esc.addRestore(g.restore)
+	return g
+}
+
+// Bind call callback when the user press and release the input (see ParseInput). callback is called by the dispatch
+// of EasySimConnect and must not block.
+func (g *InputGroup) Bind(input string, maskable bool, callback func(event InputEvent)) error {
+	definition, err := ParseInput(input)
+	if err != nil {
+		return err
+	}
+	return g.bind(definition, maskable, callback, nil)
+}
+
+// BindChan is Bind with a chan, Unbind or the Subscription of the chan remove the input
+func (g *InputGroup) BindChan(input string, maskable bool) (<-chan InputEvent, error) {
+	definition, err := ParseInput(input)
+	if err != nil {
+		return nil, err
+	}
+	c := make(chan InputEvent)
+	bound := false
+	sub := g.esc.newSubscription(c, func() error {
+		// after an error of bind the input can be bound by another call
+		if !bound {
+			return nil
+		}
+		return g.unbind(definition)
+	})
+	err = g.bind(definition, maskable, func(event InputEvent) {
+		sub.send(event, 0)
+	}, sub)
+	if err != nil {
+		sub.Unsubscribe()
+		return nil, err
+	}
+	bound = true
+	return c, nil
+}
+
+func (g *InputGroup) bind(definition string, maskable bool, callback func(event InputEvent), sub *Subscription) error {
+	g.mutex.Lock()
+	if g.closed {
+		g.mutex.Unlock()
+		return errors.New("InputGroup is closed")
+	}
+	if _, found := g.listInput[definition]; found {
+		g.mutex.Unlock()
+		return fmt.Errorf("input %s is already bound", definition)
+	}
+	g.esc.mutex.Lock()
+	g.esc.indexEvent++
+	downEventID := g.esc.indexEvent
+	g.esc.indexEvent++
+	upEventID := g.esc.indexEvent
+	g.esc.listEvent[downEventID] = func(data interface{}) {
+		callback(InputEvent{definition, true, int32(data.(*EventMessage).Data)})
+	}
+	g.esc.listEvent[upEventID] = func(data interface{}) {
+		callback(InputEvent{definition, false, int32(data.(*EventMessage).Data)})
+	}
+	g.esc.mutex.Unlock()
+	binding := &inputBinding{downEventID, upEventID, maskable, sub}
+	g.listInput[definition] = binding
+	g.mutex.Unlock()
+	if err := g.mapInput(definition, binding); err != nil {
+		g.mutex.Lock()
+		delete(g.listInput, definition)
+		g.mutex.Unlock()
+		g.esc.mutex.Lock()
+		delete(g.esc.listEvent, downEventID)
+		delete(g.esc.listEvent, upEventID)
+		g.esc.mutex.Unlock()
+		return err
+	}
+	return g.apply()
+}
+
+// Unbind remove the input of the group, the chan of BindChan is closed
+func (g *InputGroup) Unbind(input string) error {
+	definition, err := ParseInput(input)
+	if err != nil {
+		return err
+	}
+	g.mutex.Lock()
+	binding, found := g.listInput[definition]
+	g.mutex.Unlock()
+	if !found {
+		return fmt.Errorf("input %s is not bound", definition)
+	}
+	if binding.sub != nil {
+		return binding.sub.Unsubscribe()
+	}
+	return g.unbind(definition)
+}
+
+// unbind remove the input in the simulator, do nothing if the input is already removed
+func (g *InputGroup) unbind(definition string) error {
+	g.mutex.Lock()
+	binding, found := g.listInput[definition]
+	delete(g.listInput, definition)
+	g.mutex.Unlock()
+	if !found {
+		return nil
+	}
+	g.esc.mutex.Lock()
+	delete(g.esc.listEvent, binding.downEventID)
+	delete(g.esc.listEvent, binding.upEventID)
+	g.esc.mutex.Unlock()
+	_, err := g.esc.call("RemoveInputEvent", definition, nil, func() (error, uint32) {
+		return g.esc.sc.RemoveInputEvent(g.groupID, definition)
+	})
+	return err
+}
+
+// Enable turn on the group, the callbacks are called again
+func (g *InputGroup) Enable() error {
+	return g.setDisabled(false)
+}
+
+// Disable turn off the group, the simulator receive the inputs normally
+func (g *InputGroup) Disable() error {
+	return g.setDisabled(true)
+}
+
+func (g *InputGroup) setDisabled(disabled bool) error {
+	g.mutex.Lock()
+	g.disabled = disabled
+	g.mutex.Unlock()
+	return g.apply()
+}
+
+// SetPriority change the priority of the group
+func (g *InputGroup) SetPriority(priority GroupPriority) error {
+	g.mutex.Lock()
+	g.priority = priority
+	g.mutex.Unlock()
+	return g.apply()
+}
+
+// Close remove all the inputs of the group in the simulator and close the chans of BindChan. Call Close more than once
+// do nothing.
+func (g *InputGroup) Close() error {
+	g.mutex.Lock()
+	if g.closed {
+		g.mutex.Unlock()
+		return nil
+	}
+	g.closed = true
+	listInput := g.listInput
+	g.listInput = make(map[string]*inputBinding)
+	g.mutex.Unlock()
+	g.esc.removeRestore(g.restoreID)
+	g.esc.mutex.Lock()
+	for _, binding := range listInput {
+		delete(g.esc.listEvent, binding.downEventID)
+		delete(g.esc.listEvent, binding.upEventID)
+	}
+	g.esc.mutex.Unlock()
+	for _, binding := range listInput {
+		if binding.sub != nil {
+			binding.sub.Unsubscribe()
+		}
+	}
+	if len(listInput) == 0 {
+		return nil
+	}
+	_, err := g.esc.call("ClearInputGroup", "", nil, func() (error, uint32) {
+		return g.esc.sc.ClearInputGroup(g.groupID)
+	})
+	return err
+}
+
+// mapInput map the input to the client events in the simulator
+func (g *InputGroup) mapInput(definition string, binding *inputBinding) error {
+	_, err := g.esc.call("MapInputEventToClientEvent", definition, nil, func() (error, uint32) {
+		return g.esc.sc.MapInputEventToClientEvent(g.groupID, definition, binding.downEventID, 0, binding.upEventID, 0, binding.maskable)
+	})
+	return err
+}
+
+// apply send the priority and the state of the group, the simulator know the group only after the first input
+func (g *InputGroup) apply() error {
+	g.mutex.Lock()
+	priority, disabled, empty := g.priority, g.disabled, len(g.listInput) == 0
+	g.mutex.Unlock()
+	if empty {
+		return nil
+	}
+	_, err := g.esc.call("SetInputGroupPriority", "", nil, func() (error, uint32) {
+		return g.esc.sc.SetInputGroupPriority(g.groupID, uint32(priority))
+	})
+	if err != nil {
+		return err
+	}
+	state := SIMCONNECT_STATE_ON
+	if disabled {
+		state = SIMCONNECT_STATE_OFF
+	}
+	_, err = g.esc.call("SetInputGroupState", "", nil, func() (error, uint32) {
+		return g.esc.sc.SetInputGroupState(g.groupID, state)
+	})
+	return err
+}
+
+// restore map again the inputs after a reconnection
+func (g *InputGroup) restore() {
+	g.mutex.Lock()
+	listInput := make(map[string]*inputBinding, len(g.listInput))
+	for definition, binding := range g.listInput {
+		listInput[definition] = binding
+	}
+	g.mutex.Unlock()
+	for definition, binding := range listInput {
+		g.mapInput(definition, binding)
+	}
+	g.apply()
+}
+
+// reservedKeyTimeout is the maximum time for waiting the key of ReserveKey
+const reservedKeyTimeout = 5 * time.Second
+
+// ReserveKey ask the simulator a key not used by the bindings of the user, the choices are tried in order (3 choices
+// maximum, ex: "q", "Ctrl+q", "Alt+q"). Return the key granted and a chan receiving an InputEvent each time the user
+// press the key. The key stay reserved until the end of the connection, the Subscription of the chan only stop the chan.
+// After a reconnection the key is reserved again, the Input of the InputEvent is the key granted by the new connection.
+//
+//	key, cKey, err := esc.ReserveKey("q", "Ctrl+q")
+//	log.Println("press", key)
+//	for range cKey {
+//		// your function
+//	}
+func (esc *EasySimConnect) ReserveKey(choices ...string) (string, <-chan InputEvent, error) {
+	if len(choices) == 0 || len(choices) > 3 {
+		return "", nil, errors.New("ReserveKey need 1 to 3 choices")
+	}
+	keys := make([]string, 3)
+	for i, choice := range choices {
+		if choice == "" || len(choice) >= 30 {
+			return "", nil, fmt.Errorf("invalid key choice %q", choice)
+		}
+		keys[i] = choice
+	}
+	esc.mutex.Lock()
+	esc.indexEvent++
+	eventID := esc.indexEvent
+	esc.mutex.Unlock()
+	// the response of the simulator don't have an ID, only one request is waiting at a time
+	reserve := func() (string, error) {
+		esc.reserveMutex.Lock()
+		defer esc.reserveMutex.Unlock()
+		select {
+		case <-esc.cReservedKey:
+		default:
+		}
+		cException := make(chan *SimConnectError, 1)
+		id, err := esc.call("RequestReservedKey", strings.Join(choices, ", "), cException, func() (error, uint32) {
+			return esc.sc.RequestReservedKey(eventID, keys[0], keys[1], keys[2])
+		})
+		if err != nil {
+			return "", err
+		}
+		defer esc.release(id)
+		var recv *ReservedKeyMessage
+		select {
+		case recv = <-esc.cReservedKey:
+		case exception := <-cException:
+			return "", exception
+		case <-time.After(reservedKeyTimeout):
+			return "", errors.New("no response of the simulator for ReserveKey")
+		case <-esc.ctx.Done():
+			return "", errors.New("EasySimConnect is closed")
+		}
+		if recv.ReservedKey == "" {
+			return "", fmt.Errorf("no key available in %s", strings.Join(choices, ", "))
+		}
+		return recv.ReservedKey, nil
+	}
+	key, err := reserve()
+	if err != nil {
+		return "", nil, err
+	}
+
+	// the key can change at the reconnection
+	var keyMutex sync.Mutex
+	c := make(chan InputEvent)
+	var restoreID uint32
+	sub := esc.newSubscription(c, func() error {
+		esc.removeRestore(restoreID)
+		esc.mutex.Lock()
+		delete(esc.listEvent, eventID)
+		esc.mutex.Unlock()
+		return nil
+	})
+	esc.mutex.Lock()
+	esc.listEvent[eventID] = func(data interface{}) {
+		keyMutex.Lock()
+		input := key
+		keyMutex.Unlock()
+		sub.send(InputEvent{input, true, int32(data.(*EventMessage).Data)}, 0)
+	}
+	esc.mutex.Unlock()
+	restoreID = esc.addRestore(func() {
+		// the restore is called by the dispatch, the response is read after
+		go func() {
+			restored, err := reserve()
+			if err != nil {
+				esc.logf(LogWarn, "Error reserve the key again : %v", err)
+				return
+			}
+			keyMutex.Lock()
+			key = restored
+			keyMutex.Unlock()
+		}()
+	})
+	return key, c, nil
+}
@@ -0,0 +1,36 @@
+package simconnect
+
+import "testing"
+
+func TestParseInput(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"shift+ctrl+G", "Shift+Ctrl+G"},
+		{"ctrl + shift + g", "Shift+Ctrl+G"},
+		{"Alt+control+1", "Ctrl+Alt+1"},
+		{"alt+F5", "Alt+VK_F5"},
+		{"ctrl+space", "Ctrl+VK_SPACE"},
+		{"numpad8", "VK_NUMPAD8"},
+		{"vk_lcontrol+a", "VK_LCONTROL+A"},
+		{"joystick:0:button:3", "joystick:0:button:3"},
+		{"Joystick:1:xaxis", "joystick:1:XAxis"},
+		{"joystick:0:pov", "joystick:0:POV"},
+	}
+	for _, test := range tests {
+		got, err := ParseInput(test.input)
+		if err != nil {
+			t.Errorf("%q: %v", test.input, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("%q = %q, want %q", test.input, got, test.want)
+		}
+	}
+	for _, input := range []string{"", "shift+", "ctrl+shift", "ctrl+ctrl+A", "hyper+A", "F25", "numpad10", "foo", "joystick:a:button:1", "joystick:0:button", "joystick:0:button:x", "joystick:0:wheel"} {
+		if got, err := ParseInput(input); err == nil {
+			t.Errorf("%q = %q, want error", input, got)
+		}
+	}
+}
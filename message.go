@@ -0,0 +1,551 @@
+package simconnect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+)
+
+// Message is a message received from the simulator decoded by DecodeMessage.
+// The type is a pointer on one of the *Message types of this file.
+type Message interface {
+	Header() RecvHeader
+}
+
+// RecvHeader is SIMCONNECT_RECV, the start of all the messages
+type RecvHeader struct {
+	Size    uint32 // record size
+	Version uint32 // interface version
+	ID      uint32 // see SIMCONNECT_RECV_ID
+}
+
+// Header return the header of the message
+func (h RecvHeader) Header() RecvHeader {
+	return h
+}
+
+// UnknownMessage is a message with an ID unknown by DecodeMessage, Data contain all the message
+type UnknownMessage struct {
+	RecvHeader
+	Data []byte
+}
+
+// NullMessage SIMCONNECT_RECV_ID_NULL
+type NullMessage struct {
+	RecvHeader
+}
+
+// ExceptionMessage SIMCONNECT_RECV_EXCEPTION
+type ExceptionMessage struct {
+	RecvHeader
+	Exception uint32 // see SIMCONNECT_EXCEPTION
+	SendID    uint32 // see SimConnect_GetLastSentPacketID
+	Index     uint32 // index of parameter that was source of error
+}
+
+// OpenMessage SIMCONNECT_RECV_OPEN
+type OpenMessage struct {
+	RecvHeader
+	ApplicationName         string
+	ApplicationVersionMajor uint32
+	ApplicationVersionMinor uint32
+	ApplicationBuildMajor   uint32
+	ApplicationBuildMinor   uint32
+	SimConnectVersionMajor  uint32
+	SimConnectVersionMinor  uint32
+	SimConnectBuildMajor    uint32
+	SimConnectBuildMinor    uint32
+}
+
+// QuitMessage SIMCONNECT_RECV_QUIT
+type QuitMessage struct {
+	RecvHeader
+}
+
+// EventMessage SIMCONNECT_RECV_EVENT
+type EventMessage struct {
+	RecvHeader
+	GroupID uint32
+	EventID uint32
+	Data    uint32 // EventID-dependent context
+}
+
+// eventMessage is implemented by all the messages starting with SIMCONNECT_RECV_EVENT
+type eventMessage interface {
+	Message
+	eventMessage() *EventMessage
+}
+
+func (e *EventMessage) eventMessage() *EventMessage {
+	return e
+}
+
+// EventObjectAddRemoveMessage SIMCONNECT_RECV_EVENT_OBJECT_ADDREMOVE
+type EventObjectAddRemoveMessage struct {
+	EventMessage
+	ObjectType uint32 // see SIMCONNECT_SIMOBJECT_TYPE
+}
+
+// EventFilenameMessage SIMCONNECT_RECV_EVENT_FILENAME
+type EventFilenameMessage struct {
+	EventMessage
+	Filename string
+	Flags    uint32
+}
+
+// EventFrameMessage SIMCONNECT_RECV_EVENT_FRAME
+type EventFrameMessage struct {
+	EventMessage
+	FrameRate float32
+	SimSpeed  float32
+}
+
+// EventMultiplayerServerStartedMessage SIMCONNECT_RECV_EVENT_MULTIPLAYER_SERVER_STARTED
+type EventMultiplayerServerStartedMessage struct {
+	EventMessage
+}
+
+// EventMultiplayerClientStartedMessage SIMCONNECT_RECV_EVENT_MULTIPLAYER_CLIENT_STARTED
+type EventMultiplayerClientStartedMessage struct {
+	EventMessage
+}
+
+// EventMultiplayerSessionEndedMessage SIMCONNECT_RECV_EVENT_MULTIPLAYER_SESSION_ENDED
+type EventMultiplayerSessionEndedMessage struct {
+	EventMessage
+}
+
+// EventWeatherModeMessage SIMCONNECT_RECV_EVENT_WEATHER_MODE, Data is the new SIMCONNECT_WEATHER_MODE
+type EventWeatherModeMessage struct {
+	EventMessage
+}
+
+// RaceResult SIMCONNECT_DATA_RACE_RESULT
+type RaceResult struct {
+	NumberOfRacers uint32 // The total number of racers
+	MissionGUID    GUID   // The name of the mission to execute
+	PlayerName     string // The name of the player
+	SessionType    string // The type of the multiplayer session: "LAN", "GAMESPY")
+	Aircraft       string // The aircraft type
+	PlayerRole     string // The player role in the mission
+	TotalTime      float64
+	PenaltyTime    float64
+	IsDisqualified bool
+}
+
+// EventRaceEndMessage SIMCONNECT_RECV_EVENT_RACE_END
+type EventRaceEndMessage struct {
+	EventMessage
+	RacerNumber uint32 // The index of the racer the results are for
+	RacerData   RaceResult
+}
+
+// EventRaceLapMessage SIMCONNECT_RECV_EVENT_RACE_LAP
+type EventRaceLapMessage struct {
+	EventMessage
+	LapNumber uint32 // The index of the lap the results are for
+	RacerData RaceResult
+}
+
+// SimObjectDataMessage SIMCONNECT_RECV_SIMOBJECT_DATA
+type SimObjectDataMessage struct {
+	RecvHeader
+	RequestID   uint32
+	ObjectID    uint32
+	DefineID    uint32
+	Flags       uint32 // SIMCONNECT_DATA_REQUEST_FLAG
+	EntryNumber uint32 // if multiple objects returned, this is number <entrynumber> out of <outof>.
+	OutOf       uint32 // note: starts with 1, not 0.
+	DefineCount uint32 // data count (number of datums, *not* byte count)
+	Data        []byte
+}
+
+// SimObjectDataByTypeMessage SIMCONNECT_RECV_SIMOBJECT_DATA_BYTYPE
+type SimObjectDataByTypeMessage struct {
+	SimObjectDataMessage
+}
+
+// ClientDataMessage SIMCONNECT_RECV_CLIENT_DATA
+type ClientDataMessage struct {
+	SimObjectDataMessage
+}
+
+// WeatherObservationMessage SIMCONNECT_RECV_WEATHER_OBSERVATION
+type WeatherObservationMessage struct {
+	RecvHeader
+	RequestID uint32
+	Metar     string
+}
+
+// CloudStateMessage SIMCONNECT_RECV_CLOUD_STATE, Data is SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH x SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH bytes
+type CloudStateMessage struct {
+	RecvHeader
+	RequestID uint32
+	Data      []byte
+}
+
+// AssignedObjectIDMessage SIMCONNECT_RECV_ASSIGNED_OBJECT_ID
+type AssignedObjectIDMessage struct {
+	RecvHeader
+	RequestID uint32
+	ObjectID  uint32
+}
+
+// ReservedKeyMessage SIMCONNECT_RECV_RESERVED_KEY
+type ReservedKeyMessage struct {
+	RecvHeader
+	ChoiceReserved string
+	ReservedKey    string
+}
+
+// SystemStateMessage SIMCONNECT_RECV_SYSTEM_STATE
+type SystemStateMessage struct {
+	RecvHeader
+	RequestID uint32
+	Integer   uint32
+	Float     float32
+	String    string
+}
+
+// CustomActionMessage SIMCONNECT_RECV_CUSTOM_ACTION
+type CustomActionMessage struct {
+	EventMessage
+	InstanceID        GUID
+	WaitForCompletion uint32
+	PayLoad           string
+}
+
+// FacilitiesListHeader SIMCONNECT_RECV_FACILITIES_LIST, the start of the facilities list messages
+type FacilitiesListHeader struct {
+	RequestID   uint32
+	ArraySize   uint32
+	EntryNumber uint32 // when the array of items is too big for one send, which send this is (0..OutOf-1)
+	OutOf       uint32 // total number of transmissions the list is chopped into
+}
+
+// FacilityAirport SIMCONNECT_DATA_FACILITY_AIRPORT
+type FacilityAirport struct {
+	ICAO      string
+	Latitude  float64 // degrees
+	Longitude float64 // degrees
+	Altitude  float64 // meters
+}
+
+// FacilityWaypoint SIMCONNECT_DATA_FACILITY_WAYPOINT
+type FacilityWaypoint struct {
+	FacilityAirport
+	MagVar float32 // Magvar in degrees
+}
+
+// FacilityNDB SIMCONNECT_DATA_FACILITY_NDB
+type FacilityNDB struct {
+	FacilityWaypoint
+	Frequency uint32 // frequency in Hz
+}
+
+// FacilityVOR SIMCONNECT_DATA_FACILITY_VOR
+type FacilityVOR struct {
+	FacilityNDB
+	Flags           uint32  // SIMCONNECT_VOR_FLAGS
+	Localizer       float32 // Localizer in degrees
+	GlideLat        float64 // Glide Slope Location (deg, deg, meters)
+	GlideLon        float64
+	GlideAlt        float64
+	GlideSlopeAngle float32 // Glide Slope in degrees
+}
+
+// HasNavSignal return true if the VOR has a Nav signal
+func (v FacilityVOR) HasNavSignal() bool {
+	return v.Flags&SIMCONNECT_RECV_ID_VOR_LIST_HAS_NAV_SIGNAL != 0
+}
+
+// HasLocalizer return true if the VOR is a localizer, Localizer is the heading
+func (v FacilityVOR) HasLocalizer() bool {
+	return v.Flags&SIMCONNECT_RECV_ID_VOR_LIST_HAS_LOCALIZER != 0
+}
+
+// HasGlideSlope return true if the VOR has a glide slope, GlideLat, GlideLon, GlideAlt and GlideSlopeAngle are set
+func (v FacilityVOR) HasGlideSlope() bool {
+	return v.Flags&SIMCONNECT_RECV_ID_VOR_LIST_HAS_GLIDE_SLOPE != 0
+}
+
+// HasDME return true if the station has a DME
+func (v FacilityVOR) HasDME() bool {
+	return v.Flags&SIMCONNECT_RECV_ID_VOR_LIST_HAS_DME != 0
+}
+
+// AirportListMessage SIMCONNECT_RECV_AIRPORT_LIST
+type AirportListMessage struct {
+	RecvHeader
+	FacilitiesListHeader
+	Airports []FacilityAirport
+}
+
+// WaypointListMessage SIMCONNECT_RECV_WAYPOINT_LIST
+type WaypointListMessage struct {
+	RecvHeader
+	FacilitiesListHeader
+	Waypoints []FacilityWaypoint
+}
+
+// NDBListMessage SIMCONNECT_RECV_NDB_LIST
+type NDBListMessage struct {
+	RecvHeader
+	FacilitiesListHeader
+	NDBs []FacilityNDB
+}
+
+// VORListMessage SIMCONNECT_RECV_VOR_LIST
+type VORListMessage struct {
+	RecvHeader
+	FacilitiesListHeader
+	VORs []FacilityVOR
+}
+
+// Size of the records in the buffer, the structures of SimConnect.h are packed on 1 byte
+const (
+	recvHeaderSize       = 12
+	facilityAirportSize  = 9 + 8 + 8 + 8
+	facilityWaypointSize = facilityAirportSize + 4
+	facilityNDBSize      = facilityWaypointSize + 4
+	facilityVORSize      = facilityNDBSize + 4 + 4 + 8 + 8 + 8 + 4
+)
+
+// recvReader read the fields of a record in little endian, after an out of range the values are 0 and err is set
+type recvReader struct {
+	buf []byte
+	pos int
+	err error
+}
+
+func (r *recvReader) next(size int) []byte {
+	if r.err != nil || size < 0 || r.pos+size > len(r.buf) {
+		if r.err == nil {
+			r.err = fmt.Errorf("Message too short: %d bytes, need %d", len(r.buf), r.pos+size)
+		}
+		return make([]byte, 8)
+	}
+	b := r.buf[r.pos : r.pos+size]
+	r.pos += size
+	return b
+}
+
+func (r *recvReader) uint32() uint32 {
+	return binary.LittleEndian.Uint32(r.next(4))
+}
+
+func (r *recvReader) float32() float32 {
+	return math.Float32frombits(r.uint32())
+}
+
+func (r *recvReader) float64() float64 {
+	return math.Float64frombits(binary.LittleEndian.Uint64(r.next(8)))
+}
+
+func (r *recvReader) string(size int) string {
+	return cString(r.next(size))
+}
+
+// cString return the string before the first 0
+func cString(b []byte) string {
+	if i := bytes.IndexByte(b, 0); i >= 0 {
+		b = b[:i]
+	}
+	return string(b)
+}
+
+func (r *recvReader) bytes(size int) []byte {
+	b := r.next(size)
+	if r.err != nil {
+		return nil
+	}
+	return append([]byte{}, b...)
+}
+
+// rest return a copy of the bytes not read
+func (r *recvReader) rest() []byte {
+	if r.err != nil {
+		return nil
+	}
+	return r.bytes(len(r.buf) - r.pos)
+}
+
+func (r *recvReader) guid() GUID {
+	var g GUID
+	g.Data1 = uint64(r.uint32())
+	b := r.next(4)
+	g.Data2 = binary.LittleEndian.Uint16(b)
+	g.Data3 = binary.LittleEndian.Uint16(b[2:])
+	copy(g.Data4[:], r.next(8))
+	return g
+}
+
+func (r *recvReader) header() RecvHeader {
+	return RecvHeader{r.uint32(), r.uint32(), r.uint32()}
+}
+
+func (r *recvReader) event(h RecvHeader) EventMessage {
+	return EventMessage{h, r.uint32(), r.uint32(), r.uint32()}
+}
+
+func (r *recvReader) simObjectData(h RecvHeader) SimObjectDataMessage {
+	m := SimObjectDataMessage{
+		RecvHeader:  h,
+		RequestID:   r.uint32(),
+		ObjectID:    r.uint32(),
+		DefineID:    r.uint32(),
+		Flags:       r.uint32(),
+		EntryNumber: r.uint32(),
+		OutOf:       r.uint32(),
+		DefineCount: r.uint32(),
+	}
+	m.Data = r.rest()
+	return m
+}
+
+func (r *recvReader) raceResult() RaceResult {
+	return RaceResult{
+		NumberOfRacers: r.uint32(),
+		MissionGUID:    r.guid(),
+		PlayerName:     r.string(MAX_PATH),
+		SessionType:    r.string(MAX_PATH),
+		Aircraft:       r.string(MAX_PATH),
+		PlayerRole:     r.string(MAX_PATH),
+		TotalTime:      r.float64(),
+		PenaltyTime:    r.float64(),
+		IsDisqualified: r.uint32() != 0,
+	}
+}
+
+func (r *recvReader) facilities(size int) FacilitiesListHeader {
+	f := FacilitiesListHeader{r.uint32(), r.uint32(), r.uint32(), r.uint32()}
+	if r.err == nil && uint64(f.ArraySize)*uint64(size) > uint64(len(r.buf)-r.pos) {
+		r.err = fmt.Errorf("Message too short: %d bytes for %d facilities", len(r.buf), f.ArraySize)
+	}
+	return f
+}
+
+func (r *recvReader) airport() FacilityAirport {
+	return FacilityAirport{r.string(9), r.float64(), r.float64(), r.float64()}
+}
+
+func (r *recvReader) waypoint() FacilityWaypoint {
+	return FacilityWaypoint{r.airport(), r.float32()}
+}
+
+func (r *recvReader) ndb() FacilityNDB {
+	return FacilityNDB{r.waypoint(), r.uint32()}
+}
+
+func (r *recvReader) vor() FacilityVOR {
+	return FacilityVOR{r.ndb(), r.uint32(), r.float32(), r.float64(), r.float64(), r.float64(), r.float32()}
+}
+
+// DecodeMessage decode a buffer returned by GetNextDispatch. An error is returned if the buffer is shorter than
+// the message. The messages with an unknown ID are returned in an UnknownMessage.
+func DecodeMessage(buf []byte) (Message, error) {
+	r := &recvReader{buf: buf}
+	h := r.header()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if int(h.Size) > len(buf) {
+		return nil, fmt.Errorf("Message too short: %d bytes, dwSize is %d", len(buf), h.Size)
+	}
+	if h.Size >= recvHeaderSize {
+		r.buf = buf[:h.Size]
+	}
+	var m Message
+	switch h.ID {
+	case SIMCONNECT_RECV_ID_NULL:
+		m = &NullMessage{h}
+	case SIMCONNECT_RECV_ID_EXCEPTION:
+		m = &ExceptionMessage{h, r.uint32(), r.uint32(), r.uint32()}
+	case SIMCONNECT_RECV_ID_OPEN:
+		open := &OpenMessage{RecvHeader: h, ApplicationName: r.string(256)}
+		open.ApplicationVersionMajor = r.uint32()
+		open.ApplicationVersionMinor = r.uint32()
+		open.ApplicationBuildMajor = r.uint32()
+		open.ApplicationBuildMinor = r.uint32()
+		open.SimConnectVersionMajor = r.uint32()
+		open.SimConnectVersionMinor = r.uint32()
+		open.SimConnectBuildMajor = r.uint32()
+		open.SimConnectBuildMinor = r.uint32()
+		r.next(8) // reserved
+		m = open
+	case SIMCONNECT_RECV_ID_QUIT:
+		m = &QuitMessage{h}
+	case SIMCONNECT_RECV_ID_EVENT:
+		event := r.event(h)
+		m = &event
+	case SIMCONNECT_RECV_ID_EVENT_OBJECT_ADDREMOVE:
+		m = &EventObjectAddRemoveMessage{r.event(h), r.uint32()}
+	case SIMCONNECT_RECV_ID_EVENT_FILENAME:
+		m = &EventFilenameMessage{r.event(h), r.string(MAX_PATH), r.uint32()}
+	case SIMCONNECT_RECV_ID_EVENT_FRAME:
+		m = &EventFrameMessage{r.event(h), r.float32(), r.float32()}
+	case SIMCONNECT_RECV_ID_SIMOBJECT_DATA:
+		data := r.simObjectData(h)
+		m = &data
+	case SIMCONNECT_RECV_ID_SIMOBJECT_DATA_BYTYPE:
+		m = &SimObjectDataByTypeMessage{r.simObjectData(h)}
+	case SIMCONNECT_RECV_ID_WEATHER_OBSERVATION:
+		m = &WeatherObservationMessage{h, r.uint32(), cString(r.rest())}
+	case SIMCONNECT_RECV_ID_CLOUD_STATE:
+		requestID := r.uint32()
+		m = &CloudStateMessage{h, requestID, r.bytes(int(r.uint32()))}
+	case SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID:
+		m = &AssignedObjectIDMessage{h, r.uint32(), r.uint32()}
+	case SIMCONNECT_RECV_ID_RESERVED_KEY:
+		m = &ReservedKeyMessage{h, r.string(30), r.string(50)}
+	case SIMCONNECT_RECV_ID_CUSTOM_ACTION:
+		m = &CustomActionMessage{r.event(h), r.guid(), r.uint32(), cString(r.rest())}
+	case SIMCONNECT_RECV_ID_SYSTEM_STATE:
+		m = &SystemStateMessage{h, r.uint32(), r.uint32(), r.float32(), r.string(MAX_PATH)}
+	case SIMCONNECT_RECV_ID_CLIENT_DATA:
+		m = &ClientDataMessage{r.simObjectData(h)}
+	case SIMCONNECT_RECV_ID_EVENT_WEATHER_MODE:
+		m = &EventWeatherModeMessage{r.event(h)}
+	case SIMCONNECT_RECV_ID_AIRPORT_LIST:
+		list := &AirportListMessage{RecvHeader: h, FacilitiesListHeader: r.facilities(facilityAirportSize)}
+		for i := uint32(0); r.err == nil && i < list.ArraySize; i++ {
+			list.Airports = append(list.Airports, r.airport())
+		}
+		m = list
+	case SIMCONNECT_RECV_ID_VOR_LIST:
+		list := &VORListMessage{RecvHeader: h, FacilitiesListHeader: r.facilities(facilityVORSize)}
+		for i := uint32(0); r.err == nil && i < list.ArraySize; i++ {
+			list.VORs = append(list.VORs, r.vor())
+		}
+		m = list
+	case SIMCONNECT_RECV_ID_NDB_LIST:
+		list := &NDBListMessage{RecvHeader: h, FacilitiesListHeader: r.facilities(facilityNDBSize)}
+		for i := uint32(0); r.err == nil && i < list.ArraySize; i++ {
+			list.NDBs = append(list.NDBs, r.ndb())
+		}
+		m = list
+	case SIMCONNECT_RECV_ID_WAYPOINT_LIST:
+		list := &WaypointListMessage{RecvHeader: h, FacilitiesListHeader: r.facilities(facilityWaypointSize)}
+		for i := uint32(0); r.err == nil && i < list.ArraySize; i++ {
+			list.Waypoints = append(list.Waypoints, r.waypoint())
+		}
+		m = list
+	case SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_SERVER_STARTED:
+		m = &EventMultiplayerServerStartedMessage{r.event(h)}
+	case SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_CLIENT_STARTED:
+		m = &EventMultiplayerClientStartedMessage{r.event(h)}
+	case SIMCONNECT_RECV_ID_EVENT_MULTIPLAYER_SESSION_ENDED:
+		m = &EventMultiplayerSessionEndedMessage{r.event(h)}
+	case SIMCONNECT_RECV_ID_EVENT_RACE_END:
+		m = &EventRaceEndMessage{r.event(h), r.uint32(), r.raceResult()}
+	case SIMCONNECT_RECV_ID_EVENT_RACE_LAP:
+		m = &EventRaceLapMessage{r.event(h), r.uint32(), r.raceResult()}
+	default:
+		m = &UnknownMessage{h, append([]byte{}, buf...)}
+	}
+	if r.err != nil {
+		return nil, fmt.Errorf("Invalid message ID %d: %v", h.ID, r.err)
+	}
+	return m, nil
+}
@@ -0,0 +1,136 @@
+package simconnect
+
+import (
+	"encoding/binary"
+	"io/ioutil"
+	"reflect"
+	"testing"
+)
+
+// recv return a record with the header and the size
+func recv(id uint32, body *packetWriter) []byte {
+	buf := new(packetWriter).putUint32(0).putUint32(4).putUint32(id).putBytes(body.Bytes()).Bytes()
+	binary.LittleEndian.PutUint32(buf, uint32(len(buf)))
+	return buf
+}
+
+func TestDecodeMessage(t *testing.T) {
+	event := func(eventID uint32, data uint32) *packetWriter {
+		return new(packetWriter).putUint32(1).putUint32(eventID).putUint32(data)
+	}
+	airport := new(packetWriter).putString("LFPG", 9).putFloat64(49.01).putFloat64(2.55).putFloat64(119)
+	tests := []struct {
+		name string
+		buf  []byte
+		want Message
+	}{
+		{
+			"exception",
+			recv(SIMCONNECT_RECV_ID_EXCEPTION, new(packetWriter).putUint32(SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED).putUint32(7).putUint32(2)),
+			&ExceptionMessage{RecvHeader{24, 4, SIMCONNECT_RECV_ID_EXCEPTION}, SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED, 7, 2},
+		},
+		{
+			"event frame",
+			recv(SIMCONNECT_RECV_ID_EVENT_FRAME, event(3, 0).putFloat32(30).putFloat32(1)),
+			&EventFrameMessage{EventMessage{RecvHeader{32, 4, SIMCONNECT_RECV_ID_EVENT_FRAME}, 1, 3, 0}, 30, 1},
+		},
+		{
+			"event filename",
+			recv(SIMCONNECT_RECV_ID_EVENT_FILENAME, event(5, 0).putString("flight.FLT", MAX_PATH).putUint32(0)),
+			&EventFilenameMessage{EventMessage{RecvHeader{288, 4, SIMCONNECT_RECV_ID_EVENT_FILENAME}, 1, 5, 0}, "flight.FLT", 0},
+		},
+		{
+			"simobject data",
+			recv(SIMCONNECT_RECV_ID_SIMOBJECT_DATA, new(packetWriter).putUint32(1).putUint32(2).putUint32(3).putUint32(0).putUint32(0).putUint32(0).putUint32(1).putFloat64(1543.5)),
+			&SimObjectDataMessage{RecvHeader{48, 4, SIMCONNECT_RECV_ID_SIMOBJECT_DATA}, 1, 2, 3, 0, 0, 0, 1, new(packetWriter).putFloat64(1543.5).Bytes()},
+		},
+		{
+			"weather observation",
+			recv(SIMCONNECT_RECV_ID_WEATHER_OBSERVATION, new(packetWriter).putUint32(9).putBytes([]byte("LFPG 121200Z 27010KT\x00"))),
+			&WeatherObservationMessage{RecvHeader{37, 4, SIMCONNECT_RECV_ID_WEATHER_OBSERVATION}, 9, "LFPG 121200Z 27010KT"},
+		},
+		{
+			"cloud state",
+			recv(SIMCONNECT_RECV_ID_CLOUD_STATE, new(packetWriter).putUint32(9).putUint32(3).putBytes([]byte{1, 2, 3})),
+			&CloudStateMessage{RecvHeader{23, 4, SIMCONNECT_RECV_ID_CLOUD_STATE}, 9, []byte{1, 2, 3}},
+		},
+		{
+			"reserved key",
+			recv(SIMCONNECT_RECV_ID_RESERVED_KEY, new(packetWriter).putString("q", 30).putString("Q", 50)),
+			&ReservedKeyMessage{RecvHeader{92, 4, SIMCONNECT_RECV_ID_RESERVED_KEY}, "q", "Q"},
+		},
+		{
+			"system state",
+			recv(SIMCONNECT_RECV_ID_SYSTEM_STATE, new(packetWriter).putUint32(4).putUint32(1).putFloat32(0).putString("Aircraft.cfg", MAX_PATH)),
+			&SystemStateMessage{RecvHeader{284, 4, SIMCONNECT_RECV_ID_SYSTEM_STATE}, 4, 1, 0, "Aircraft.cfg"},
+		},
+		{
+			"airport list",
+			recv(SIMCONNECT_RECV_ID_AIRPORT_LIST, new(packetWriter).putUint32(2).putUint32(1).putUint32(0).putUint32(1).putBytes(airport.Bytes())),
+			&AirportListMessage{
+				RecvHeader{61, 4, SIMCONNECT_RECV_ID_AIRPORT_LIST},
+				FacilitiesListHeader{2, 1, 0, 1},
+				[]FacilityAirport{{"LFPG", 49.01, 2.55, 119}},
+			},
+		},
+		{
+			"unknown",
+			recv(1000, new(packetWriter).putUint32(1)),
+			&UnknownMessage{RecvHeader{16, 4, 1000}, recv(1000, new(packetWriter).putUint32(1))},
+		},
+	}
+	// the end of these messages have a variable size, a shorter buffer is still valid
+	variable := map[string]bool{"simobject data": true, "weather observation": true, "unknown": true}
+	for _, test := range tests {
+		msg, err := DecodeMessage(test.buf)
+		if err != nil {
+			t.Errorf("%s: %v", test.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(msg, test.want) {
+			t.Errorf("%s: got %#v, want %#v", test.name, msg, test.want)
+		}
+		if variable[test.name] {
+			continue
+		}
+		// a truncated message must return an error
+		short := append([]byte{}, test.buf[:len(test.buf)-1]...)
+		binary.LittleEndian.PutUint32(short, uint32(len(short)))
+		if _, err := DecodeMessage(short); err == nil {
+			t.Errorf("%s: want error for truncated message", test.name)
+		}
+	}
+}
+
+func TestDecodeMessageCapturedOpen(t *testing.T) {
+	buf, err := ioutil.ReadFile("testdata/recv_open.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := DecodeMessage(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	open, ok := msg.(*OpenMessage)
+	if !ok {
+		t.Fatalf("got %T, want *OpenMessage", msg)
+	}
+	if open.ApplicationName != "KittyHawk" {
+		t.Errorf("ApplicationName = %q", open.ApplicationName)
+	}
+}
+
+func TestDecodeMessageInvalidSize(t *testing.T) {
+	if _, err := DecodeMessage([]byte{1, 2, 3}); err == nil {
+		t.Error("want error for a buffer shorter than the header")
+	}
+	buf := recv(SIMCONNECT_RECV_ID_QUIT, new(packetWriter))
+	binary.LittleEndian.PutUint32(buf, 100)
+	if _, err := DecodeMessage(buf); err == nil {
+		t.Error("want error when dwSize is bigger than the buffer")
+	}
+	buf = recv(SIMCONNECT_RECV_ID_CLOUD_STATE, new(packetWriter).putUint32(1).putUint32(0xFFFFFFFF))
+	if _, err := DecodeMessage(buf); err == nil {
+		t.Error("want error for a bad array size")
+	}
+}
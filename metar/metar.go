@@ -0,0 +1,310 @@
+// Package metar parse and build the METARs of the weather observations of SimConnect (WeatherObservationMessage and
+// WeatherSetObservation), with the extensions of FSX and P3D for the wind, visibility, cloud and temperature layers.
+//
+//	m, err := metar.Parse("KSEA 121200Z 18005KT 10SM BKN020 12/08 A3001")
+//	for i := range m.Clouds {
+//		m.Clouds[i].Base += 1000
+//	}
+//	weather.SetObservation(0, m.String())
+package metar
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Turbulence of a wind or a cloud layer
+type Turbulence byte
+
+// Turbulence of the extensions
+const (
+	TurbulenceNone       Turbulence = 'N'
+	TurbulenceOccasional Turbulence = 'O'
+	TurbulenceLight      Turbulence = 'L'
+	TurbulenceModerate   Turbulence = 'M'
+	TurbulenceHeavy      Turbulence = 'H'
+	TurbulenceSevere     Turbulence = 'S'
+)
+
+// Shear of a wind layer
+type Shear byte
+
+// Shear of the extensions
+const (
+	ShearGradual  Shear = 'G'
+	ShearModerate Shear = 'M'
+	ShearSteep    Shear = 'S'
+)
+
+// Metar is a weather observation, the zero values are not written by String
+type Metar struct {
+	Report       string // METAR or SPECI before the station, often empty
+	Station      string // ICAO of the station, GLOB for the global weather
+	Day          int
+	Hour         int
+	Minute       int
+	HasTime      bool
+	Modifier     string // AUTO or COR
+	Winds        []Wind // the surface wind then the winds aloft
+	CAVOK        bool
+	Visibilities []Visibility
+	RVR          []string // runway visual ranges (ex: R28L/2400FT)
+	Weather      []string // present weather (ex: -RA, +TSRA, BR)
+	Clouds       []Cloud
+	Temperatures []Temperature // the surface temperature then the temperatures aloft
+	Altimeter    *Altimeter
+	Other        []string // groups not understood, written after the altimeter (their position is not kept)
+	Trend        string   // the trend from NOSIG, TEMPO or BECMG to the remarks (ex: TEMPO 4000 RA)
+	Remarks      string   // the end of the METAR from RMK or @@@
+}
+
+// Wind is a surface wind or a wind aloft
+type Wind struct {
+	Direction    int  // degrees
+	Variable     bool // VRB
+	Speed        int
+	Gust         int
+	Unit         string // KT, MPS or KMH
+	HasVariable  bool   // variable direction (dddVddd) from VariableFrom to VariableTo
+	VariableFrom int
+	VariableTo   int
+	Layer        *WindLayer // FSX extension
+}
+
+// WindLayer is the FSX extension of a wind (&D980NG or &A3048NG)
+type WindLayer struct {
+	Aloft      bool // true for a wind aloft (&A), false for the surface wind (&D)
+	Height     int  // meters, the depth of the surface wind or the altitude of the wind aloft
+	Turbulence Turbulence
+	Shear      Shear
+}
+
+// Visibility is the horizontal visibility
+type Visibility struct {
+	Distance float64
+	Unit     string // "" for meters (9999), SM or KM
+	LessThan bool   // M1/4SM
+	Layer    *VisibilityLayer
+}
+
+// VisibilityLayer is the FSX extension of a visibility (&B-433&D3048)
+type VisibilityLayer struct {
+	Base  int // meters, can be negative
+	Depth int // meters
+}
+
+// Cloud is a cloud layer, Coverage is FEW, SCT, BKN, OVC or VV, or the octas of FSX ("1" to "8"). CLR, SKC, NSC and
+// NCD have no base.
+type Cloud struct {
+	Coverage string
+	Base     int    // feet, -1 for ///
+	Type     string // CB or TCU, the type of the cloud for the octas (ex: CU, CI)
+	Layer    *CloudLayer
+}
+
+// CloudLayer is the FSX extension of a cloud layer (&CU001FNMN000N)
+type CloudLayer struct {
+	Type              string // CI, CS, CC, AS, AC, SC, NS, ST, CU or CB
+	Top               int    // feet
+	Shape             byte   // F flat, R round, A anvil
+	Turbulence        Turbulence
+	PrecipitationRate byte // V very light, L light, M moderate, H heavy, D dense
+	PrecipitationType byte // N none, R rain, F freezing rain, H hail, S snow
+	PrecipitationBase int  // feet
+	Icing             byte // N none, T trace, L light, M moderate, S severe
+}
+
+// Temperature is the temperature and the dew point in Celsius
+type Temperature struct {
+	Temperature     int
+	DewPoint        int
+	DewPointMissing bool
+	Aloft           bool // FSX extension &A, the temperature at Altitude
+	Altitude        int  // meters
+}
+
+// Altimeter is the pressure, Unit is Q for hPa and A for inHg
+type Altimeter struct {
+	Unit  byte
+	Value float64
+}
+
+// HPa return the pressure in hectopascals
+func (a Altimeter) HPa() float64 {
+	if a.Unit == 'A' {
+		return a.Value * 33.8639
+	}
+	return a.Value
+}
+
+// String return the METAR
+func (m *Metar) String() string {
+	var groups []string
+	add := func(group string) {
+		if group != "" {
+			groups = append(groups, group)
+		}
+	}
+	add(m.Report)
+	add(m.Station)
+	if m.HasTime {
+		add(fmt.Sprintf("%02d%02d%02dZ", m.Day, m.Hour, m.Minute))
+	}
+	add(m.Modifier)
+	for _, w := range m.Winds {
+		add(w.String())
+		if w.HasVariable {
+			add(fmt.Sprintf("%03dV%03d", w.VariableFrom, w.VariableTo))
+		}
+	}
+	if m.CAVOK {
+		add("CAVOK")
+	}
+	for _, v := range m.Visibilities {
+		add(v.String())
+	}
+	groups = append(groups, m.RVR...)
+	groups = append(groups, m.Weather...)
+	for _, c := range m.Clouds {
+		add(c.String())
+	}
+	for _, t := range m.Temperatures {
+		add(t.String())
+	}
+	if m.Altimeter != nil {
+		add(m.Altimeter.String())
+	}
+	groups = append(groups, m.Other...)
+	add(m.Trend)
+	add(m.Remarks)
+	return strings.Join(groups, " ")
+}
+
+// String return the group of the wind
+func (w Wind) String() string {
+	direction := fmt.Sprintf("%03d", w.Direction)
+	if w.Variable {
+		direction = "VRB"
+	}
+	s := direction + fmt.Sprintf("%02d", w.Speed)
+	if w.Gust != 0 {
+		s += fmt.Sprintf("G%02d", w.Gust)
+	}
+	unit := w.Unit
+	if unit == "" {
+		unit = "KT"
+	}
+	s += unit
+	if w.Layer != nil {
+		layer := "D"
+		if w.Layer.Aloft {
+			layer = "A"
+		}
+		s += fmt.Sprintf("&%s%d%c%c", layer, w.Layer.Height, orDefault(byte(w.Layer.Turbulence), 'N'), orDefault(byte(w.Layer.Shear), 'G'))
+	}
+	return s
+}
+
+// String return the group of the visibility
+func (v Visibility) String() string {
+	var s string
+	switch v.Unit {
+	case "SM":
+		s = formatFraction(v.Distance) + "SM"
+	case "KM":
+		s = strconv.Itoa(int(math.Round(v.Distance))) + "KM"
+	default:
+		s = fmt.Sprintf("%04d", int(math.Round(v.Distance)))
+	}
+	if v.LessThan {
+		s = "M" + s
+	}
+	if v.Layer != nil {
+		s += fmt.Sprintf("&B%d&D%d", v.Layer.Base, v.Layer.Depth)
+	}
+	return s
+}
+
+// formatFraction write the statute miles like 10, 1/2 or 1 1/2
+func formatFraction(f float64) string {
+	whole := math.Floor(f)
+	frac := f - whole
+	for _, denominator := range []float64{2, 4, 8, 16} {
+		numerator := math.Round(frac * denominator)
+		if math.Abs(numerator/denominator-frac) > 1e-9 {
+			continue
+		}
+		switch {
+		case numerator == 0:
+			return strconv.Itoa(int(whole))
+		case whole == 0:
+			return fmt.Sprintf("%d/%d", int(numerator), int(denominator))
+		default:
+			return fmt.Sprintf("%d %d/%d", int(whole), int(numerator), int(denominator))
+		}
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
+
+// String return the group of the cloud layer, the extension is always complete
+func (c Cloud) String() string {
+	switch c.Coverage {
+	case "CLR", "SKC", "NSC", "NCD":
+		return c.Coverage
+	}
+	base := "///"
+	if c.Base >= 0 {
+		base = fmt.Sprintf("%03d", c.Base/100)
+	}
+	var s string
+	if isOctas(c.Coverage) {
+		s = c.Coverage + c.Type + base
+	} else {
+		s = c.Coverage + base + c.Type
+	}
+	if l := c.Layer; l != nil {
+		s += fmt.Sprintf("&%s%03d%c%c%c%c%03d%c", l.Type, l.Top/100, orDefault(l.Shape, 'F'), orDefault(byte(l.Turbulence), 'N'),
+			orDefault(l.PrecipitationRate, 'V'), orDefault(l.PrecipitationType, 'N'), l.PrecipitationBase/100, orDefault(l.Icing, 'N'))
+	}
+	return s
+}
+
+// String return the group of the temperature
+func (t Temperature) String() string {
+	s := formatTemperature(t.Temperature) + "/"
+	if !t.DewPointMissing {
+		s += formatTemperature(t.DewPoint)
+	}
+	if t.Aloft {
+		s += fmt.Sprintf("&A%d", t.Altitude)
+	}
+	return s
+}
+
+func formatTemperature(t int) string {
+	if t < 0 {
+		return fmt.Sprintf("M%02d", -t)
+	}
+	return fmt.Sprintf("%02d", t)
+}
+
+// String return the group of the altimeter
+func (a Altimeter) String() string {
+	if a.Unit == 'A' {
+		return fmt.Sprintf("A%04d", int(math.Round(a.Value*100)))
+	}
+	return fmt.Sprintf("Q%04d", int(math.Round(a.Value)))
+}
+
+func isOctas(coverage string) bool {
+	return len(coverage) == 1 && coverage[0] >= '1' && coverage[0] <= '8'
+}
+
+func orDefault(b byte, def byte) byte {
+	if b == 0 {
+		return def
+	}
+	return b
+}
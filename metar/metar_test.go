@@ -0,0 +1,116 @@
+package metar
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	m, err := Parse("KSEA 121200Z 18005G15KT 150V210 1 1/2SM R16L/2400FT -RA BR FEW008 BKN020CB OVC///  M02/M05 A3001 NOSIG RMK AO2 SLP165")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Metar{
+		Station: "KSEA",
+		Day:     12, Hour: 12, Minute: 0, HasTime: true,
+		Winds:        []Wind{{Direction: 180, Speed: 5, Gust: 15, Unit: "KT", HasVariable: true, VariableFrom: 150, VariableTo: 210}},
+		Visibilities: []Visibility{{Distance: 1.5, Unit: "SM"}},
+		RVR:          []string{"R16L/2400FT"},
+		Weather:      []string{"-RA", "BR"},
+		Clouds:       []Cloud{{Coverage: "FEW", Base: 800}, {Coverage: "BKN", Base: 2000, Type: "CB"}, {Coverage: "OVC", Base: -1}},
+		Temperatures: []Temperature{{Temperature: -2, DewPoint: -5}},
+		Altimeter:    &Altimeter{Unit: 'A', Value: 30.01},
+		Trend:        "NOSIG",
+		Remarks:      "RMK AO2 SLP165",
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("Parse =\n%#v\nwant\n%#v", m, want)
+	}
+	if hPa := m.Altimeter.HPa(); hPa < 1016 || hPa > 1017 {
+		t.Errorf("HPa = %f", hPa)
+	}
+}
+
+func TestParseExtensions(t *testing.T) {
+	m, err := Parse("GLOB 00000KT&D980NG 27020G25KT&A3048LM 100KM&B-433&D3048 5000&B2000&D500 3CU056&CU030FLMR020T 2CI269&CI001FNMN 15/05 M20/M25&A6000 Q1013 @@@ 30 15 270 20")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := &Metar{
+		Station: "GLOB",
+		Winds: []Wind{
+			{Unit: "KT", Layer: &WindLayer{Height: 980, Turbulence: TurbulenceNone, Shear: ShearGradual}},
+			{Direction: 270, Speed: 20, Gust: 25, Unit: "KT", Layer: &WindLayer{Aloft: true, Height: 3048, Turbulence: TurbulenceLight, Shear: ShearModerate}},
+		},
+		Visibilities: []Visibility{
+			{Distance: 100, Unit: "KM", Layer: &VisibilityLayer{Base: -433, Depth: 3048}},
+			{Distance: 5000, Layer: &VisibilityLayer{Base: 2000, Depth: 500}},
+		},
+		Clouds: []Cloud{
+			{Coverage: "3", Type: "CU", Base: 5600, Layer: &CloudLayer{Type: "CU", Top: 3000, Shape: 'F', Turbulence: TurbulenceLight,
+				PrecipitationRate: 'M', PrecipitationType: 'R', PrecipitationBase: 2000, Icing: 'T'}},
+			{Coverage: "2", Type: "CI", Base: 26900, Layer: &CloudLayer{Type: "CI", Top: 100, Shape: 'F', Turbulence: TurbulenceNone,
+				PrecipitationRate: 'M', PrecipitationType: 'N', Icing: 'N'}},
+		},
+		Temperatures: []Temperature{{Temperature: 15, DewPoint: 5}, {Temperature: -20, DewPoint: -25, Aloft: true, Altitude: 6000}},
+		Altimeter:    &Altimeter{Unit: 'Q', Value: 1013},
+		Remarks:      "@@@ 30 15 270 20",
+	}
+	if !reflect.DeepEqual(m, want) {
+		t.Errorf("Parse =\n%#v\nwant\n%#v", m, want)
+	}
+}
+
+func TestParseError(t *testing.T) {
+	for _, s := range []string{"", "   ", "METAR", "K 121200Z"} {
+		if _, err := Parse(s); err == nil {
+			t.Errorf("%q: want error", s)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, s := range []string{
+		"KSEA 121200Z AUTO 18005G15KT 150V210 1 1/2SM R16L/2400FT -RA BR FEW008 BKN020CB OVC/// M02/M05 A3001 NOSIG RMK AO2",
+		"METAR LFPG 121230Z VRB02KT CAVOK 22/12 Q1021",
+		"EGLL 121220Z 24015MPS M1/4SM +TSRA VV002 05/ Q0998",
+		"GLOB 00000KT&D980NG 27020G25KT&A3048LM 100KM&B-433&D3048 3CU056&CU030FLMR020T 15/05 M20/M25&A6000 Q1013 @@@ 30 15",
+		"XTST CLR 10/M01 A2992",
+		"LFPO 121200Z 35010KT 000V090 9999 FEW030 15/05 Q1018",
+		"LFPO 121200Z 35010KT 9999 FEW030 15/05 Q1018 TEMPO 4000 RA BECMG 25015KT",
+		"XTST 18005KT 9999 15/05 Q1018 XYZ TEMPO 4000 RMK AO2",
+	} {
+		m, err := Parse(s)
+		if err != nil {
+			t.Errorf("%q: %v", s, err)
+			continue
+		}
+		if got := m.String(); got != s {
+			t.Errorf("String =\n%q\nwant\n%q", got, s)
+		}
+	}
+	// the groups of the trend are not the observation
+	m, _ := Parse("LFPO 35010KT 9999 FEW030 Q1018 TEMPO 4000 RA")
+	if len(m.Visibilities) != 1 || len(m.Weather) != 0 || m.Trend != "TEMPO 4000 RA" {
+		t.Errorf("Parse with trend = %#v", m)
+	}
+	// the extension of a cloud is written complete
+	m, _ = Parse("GLOB 2CI269&CI001FNMN")
+	if got := m.String(); got != "GLOB 2CI269&CI001FNMN000N" {
+		t.Errorf("String = %q", got)
+	}
+}
+
+func ExampleParse() {
+	m, err := Parse("KSEA 121200Z 18005KT 10SM BKN020 OVC045 12/08 A3001")
+	if err != nil {
+		panic(err)
+	}
+	// raise the cloud base by 1000 ft
+	for i := range m.Clouds {
+		m.Clouds[i].Base += 1000
+	}
+	fmt.Println(m)
+	// Output: KSEA 121200Z 18005KT 10SM BKN030 OVC055 12/08 A3001
+}
@@ -0,0 +1,229 @@
+package metar
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	reStation     = regexp.MustCompile(`^[A-Z0-9]{4}$`)
+	reTime        = regexp.MustCompile(`^(\d{2})(\d{2})(\d{2})Z$`)
+	reWind        = regexp.MustCompile(`^(VRB|\d{3})(\d{2,3})(?:G(\d{2,3}))?(KT|MPS|KMH)(?:&([DA])(\d+)([NOLMHS])([GMS]))?$`)
+	reVariable    = regexp.MustCompile(`^(\d{3})V(\d{3})$`)
+	reVisibility  = regexp.MustCompile(`^(M)?(?:(\d{4})|(\d+(?:/\d+)?)SM|(\d+)KM)(?:&B(-?\d+)&D(\d+))?$`)
+	reWhole       = regexp.MustCompile(`^\d$`)
+	reFraction    = regexp.MustCompile(`^\d/\d+SM(?:&B-?\d+&D\d+)?$`)
+	reRVR         = regexp.MustCompile(`^R\d{2}[LRC]?/`)
+	reWeather     = regexp.MustCompile(`^(?:\+|-|VC)?(?:MI|PR|BC|DR|BL|SH|TS|FZ)?(?:DZ|RA|SN|SG|IC|PL|GR|GS|UP|BR|FG|FU|VA|DU|SA|HZ|PY|PO|SQ|FC|SS|DS)*$`)
+	reCloud       = regexp.MustCompile(`^(FEW|SCT|BKN|OVC|VV)(\d{3}|///)(CB|TCU)?` + cloudLayer + `$`)
+	reCloudOctas  = regexp.MustCompile(`^([1-8])([A-Z]{2})(\d{3})` + cloudLayer + `$`)
+	reTemperature = regexp.MustCompile(`^(M?\d{2})/(M?\d{2})?(?:&A(\d+))?$`)
+	reAltimeter   = regexp.MustCompile(`^([QA])(\d{4})$`)
+)
+
+// cloudLayer is the FSX extension of a cloud, the precipitation base and the icing are optional
+const cloudLayer = `(?:&([A-Z]{2})(\d{3})([FRA])([NOLMHS])([VLMHD])([NRFHS])(\d{3})?([NTLMS])?)?`
+
+// Parse return the Metar of the string. The groups not understood are kept in Other, the trend and the remarks are
+// kept as they are.
+func Parse(s string) (*Metar, error) {
+	groups := strings.Fields(s)
+	if len(groups) == 0 {
+		return nil, errors.New("empty METAR")
+	}
+	m := &Metar{}
+	if groups[0] == "METAR" || groups[0] == "SPECI" {
+		m.Report = groups[0]
+		groups = groups[1:]
+	}
+	if len(groups) == 0 || !reStation.MatchString(groups[0]) {
+		return nil, fmt.Errorf("no station in METAR %q", s)
+	}
+	m.Station = groups[0]
+	groups = groups[1:]
+	if len(groups) > 0 {
+		if match := reTime.FindStringSubmatch(groups[0]); match != nil {
+			m.Day, m.Hour, m.Minute, m.HasTime = atoi(match[1]), atoi(match[2]), atoi(match[3]), true
+			groups = groups[1:]
+		}
+	}
+	for i, group := range groups {
+		if group == "RMK" || group == "@@@" {
+			m.Remarks = strings.Join(groups[i:], " ")
+			groups = groups[:i]
+			break
+		}
+	}
+	// the groups of the trend are not the observation
+	for i, group := range groups {
+		if group == "NOSIG" || group == "TEMPO" || group == "BECMG" {
+			m.Trend = strings.Join(groups[i:], " ")
+			groups = groups[:i]
+			break
+		}
+	}
+	for i := 0; i < len(groups); i++ {
+		group := groups[i]
+		// 1 1/2SM
+		if reWhole.MatchString(group) && i+1 < len(groups) && reFraction.MatchString(groups[i+1]) {
+			i++
+			group += " " + groups[i]
+		}
+		if !m.parseGroup(group) {
+			m.Other = append(m.Other, group)
+		}
+	}
+	return m, nil
+}
+
+// parseGroup add the group in the Metar, return false if the group is not understood
+func (m *Metar) parseGroup(group string) bool {
+	switch {
+	case group == "AUTO" || group == "COR":
+		m.Modifier = group
+	case group == "CAVOK":
+		m.CAVOK = true
+	case group == "CLR" || group == "SKC" || group == "NSC" || group == "NCD":
+		m.Clouds = append(m.Clouds, Cloud{Coverage: group})
+	case reWind.MatchString(group):
+		m.Winds = append(m.Winds, parseWind(reWind.FindStringSubmatch(group)))
+	case reVariable.MatchString(group) && len(m.Winds) > 0:
+		match := reVariable.FindStringSubmatch(group)
+		w := &m.Winds[len(m.Winds)-1]
+		w.HasVariable, w.VariableFrom, w.VariableTo = true, atoi(match[1]), atoi(match[2])
+	case strings.Contains(group, " ") || reVisibility.MatchString(group):
+		m.Visibilities = append(m.Visibilities, parseVisibility(group))
+	case reRVR.MatchString(group):
+		m.RVR = append(m.RVR, group)
+	case reCloud.MatchString(group):
+		match := reCloud.FindStringSubmatch(group)
+		base := -1
+		if match[2] != "///" {
+			base = atoi(match[2]) * 100
+		}
+		m.Clouds = append(m.Clouds, Cloud{Coverage: match[1], Base: base, Type: match[3], Layer: parseCloudLayer(match[4:])})
+	case reCloudOctas.MatchString(group):
+		match := reCloudOctas.FindStringSubmatch(group)
+		m.Clouds = append(m.Clouds, Cloud{Coverage: match[1], Type: match[2], Base: atoi(match[3]) * 100, Layer: parseCloudLayer(match[4:])})
+	case reTemperature.MatchString(group):
+		match := reTemperature.FindStringSubmatch(group)
+		t := Temperature{Temperature: parseTemperature(match[1]), DewPointMissing: match[2] == ""}
+		if match[2] != "" {
+			t.DewPoint = parseTemperature(match[2])
+		}
+		if match[3] != "" {
+			t.Aloft, t.Altitude = true, atoi(match[3])
+		}
+		m.Temperatures = append(m.Temperatures, t)
+	case reAltimeter.MatchString(group):
+		match := reAltimeter.FindStringSubmatch(group)
+		a := &Altimeter{Unit: match[1][0], Value: float64(atoi(match[2]))}
+		if a.Unit == 'A' {
+			a.Value /= 100
+		}
+		m.Altimeter = a
+	case group != "" && reWeather.MatchString(group):
+		m.Weather = append(m.Weather, group)
+	default:
+		return false
+	}
+	return true
+}
+
+func parseWind(match []string) Wind {
+	w := Wind{Speed: atoi(match[2]), Unit: match[4]}
+	if match[1] == "VRB" {
+		w.Variable = true
+	} else {
+		w.Direction = atoi(match[1])
+	}
+	if match[3] != "" {
+		w.Gust = atoi(match[3])
+	}
+	if match[5] != "" {
+		w.Layer = &WindLayer{
+			Aloft:      match[5] == "A",
+			Height:     atoi(match[6]),
+			Turbulence: Turbulence(match[7][0]),
+			Shear:      Shear(match[8][0]),
+		}
+	}
+	return w
+}
+
+// parseVisibility parse a group of reVisibility, the statute miles can be 1 1/2SM
+func parseVisibility(group string) Visibility {
+	var v Visibility
+	whole := 0
+	if i := strings.Index(group, " "); i >= 0 {
+		whole = atoi(group[:i])
+		group = group[i+1:]
+	}
+	match := reVisibility.FindStringSubmatch(group)
+	v.LessThan = match[1] == "M"
+	switch {
+	case match[2] != "":
+		v.Distance = float64(atoi(match[2]))
+	case match[3] != "":
+		v.Unit = "SM"
+		v.Distance = float64(whole) + parseFraction(match[3])
+	default:
+		v.Unit = "KM"
+		v.Distance = float64(atoi(match[4]))
+	}
+	if match[5] != "" {
+		v.Layer = &VisibilityLayer{Base: atoi(match[5]), Depth: atoi(match[6])}
+	}
+	return v
+}
+
+func parseFraction(s string) float64 {
+	i := strings.Index(s, "/")
+	if i < 0 {
+		return float64(atoi(s))
+	}
+	denominator := atoi(s[i+1:])
+	if denominator == 0 {
+		return 0
+	}
+	return float64(atoi(s[:i])) / float64(denominator)
+}
+
+// parseCloudLayer return the extension of the submatches of cloudLayer, nil without extension
+func parseCloudLayer(match []string) *CloudLayer {
+	if match[0] == "" {
+		return nil
+	}
+	l := &CloudLayer{
+		Type:              match[0],
+		Top:               atoi(match[1]) * 100,
+		Shape:             match[2][0],
+		Turbulence:        Turbulence(match[3][0]),
+		PrecipitationRate: match[4][0],
+		PrecipitationType: match[5][0],
+		Icing:             'N',
+	}
+	if match[6] != "" {
+		l.PrecipitationBase = atoi(match[6]) * 100
+	}
+	if match[7] != "" {
+		l.Icing = match[7][0]
+	}
+	return l
+}
+
+func parseTemperature(s string) int {
+	if strings.HasPrefix(s, "M") {
+		return -atoi(s[1:])
+	}
+	return atoi(s)
+}
+
+// atoi convert the digits matched by a regexp
+func atoi(s string) int {
+	i, _ := strconv.Atoi(s)
+	return i
+}
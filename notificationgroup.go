@@ -0,0 +1,275 @@
+package simconnect
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// simEventGroupID is the notification group of NewSimEvent, NewNotificationGroup use the next IDs
+const simEventGroupID = 0
+
+// GroupEvent is a SimEvent received by a NotificationGroup, Value is the data of the event (ex: the position of an axis)
+type GroupEvent struct {
+	Mapping KeySimEvent
+	Value   int32
+}
+
+// NotificationGroup receive the SimEvents done in the simulator by the user or by the other clients (ex: the gear lever).
+// The groups with a higher priority receive the events before the others. A maskable event in a group with a priority
+// between SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE and SIMCONNECT_GROUP_PRIORITY_LOWEST is swallowed, the simulator
+// and the groups with a lower priority don't receive it.
+//
+//	group, _ := esc.NewNotificationGroup(SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE)
+//	group.AddSimEvent(KeyGearToggle, true) // the aircraft don't move the gear
+//	for event := range group.Events() {
+//		// your gear logic
+//	}
+type NotificationGroup struct {
+	esc       *EasySimConnect
+	groupID   uint32
+	mutex     sync.Mutex
+	priority  GroupPriority
+	listEvent map[KeySimEvent]groupEvent
+	c         chan GroupEvent
+	sub       *Subscription
+	restoreID uint32
+}
+
+// groupEvent is the client event of a SimEvent in a NotificationGroup
+type groupEvent struct {
+	eventID  uint32
+	maskable bool
+}
+
+// NewNotificationGroup create a group with the priority (SIMCONNECT_GROUP_PRIORITY_*) receiving the SimEvents added with AddSimEvent
+func (esc *EasySimConnect) NewNotificationGroup(priority GroupPriority) (*NotificationGroup, error) {
+	esc.mutex.Lock()
+	esc.indexGroup++
+	groupID := esc.indexGroup
+	esc.mutex.Unlock()
+	g := &NotificationGroup{
+		esc:       esc,
+		groupID:   groupID,
+		priority:  priority,
+		listEvent: make(map[KeySimEvent]groupEvent),
+		c:         make(chan GroupEvent),
+	}
+	g.sub = esc.newSubscription(g.c, g.stop)
+	g.restoreID = esc.addRestore(g.restore)
+	if err := g.setPriority(priority); err != nil {
+		g.sub.Unsubscribe()
+		return nil, err
+	}
+	return g, nil
+}
+
+// Events return the chan of the SimEvents of the group, the chan is closed by Close
+func (g *NotificationGroup) Events() <-chan GroupEvent {
+	return g.c
+}
+
+// AddSimEvent add the SimEvent in the group. If maskable is true and the priority of the group allow the masking, the
+// simulator don't receive the event.
+func (g *NotificationGroup) AddSimEvent(event KeySimEvent, maskable bool) error {
+	select {
+	case <-g.sub.done:
+		return errors.New("NotificationGroup is closed")
+	default:
+	}
+	g.mutex.Lock()
+	if _, found := g.listEvent[event]; found {
+		g.mutex.Unlock()
+		return fmt.Errorf("SimEvent %s is already in the NotificationGroup", event)
+	}
+	g.esc.mutex.Lock()
+	g.esc.indexEvent++
+	eventID := g.esc.indexEvent
+	g.esc.listEvent[eventID] = func(data interface{}) {
+		recv := data.(*EventMessage)
+		g.sub.send(GroupEvent{event, int32(recv.Data)}, 0)
+	}
+	g.esc.mutex.Unlock()
+	e := groupEvent{eventID, maskable}
+	g.listEvent[event] = e
+	g.mutex.Unlock()
+	if err := g.mapEvent(event, e); err != nil {
+		g.forgetEvent(event, e)
+		return err
+	}
+	if err := g.addToGroup(event, e); err != nil {
+		g.forgetEvent(event, e)
+		g.esc.call("RemoveClientEvent", string(event), nil, func() (error, uint32) {
+			return g.esc.sc.RemoveClientEvent(g.groupID, e.eventID)
+		})
+		return err
+	}
+	return nil
+}
+
+// RemoveSimEvent remove the SimEvent of the group
+func (g *NotificationGroup) RemoveSimEvent(event KeySimEvent) error {
+	g.mutex.Lock()
+	e, found := g.listEvent[event]
+	if !found {
+		g.mutex.Unlock()
+		return fmt.Errorf("SimEvent %s is not in the NotificationGroup", event)
+	}
+	delete(g.listEvent, event)
+	g.mutex.Unlock()
+	g.esc.mutex.Lock()
+	delete(g.esc.listEvent, e.eventID)
+	g.esc.mutex.Unlock()
+	_, err := g.esc.call("RemoveClientEvent", string(event), nil, func() (error, uint32) {
+		return g.esc.sc.RemoveClientEvent(g.groupID, e.eventID)
+	})
+	return err
+}
+
+// SetPriority change the priority of the group
+func (g *NotificationGroup) SetPriority(priority GroupPriority) error {
+	g.mutex.Lock()
+	g.priority = priority
+	g.mutex.Unlock()
+	return g.setPriority(priority)
+}
+
+// Clear remove all the SimEvents of the group
+func (g *NotificationGroup) Clear() error {
+	g.removeEvents()
+	_, err := g.esc.call("ClearNotificationGroup", "", nil, func() (error, uint32) {
+		return g.esc.sc.ClearNotificationGroup(g.groupID)
+	})
+	return err
+}
+
+// Request ask the SimEvents of the group when the simulator is in a dialog mode
+func (g *NotificationGroup) Request() error {
+	_, err := g.esc.call("RequestNotificationGroup", "", nil, func() (error, uint32) {
+		return g.esc.sc.RequestNotificationGroup(g.groupID, 0, 0)
+	})
+	return err
+}
+
+// Close remove the group in the simulator and close the chan of Events. Call Close more than once do nothing.
+func (g *NotificationGroup) Close() error {
+	return g.sub.Unsubscribe()
+}
+
+func (g *NotificationGroup) setPriority(priority GroupPriority) error {
+	_, err := g.esc.call("SetNotificationGroupPriority", "", nil, func() (error, uint32) {
+		return g.esc.sc.SetNotificationGroupPriority(g.groupID, priority)
+	})
+	return err
+}
+
+// addEvent map the client event and add it in the group of the simulator
+func (g *NotificationGroup) addEvent(event KeySimEvent, e groupEvent) error {
+	if err := g.mapEvent(event, e); err != nil {
+		return err
+	}
+	return g.addToGroup(event, e)
+}
+
+// mapEvent map the client event to the SimEvent
+func (g *NotificationGroup) mapEvent(event KeySimEvent, e groupEvent) error {
+	_, err := g.esc.call("MapClientEventToSimEvent", string(event), nil, func() (error, uint32) {
+		return g.esc.sc.MapClientEventToSimEvent(e.eventID, string(event))
+	})
+	return err
+}
+
+// addToGroup add the mapped client event in the group of the simulator
+func (g *NotificationGroup) addToGroup(event KeySimEvent, e groupEvent) error {
+	_, err := g.esc.call("AddClientEventToNotificationGroup", string(event), nil, func() (error, uint32) {
+		return g.esc.sc.AddClientEventToNotificationGroup(g.groupID, e.eventID, e.maskable)
+	})
+	return err
+}
+
+// forgetEvent remove the client event of a failed AddSimEvent in the group and in EasySimConnect
+func (g *NotificationGroup) forgetEvent(event KeySimEvent, e groupEvent) {
+	g.mutex.Lock()
+	if current, found := g.listEvent[event]; found && current.eventID == e.eventID {
+		delete(g.listEvent, event)
+	}
+	g.mutex.Unlock()
+	g.esc.mutex.Lock()
+	delete(g.esc.listEvent, e.eventID)
+	g.esc.mutex.Unlock()
+}
+
+// removeEvents forget the SimEvents of the group in EasySimConnect
+func (g *NotificationGroup) removeEvents() {
+	g.mutex.Lock()
+	listEvent := g.listEvent
+	g.listEvent = make(map[KeySimEvent]groupEvent)
+	g.mutex.Unlock()
+	g.esc.mutex.Lock()
+	for _, e := range listEvent {
+		delete(g.esc.listEvent, e.eventID)
+	}
+	g.esc.mutex.Unlock()
+}
+
+// restore create again the group after a reconnection
+func (g *NotificationGroup) restore() {
+	g.mutex.Lock()
+	priority := g.priority
+	listEvent := make(map[KeySimEvent]groupEvent, len(g.listEvent))
+	for event, e := range g.listEvent {
+		listEvent[event] = e
+	}
+	g.mutex.Unlock()
+	g.setPriority(priority)
+	for event, e := range listEvent {
+		g.addEvent(event, e)
+	}
+}
+
+// stop is called by Unsubscribe
+func (g *NotificationGroup) stop() error {
+	g.esc.removeRestore(g.restoreID)
+	return g.Clear()
+}
+
+// KeyEvent is a SimEvent triggered in the simulator, returned by WatchSimEvents
+type KeyEvent struct {
+	Key   KeySimEvent
+	Value int32
+	Time  time.Time
+}
+
+// WatchSimEvents return a chan receiving the SimEvents triggered by the user, by the other clients or by this
+// EasySimConnect. The events are not masked, the simulator receive them normally. Use Subscription for stopping.
+//
+//	cKey, _ := esc.WatchSimEvents(KeyApMaster, KeyFlapsIncr, KeyFlapsDecr)
+//	for event := range cKey {
+//		log.Println(event.Time, event.Key, event.Value)
+//	}
+func (esc *EasySimConnect) WatchSimEvents(keys ...KeySimEvent) (<-chan KeyEvent, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("WatchSimEvents need a KeySimEvent")
+	}
+	group, err := esc.NewNotificationGroup(SIMCONNECT_GROUP_PRIORITY_HIGHEST)
+	if err != nil {
+		return nil, err
+	}
+	for _, key := range keys {
+		if err := group.AddSimEvent(key, false); err != nil {
+			group.Close()
+			return nil, err
+		}
+	}
+	c := make(chan KeyEvent)
+	sub := esc.newSubscription(c, group.Close)
+	go func() {
+		for event := range group.Events() {
+			if !sub.send(KeyEvent{event.Mapping, event.Value, time.Now()}, 0) {
+				return
+			}
+		}
+	}()
+	return c, nil
+}
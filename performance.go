@@ -0,0 +1,362 @@
+package simconnect
+
+import (
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxPendingPing is the number of pings without response kept, the older are lost
+const maxPendingPing = 8
+
+// PerformanceOptions configure a PerformanceMonitor, a zero value use the default
+type PerformanceOptions struct {
+	// Window is the duration of the frames and of the latencies used for the statistics (default 10s)
+	Window time.Duration
+	// Interval is the period of the latency measure and of the stats sent by Updates (default 1s)
+	Interval time.Duration
+	// StutterFactor detect a stutter when a frame is StutterFactor times longer than the average frame (default 2.5)
+	StutterFactor float32
+	// LowFPS flag the stats when the 5% low frame rate is lower (default 20)
+	LowFPS float32
+	// LatencySpike flag the stats when the SimConnect round trip is longer (default 250ms)
+	LatencySpike time.Duration
+}
+
+func (o PerformanceOptions) withDefault() PerformanceOptions {
+	if o.Window <= 0 {
+		o.Window = 10 * time.Second
+	}
+	if o.Interval <= 0 {
+		o.Interval = time.Second
+	}
+	if o.StutterFactor <= 0 {
+		o.StutterFactor = 2.5
+	}
+	if o.LowFPS <= 0 {
+		o.LowFPS = 20
+	}
+	if o.LatencySpike <= 0 {
+		o.LatencySpike = 250 * time.Millisecond
+	}
+	return o
+}
+
+// PerformanceStats is a snapshot of a PerformanceMonitor, the frame rates and the latencies are computed on the Window
+type PerformanceStats struct {
+	Time            time.Time
+	Frames          int     // number of frames in the window
+	FPS             float32 // frame rate of the last frame
+	FPSAverage      float32
+	FPSMedian       float32
+	FPSLow5         float32 // 5th percentile of the frame rate
+	FPSLow1         float32 // 1st percentile of the frame rate
+	FPSMin          float32
+	FPSMax          float32
+	Stutters        int     // stutters in the window
+	TotalStutters   int     // stutters since the start of the monitor
+	SimSpeed        float32 // simulation rate (ex: 0.5 for half speed)
+	SimSpeedChanges int     // changes of the simulation rate since the start of the monitor
+	Paused          bool
+	Latency         time.Duration // last SimConnect round trip
+	LatencyAverage  time.Duration
+	LatencyMax      time.Duration
+	ResponseTimes   []float32 // times in seconds of RequestResponseTimes, nil if the transport don't give them
+	LowFPS          bool      // FPSLow5 is lower than PerformanceOptions.LowFPS
+	LatencySpike    bool      // Latency is longer than PerformanceOptions.LatencySpike
+}
+
+type frameSample struct {
+	time    time.Time
+	fps     float32
+	stutter bool
+}
+
+type latencySample struct {
+	time    time.Time
+	latency time.Duration
+}
+
+// PerformanceMonitor keep the statistics of the frame rate, the simulation rate and the SimConnect latency.
+// The frames come from the system event Frame, the latency is the round trip of a RequestSystemState.
+//
+//	monitor := esc.NewPerformanceMonitor(PerformanceOptions{})
+//	for stats := range monitor.Updates() {
+//		if stats.LowFPS || stats.LatencySpike {
+//			esc.ShowText("Low performance", 2, SIMCONNECT_TEXT_TYPE_PRINT_RED)
+//		}
+//	}
+type PerformanceMonitor struct {
+	esc             *EasySimConnect
+	options         PerformanceOptions
+	mutex           sync.Mutex
+	frames          []frameSample
+	fpsSum          float64
+	totalStutters   int
+	simSpeed        float32
+	simSpeedChanges int
+	lastPause       time.Time
+	pings           map[uint32]time.Time // sent time by request ID
+	latencies       []latencySample
+	latency         time.Duration
+	responseTimes   []float32
+	cFrame          <-chan FrameEvent
+	cPause          <-chan FrameEvent
+	c               chan PerformanceStats
+	sub             *Subscription
+}
+
+// NewPerformanceMonitor start a PerformanceMonitor, Close stop it
+func (esc *EasySimConnect) NewPerformanceMonitor(options PerformanceOptions) *PerformanceMonitor {
+	m := &PerformanceMonitor{
+		esc:     esc,
+		options: options.withDefault(),
+		c:       make(chan PerformanceStats),
+		pings:   make(map[uint32]time.Time),
+	}
+	m.cFrame = esc.ConnectSysEventFrame()
+	m.cPause = esc.ConnectSysEventPauseFrame()
+	m.sub = esc.newSubscription(m.c, m.stop)
+	go m.runFrames()
+	go m.run()
+	return m
+}
+
+// Updates return a chan receiving the stats at each Interval, the chan is closed by Close
+func (m *PerformanceMonitor) Updates() <-chan PerformanceStats {
+	return m.c
+}
+
+// Close stop the monitor. Call Close more than once do nothing.
+func (m *PerformanceMonitor) Close() error {
+	return m.sub.Unsubscribe()
+}
+
+// Stats return the current statistics
+func (m *PerformanceMonitor) Stats() PerformanceStats {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	now := time.Now()
+	m.expire(now)
+	stats := PerformanceStats{
+		Time:            now,
+		Frames:          len(m.frames),
+		TotalStutters:   m.totalStutters,
+		SimSpeed:        m.simSpeed,
+		SimSpeedChanges: m.simSpeedChanges,
+		Paused:          now.Sub(m.lastPause) < m.options.Interval,
+		Latency:         m.latency,
+		LatencySpike:    m.latency > m.options.LatencySpike,
+	}
+	if m.responseTimes != nil {
+		stats.ResponseTimes = append([]float32{}, m.responseTimes...)
+	}
+	if len(m.frames) > 0 {
+		fps := make([]float32, len(m.frames))
+		for i, frame := range m.frames {
+			fps[i] = frame.fps
+			if frame.stutter {
+				stats.Stutters++
+			}
+		}
+		stats.FPS = fps[len(fps)-1]
+		stats.FPSAverage = float32(m.fpsSum / float64(len(fps)))
+		sort.Slice(fps, func(i, j int) bool { return fps[i] < fps[j] })
+		stats.FPSMin = fps[0]
+		stats.FPSMax = fps[len(fps)-1]
+		stats.FPSMedian = percentile(fps, 50)
+		stats.FPSLow5 = percentile(fps, 5)
+		stats.FPSLow1 = percentile(fps, 1)
+		stats.LowFPS = stats.FPSLow5 < m.options.LowFPS
+	}
+	if len(m.latencies) > 0 {
+		var sum time.Duration
+		for _, sample := range m.latencies {
+			sum += sample.latency
+			if sample.latency > stats.LatencyMax {
+				stats.LatencyMax = sample.latency
+			}
+		}
+		stats.LatencyAverage = sum / time.Duration(len(m.latencies))
+	}
+	return stats
+}
+
+// percentile return the nearest-rank percentile p of the sorted values
+func percentile(sorted []float32, p float64) float32 {
+	i := int(math.Ceil(p/100*float64(len(sorted)))) - 1
+	if i < 0 {
+		i = 0
+	}
+	return sorted[i]
+}
+
+func (m *PerformanceMonitor) runFrames() {
+	for {
+		select {
+		case frame, ok := <-m.cFrame:
+			if !ok {
+				return
+			}
+			m.addFrame(frame, time.Now())
+		case _, ok := <-m.cPause:
+			if !ok {
+				return
+			}
+			m.mutex.Lock()
+			m.lastPause = time.Now()
+			m.mutex.Unlock()
+		case <-m.sub.done:
+			return
+		case <-m.esc.ctx.Done():
+			return
+		}
+	}
+}
+
+// run measure the latency and send the stats at each Interval
+func (m *PerformanceMonitor) run() {
+	ticker := time.NewTicker(m.options.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-m.sub.done:
+			return
+		case <-m.esc.ctx.Done():
+			return
+		}
+		m.ping()
+		m.requestResponseTimes()
+		// a slow reader lose the stats of this interval
+		m.sub.send(m.Stats(), m.options.Interval)
+	}
+}
+
+func (m *PerformanceMonitor) addFrame(frame FrameEvent, now time.Time) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	m.expire(now)
+	if m.simSpeed != 0 && frame.SimSpeed != m.simSpeed {
+		m.simSpeedChanges++
+	}
+	m.simSpeed = frame.SimSpeed
+	// a few frames are needed for a good average
+	stutter := false
+	if len(m.frames) >= 10 {
+		average := m.fpsSum / float64(len(m.frames))
+		stutter = float64(frame.FrameRate*m.options.StutterFactor) < average
+	}
+	if stutter {
+		m.totalStutters++
+	}
+	m.frames = append(m.frames, frameSample{now, frame.FrameRate, stutter})
+	m.fpsSum += float64(frame.FrameRate)
+}
+
+// expire remove the samples older than the window, must be called with the lock
+func (m *PerformanceMonitor) expire(now time.Time) {
+	limit := now.Add(-m.options.Window)
+	i := 0
+	for i < len(m.frames) && m.frames[i].time.Before(limit) {
+		m.fpsSum -= float64(m.frames[i].fps)
+		i++
+	}
+	m.frames = m.frames[i:]
+	if len(m.frames) == 0 {
+		m.fpsSum = 0
+	}
+	i = 0
+	for i < len(m.latencies) && m.latencies[i].time.Before(limit) {
+		i++
+	}
+	m.latencies = m.latencies[i:]
+}
+
+// ping send a RequestSystemState, pong is called by the response
+func (m *PerformanceMonitor) ping() {
+	requestID := m.esc.newRequest(func(data interface{}) {
+		requestID := data.(*SystemStateMessage).RequestID
+		if m.pong(requestID, time.Now()) {
+			m.esc.removeRequest(requestID)
+		}
+	})
+	m.mutex.Lock()
+	dropped, found := m.addPing(requestID, time.Now())
+	m.mutex.Unlock()
+	if found {
+		m.esc.removeRequest(dropped)
+	}
+	_, err := m.esc.call("RequestSystemState", "Sim", nil, func() (error, uint32) {
+		return m.esc.sc.RequestSystemState(requestID, "Sim")
+	})
+	if err != nil {
+		m.mutex.Lock()
+		delete(m.pings, requestID)
+		m.mutex.Unlock()
+		m.esc.removeRequest(requestID)
+	}
+}
+
+// addPing keep the sent time of the ping and return the oldest ping dropped when there are more than maxPendingPing.
+// Must be called with the lock.
+func (m *PerformanceMonitor) addPing(requestID uint32, sent time.Time) (uint32, bool) {
+	var dropped uint32
+	found := false
+	if len(m.pings) >= maxPendingPing {
+		for id, t := range m.pings {
+			if !found || t.Before(m.pings[dropped]) {
+				dropped, found = id, true
+			}
+		}
+		delete(m.pings, dropped)
+	}
+	m.pings[requestID] = sent
+	return dropped, found
+}
+
+// pong measure the latency of the ping requestID, return false if the ping is unknown (dropped or already received)
+func (m *PerformanceMonitor) pong(requestID uint32, now time.Time) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	sent, found := m.pings[requestID]
+	if !found {
+		return false
+	}
+	delete(m.pings, requestID)
+	m.latency = now.Sub(sent)
+	m.latencies = append(m.latencies, latencySample{now, m.latency})
+	return true
+}
+
+// requestResponseTimes keep the times of SimConnect.dll, the other transports return an error
+func (m *PerformanceMonitor) requestResponseTimes() {
+	times := make([]float32, 5)
+	err, _ := m.esc.sc.RequestResponseTimes(uint32(len(times)), &times[0])
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	if err != nil {
+		m.responseTimes = nil
+		return
+	}
+	m.responseTimes = times
+}
+
+// stop is called by Unsubscribe
+func (m *PerformanceMonitor) stop() error {
+	m.mutex.Lock()
+	pings := m.pings
+	m.pings = make(map[uint32]time.Time)
+	m.mutex.Unlock()
+	for requestID := range pings {
+		m.esc.removeRequest(requestID)
+	}
+	if sub, err := m.esc.Subscription(m.cFrame); err == nil {
+		sub.Unsubscribe()
+	}
+	if sub, err := m.esc.Subscription(m.cPause); err == nil {
+		sub.Unsubscribe()
+	}
+	return nil
+}
@@ -0,0 +1,48 @@
+package simconnect
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPingPong(t *testing.T) {
+	m := &PerformanceMonitor{pings: make(map[uint32]time.Time)}
+	start := time.Now()
+	for i := 1; i <= maxPendingPing; i++ {
+		if _, found := m.addPing(uint32(i), start.Add(time.Duration(i)*time.Second)); found {
+			t.Fatalf("ping %d dropped a ping", i)
+		}
+	}
+	// the oldest ping is dropped
+	if dropped, found := m.addPing(maxPendingPing+1, start.Add((maxPendingPing+1)*time.Second)); !found || dropped != 1 {
+		t.Errorf("dropped = %d %v, want 1", dropped, found)
+	}
+	now := start.Add(20 * time.Second)
+	// the late response of the dropped ping is ignored and don't shift the next pings
+	if m.pong(1, now) {
+		t.Error("pong of a dropped ping")
+	}
+	if !m.pong(3, now) || m.latency != 17*time.Second {
+		t.Errorf("latency = %v, want 17s", m.latency)
+	}
+	if !m.pong(2, now) || m.latency != 18*time.Second {
+		t.Errorf("latency = %v, want 18s", m.latency)
+	}
+	if m.pong(2, now) {
+		t.Error("pong received twice")
+	}
+	if len(m.pings) != maxPendingPing-2 {
+		t.Errorf("pending pings = %d, want %d", len(m.pings), maxPendingPing-2)
+	}
+}
+
+func TestRequestResponseTimesCount(t *testing.T) {
+	sc := NewSimConnectWithTransport(nil)
+	times := make([]float32, 1)
+	if err, _ := sc.RequestResponseTimes(maxResponseTimes+1, &times[0]); err == nil {
+		t.Error("want error for more than maxResponseTimes floats")
+	}
+	if err, _ := sc.RequestResponseTimes(0, &times[0]); err == nil {
+		t.Error("want error for 0 float")
+	}
+}
@@ -0,0 +1,478 @@
+package simconnect
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"sync"
+	"time"
+)
+
+// The file of a Recorder start with recordMagic and after each packet is written with
+// uvarint(microseconds since the previous packet) uvarint(size) and the SIMCONNECT_RECV_* packet.
+var recordMagic = []byte("SCREC\x01")
+
+// Recorder is a Transport writing all the packets received by GetNextDispatch in a file.
+// The file can be read with NewReplay.
+type Recorder struct {
+	Transport
+	mutex sync.Mutex
+	w     *bufio.Writer
+	last  time.Time
+	err   error
+}
+
+// NewRecorder return a Recorder using transport for the communication with the simulator and writing the packets in w
+func NewRecorder(transport Transport, w io.Writer) (*Recorder, error) {
+	bw := bufio.NewWriter(w)
+	_, err := bw.Write(recordMagic)
+	if err != nil {
+		return nil, err
+	}
+	return &Recorder{Transport: transport, w: bw}, nil
+}
+
+// GetNextDispatch return the packet of the Transport and write it with the time
+func (r *Recorder) GetNextDispatch() ([]byte, error) {
+	buf, err := r.Transport.GetNextDispatch()
+	if err != nil {
+		return buf, err
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.err != nil {
+		return buf, nil
+	}
+	now := time.Now()
+	var delta time.Duration
+	if !r.last.IsZero() {
+		delta = now.Sub(r.last)
+	}
+	r.last = now
+	header := make([]byte, 2*binary.MaxVarintLen64)
+	n := binary.PutUvarint(header, uint64(delta/time.Microsecond))
+	n += binary.PutUvarint(header[n:], uint64(len(buf)))
+	_, r.err = r.w.Write(header[:n])
+	if r.err == nil {
+		_, r.err = r.w.Write(buf)
+	}
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	return buf, nil
+}
+
+// Unwrap return the Transport of the Recorder, the optional functions (ex: AITransport) are called on it
+func (r *Recorder) Unwrap() Transport {
+	return r.Transport
+}
+
+// Err return the first error when writing the file
+func (r *Recorder) Err() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.err
+}
+
+var (
+	_ Transport                  = (*Replay)(nil)
+	_ DataTransport              = (*Replay)(nil)
+	_ SystemTransport            = (*Replay)(nil)
+	_ NotificationGroupTransport = (*Replay)(nil)
+	_ InputTransport             = (*Replay)(nil)
+	_ AITransport                = (*Replay)(nil)
+	_ WeatherTransport           = (*Replay)(nil)
+	_ FacilitiesTransport        = (*Replay)(nil)
+)
+
+// Replay is a Transport sending the packets recorded by a Recorder. All the functions sending data to
+// the simulator do nothing, so your application must connect the SimVars and events in the same order
+// than during the record for matching the IDs.
+type Replay struct {
+	mutex  sync.Mutex
+	r      *bufio.Reader
+	speed  float64
+	sendID uint32
+	clock  time.Time
+	next   []byte
+	wait   time.Duration
+	end    bool
+	err    error     // the error of a truncated or corrupt file
+	paused time.Time // zero when not paused
+}
+
+// NewReplay return a Replay reading the file written by a Recorder.
+//
+// speed is 1 for replay at real speed, 10 for ten times faster and 0 for sending all the packets without waiting.
+// A SIMCONNECT_RECV_QUIT is sent at the end of the file, GetNextDispatch return the error of a truncated or corrupt file.
+func NewReplay(r io.Reader, speed float64) (*Replay, error) {
+	br := bufio.NewReader(r)
+	magic := make([]byte, len(recordMagic))
+	_, err := io.ReadFull(br, magic)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(magic, recordMagic) {
+		return nil, errors.New("Invalid record file")
+	}
+	return &Replay{r: br, speed: speed}, nil
+}
+
+// read the next packet of the file, must be called with the lock. Return io.EOF only at the end of the last packet.
+func (r *Replay) read() error {
+	delta, err := binary.ReadUvarint(r.r)
+	if err != nil {
+		return err
+	}
+	size, err := binary.ReadUvarint(r.r)
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	if size < uint64(binary.Size(SIMCONNECT_RECV{})) || size > maxPacketSize {
+		return errors.New("Invalid record size")
+	}
+	buf := make([]byte, size)
+	_, err = io.ReadFull(r.r, buf)
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	if err != nil {
+		return err
+	}
+	r.next = buf
+	r.wait = time.Duration(delta) * time.Microsecond
+	if r.speed > 0 {
+		r.wait = time.Duration(float64(r.wait) / r.speed)
+	}
+	return nil
+}
+
+// GetNextDispatch return the next packet of the file when his time is reached
+func (r *Replay) GetNextDispatch() ([]byte, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.err != nil {
+		return nil, r.err
+	}
+	if r.end {
+		return nil, errors.New("End of replay")
+	}
+	if !r.paused.IsZero() {
+		return nil, errors.New("No dispatch")
+	}
+	if r.next == nil {
+		err := r.read()
+		if err == io.EOF {
+			r.end = true
+			quit := new(packetWriter).
+				putUint32(uint32(binary.Size(SIMCONNECT_RECV{}))).
+				putUint32(0).
+				putUint32(SIMCONNECT_RECV_ID_QUIT)
+			return quit.Bytes(), nil
+		}
+		if err != nil {
+			r.err = err
+			return nil, err
+		}
+	}
+	if r.clock.IsZero() {
+		r.clock = time.Now()
+	}
+	if r.speed > 0 && time.Since(r.clock) < r.wait {
+		return nil, errors.New("No dispatch")
+	}
+	r.clock = r.clock.Add(r.wait)
+	buf := r.next
+	r.next = nil
+	return buf, nil
+}
+
+// Pause stop the sending of the packets until Resume. Call Pause before Connect for connecting the SimVars and the
+// events before the first packet.
+func (r *Replay) Pause() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.paused.IsZero() {
+		r.paused = time.Now()
+	}
+}
+
+// Resume continue the replay stopped by Pause, the time of the pause is not counted
+func (r *Replay) Resume() {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if r.paused.IsZero() {
+		return
+	}
+	if !r.clock.IsZero() {
+		r.clock = r.clock.Add(time.Since(r.paused))
+	}
+	r.paused = time.Time{}
+}
+
+// RequestResponseTimes do nothing
+func (r *Replay) RequestResponseTimes(nCount uint32, fElapsedSeconds []float32) error {
+	return r.send()
+}
+
+func (r *Replay) send() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.sendID++
+	return nil
+}
+
+// Open start the replay
+func (r *Replay) Open(appTitle string) error {
+	return r.send()
+}
+
+// Close stop the replay
+func (r *Replay) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.end = true
+	return nil
+}
+
+// GetLastSentPacketID return the number of calls
+func (r *Replay) GetLastSentPacketID() (uint32, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.sendID, nil
+}
+
+// MapClientEventToSimEvent do nothing
+func (r *Replay) MapClientEventToSimEvent(EventID uint32, EventName string) error {
+	return r.send()
+}
+
+// TransmitClientEvent do nothing
+func (r *Replay) TransmitClientEvent(ObjectID uint32, EventID uint32, dwData int, GroupID GroupPriority, Flags EventFlag) error {
+	return r.send()
+}
+
+// AddClientEventToNotificationGroup do nothing
+func (r *Replay) AddClientEventToNotificationGroup(GroupID uint32, EventID uint32, bMaskable bool) error {
+	return r.send()
+}
+
+// RemoveClientEvent do nothing
+func (r *Replay) RemoveClientEvent(GroupID uint32, EventID uint32) error {
+	return r.send()
+}
+
+// SetNotificationGroupPriority do nothing
+func (r *Replay) SetNotificationGroupPriority(GroupID uint32, uPriority GroupPriority) error {
+	return r.send()
+}
+
+// ClearNotificationGroup do nothing
+func (r *Replay) ClearNotificationGroup(GroupID uint32) error {
+	return r.send()
+}
+
+// RequestNotificationGroup do nothing
+func (r *Replay) RequestNotificationGroup(GroupID uint32, dwReserved uint32, Flags uint32) error {
+	return r.send()
+}
+
+// AddToDataDefinition do nothing
+func (r *Replay) AddToDataDefinition(DefineID uint32, DatumName string, UnitsName string, DatumType uint32, fEpsilon float32, DatumID uint32) error {
+	return r.send()
+}
+
+// ClearDataDefinition do nothing
+func (r *Replay) ClearDataDefinition(DefineID uint32) error {
+	return r.send()
+}
+
+// RequestDataOnSimObject do nothing
+func (r *Replay) RequestDataOnSimObject(RequestID uint32, DefineID uint32, ObjectID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) error {
+	return r.send()
+}
+
+// RequestDataOnSimObjectType do nothing
+func (r *Replay) RequestDataOnSimObjectType(RequestID uint32, DefineID uint32, dwRadiusMeters uint32, t uint32) error {
+	return r.send()
+}
+
+// SetDataOnSimObject do nothing
+func (r *Replay) SetDataOnSimObject(DefineID uint32, ObjectID uint32, Flags uint32, ArrayCount uint32, cbUnitSize uint32, pDataSet []byte) error {
+	return r.send()
+}
+
+// MapInputEventToClientEvent do nothing
+func (r *Replay) MapInputEventToClientEvent(GroupID uint32, szInputDefinition string, DownEventID uint32, DownValue uint32, UpEventID uint32, UpValue uint32, bMaskable bool) error {
+	return r.send()
+}
+
+// SetInputGroupPriority do nothing
+func (r *Replay) SetInputGroupPriority(GroupID uint32, uPriority uint32) error {
+	return r.send()
+}
+
+// RemoveInputEvent do nothing
+func (r *Replay) RemoveInputEvent(GroupID uint32, szInputDefinition string) error {
+	return r.send()
+}
+
+// ClearInputGroup do nothing
+func (r *Replay) ClearInputGroup(GroupID uint32) error {
+	return r.send()
+}
+
+// SetInputGroupState do nothing
+func (r *Replay) SetInputGroupState(GroupID uint32, dwState SimConnectStat) error {
+	return r.send()
+}
+
+// RequestReservedKey do nothing
+func (r *Replay) RequestReservedKey(EventID uint32, szKeyChoice1 string, szKeyChoice2 string, szKeyChoice3 string) error {
+	return r.send()
+}
+
+// SubscribeToSystemEvent do nothing
+func (r *Replay) SubscribeToSystemEvent(EventID uint32, SystemEventName SystemEvent) error {
+	return r.send()
+}
+
+// UnsubscribeFromSystemEvent do nothing
+func (r *Replay) UnsubscribeFromSystemEvent(EventID uint32) error {
+	return r.send()
+}
+
+// WeatherRequestInterpolatedObservation do nothing
+func (r *Replay) WeatherRequestInterpolatedObservation(RequestID uint32, lat float32, lon float32, alt float32) error {
+	return r.send()
+}
+
+// WeatherRequestObservationAtStation do nothing
+func (r *Replay) WeatherRequestObservationAtStation(RequestID uint32, szICAO string) error {
+	return r.send()
+}
+
+// WeatherRequestObservationAtNearestStation do nothing
+func (r *Replay) WeatherRequestObservationAtNearestStation(RequestID uint32, lat float32, lon float32) error {
+	return r.send()
+}
+
+// WeatherCreateStation do nothing
+func (r *Replay) WeatherCreateStation(RequestID uint32, szICAO string, szName string, lat float32, lon float32, alt float32) error {
+	return r.send()
+}
+
+// WeatherRemoveStation do nothing
+func (r *Replay) WeatherRemoveStation(RequestID uint32, szICAO string) error {
+	return r.send()
+}
+
+// WeatherSetObservation do nothing
+func (r *Replay) WeatherSetObservation(Seconds uint32, szMETAR string) error {
+	return r.send()
+}
+
+// WeatherSetModeServer do nothing
+func (r *Replay) WeatherSetModeServer(dwPort uint32, dwSeconds uint32) error {
+	return r.send()
+}
+
+// WeatherSetModeTheme do nothing
+func (r *Replay) WeatherSetModeTheme(szThemeName string) error {
+	return r.send()
+}
+
+// WeatherSetModeGlobal do nothing
+func (r *Replay) WeatherSetModeGlobal() error {
+	return r.send()
+}
+
+// WeatherSetModeCustom do nothing
+func (r *Replay) WeatherSetModeCustom() error {
+	return r.send()
+}
+
+// WeatherSetDynamicUpdateRate do nothing
+func (r *Replay) WeatherSetDynamicUpdateRate(dwRate uint32) error {
+	return r.send()
+}
+
+// WeatherRequestCloudState do nothing
+func (r *Replay) WeatherRequestCloudState(RequestID uint32, minLat float32, minLon float32, minAlt float32, maxLat float32, maxLon float32, maxAlt float32, dwFlags uint32) error {
+	return r.send()
+}
+
+// WeatherCreateThermal do nothing
+func (r *Replay) WeatherCreateThermal(RequestID uint32, lat float32, lon float32, alt float32, radius float32, height float32, coreRate float32, coreTurbulence float32, sinkRate float32, sinkTurbulence float32, coreSize float32, coreTransitionSize float32, sinkLayerSize float32, sinkTransitionSize float32) error {
+	return r.send()
+}
+
+// WeatherRemoveThermal do nothing
+func (r *Replay) WeatherRemoveThermal(ObjectID uint32) error {
+	return r.send()
+}
+
+// RequestSystemState do nothing
+func (r *Replay) RequestSystemState(RequestID uint32, szState string) error {
+	return r.send()
+}
+
+// Text do nothing
+func (r *Replay) Text(t uint32, fTimeSeconds float32, EventID uint32, pDataSet string) error {
+	return r.send()
+}
+
+// AICreateParkedATCAircraft do nothing
+func (r *Replay) AICreateParkedATCAircraft(szContainerTitle string, szTailNumber string, szAirportID string, RequestID uint32) error {
+	return r.send()
+}
+
+// AICreateEnrouteATCAircraft do nothing
+func (r *Replay) AICreateEnrouteATCAircraft(szContainerTitle string, szTailNumber string, iFlightNumber int, szFlightPlanPath string, dFlightPlanPosition float64, bTouchAndGo uint32, RequestID uint32) error {
+	return r.send()
+}
+
+// AICreateNonATCAircraft do nothing
+func (r *Replay) AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) error {
+	return r.send()
+}
+
+// AICreateSimulatedObject do nothing
+func (r *Replay) AICreateSimulatedObject(szContainerTitle string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) error {
+	return r.send()
+}
+
+// AIReleaseControl do nothing
+func (r *Replay) AIReleaseControl(ObjectID uint32, RequestID uint32) error {
+	return r.send()
+}
+
+// AIRemoveObject do nothing
+func (r *Replay) AIRemoveObject(ObjectID uint32, RequestID uint32) error {
+	return r.send()
+}
+
+// AISetAircraftFlightPlan do nothing
+func (r *Replay) AISetAircraftFlightPlan(ObjectID uint32, szFlightPlanPath string, RequestID uint32) error {
+	return r.send()
+}
+
+// SubscribeToFacilities do nothing
+func (r *Replay) SubscribeToFacilities(listType uint32, RequestID uint32) error {
+	return r.send()
+}
+
+// UnsubscribeToFacilities do nothing
+func (r *Replay) UnsubscribeToFacilities(listType uint32) error {
+	return r.send()
+}
+
+// RequestFacilitiesList do nothing
+func (r *Replay) RequestFacilitiesList(listType uint32, RequestID uint32) error {
+	return r.send()
+}
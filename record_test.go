@@ -0,0 +1,123 @@
+package simconnect_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	sim "github.com/micmonay/simconnect"
+	"github.com/micmonay/simconnect/simconnecttest"
+)
+
+func TestRecordReplay(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetSimVar("PLANE ALTITUDE", 1000)
+	var file bytes.Buffer
+	recorder, err := sim.NewRecorder(fake, &file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	esc := sim.NewEasySimConnectWithTransport(recorder)
+	esc.SetDelay(10 * time.Millisecond)
+	c, err := esc.Connect("TestApp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-c
+	cSimVar, err := esc.ConnectToSimVar(sim.SimVarPlaneAltitude())
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-cSimVar
+	fake.SetSimVar("PLANE ALTITUDE", 2000)
+	for result := range cSimVar {
+		if f, _ := result[0].GetFloat64(); f == 2000 {
+			break
+		}
+	}
+	<-esc.Close()
+	if err := recorder.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	replay, err := sim.NewReplay(&file, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the SimVar is connected before the first data
+	replay.Pause()
+	esc = sim.NewEasySimConnectWithTransport(replay)
+	esc.SetDelay(10 * time.Millisecond)
+	c, err = esc.Connect("TestApp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cSimVar, err = esc.ConnectToSimVar(sim.SimVarPlaneAltitude())
+	if err != nil {
+		t.Fatal(err)
+	}
+	replay.Resume()
+	if !<-c {
+		t.Fatal("not connected")
+	}
+	values := make([]float64, 0)
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case result := <-cSimVar:
+			f, _ := result[0].GetFloat64()
+			values = append(values, f)
+			continue
+		case open := <-c:
+			if open {
+				t.Error("want false at the end of replay")
+			}
+		case <-timeout:
+			t.Fatal("timeout waiting end of replay")
+		}
+		break
+	}
+	if len(values) < 2 || values[0] != 1000 || values[len(values)-1] != 2000 {
+		t.Errorf("replayed values = %v", values)
+	}
+}
+
+func TestReplayInvalidFile(t *testing.T) {
+	_, err := sim.NewReplay(bytes.NewReader([]byte("not a record")), 1)
+	if err == nil {
+		t.Error("want error for invalid file")
+	}
+}
+
+func TestReplayCorruptFile(t *testing.T) {
+	quit := []byte{12, 0, 0, 0, 0, 0, 0, 0, 3, 0, 0, 0}
+	for name, record := range map[string][]byte{
+		"truncated packet": append([]byte{0, 12}, quit[:6]...),
+		"truncated size":   {0},
+		"bad varint":       {0, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF},
+		"invalid size":     {0, 0xFF, 0xFF, 0xFF, 0xFF, 0x0F},
+	} {
+		replay, err := sim.NewReplay(bytes.NewReader(append([]byte("SCREC\x01"), record...)), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buf, err := replay.GetNextDispatch(); err == nil {
+			t.Errorf("%s : want error, got the packet %v", name, buf)
+		}
+		if _, err := replay.GetNextDispatch(); err == nil {
+			t.Errorf("%s : want error after a corrupt file", name)
+		}
+	}
+
+	// the end of the file is a SIMCONNECT_RECV_QUIT
+	replay, err := sim.NewReplay(bytes.NewReader(append([]byte("SCREC\x01"), append([]byte{0, 12}, quit...)...)), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		buf, err := replay.GetNextDispatch()
+		if err != nil || !bytes.Equal(buf, quit) {
+			t.Errorf("packet %d = %v %v, want the quit packet", i, buf, err)
+		}
+	}
+}
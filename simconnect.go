@@ -4,43 +4,35 @@ package simconnect
 
 import (
 	"errors"
+	"fmt"
 	"unsafe"
 )
 
-// convert string to const char *
-func cChar(str string) uintptr {
-	b := []byte(str + "\x00")
-	return uintptr(unsafe.Pointer(&b[0]))
-}
-
-func cBool(b bool) uintptr {
-	mask := 0
-	if b {
-		mask = 1
-	}
-	return uintptr(mask)
-}
+// maxResponseTimes is the maximum nCount of RequestResponseTimes
+const maxResponseTimes = 1 << 16
 
 // SimConnect golang interface
 type SimConnect struct {
-	hSimConnect uintptr
-	syscallSC   *SyscallSC
+	transport Transport
 }
 
-// NewSimConnect get instance of SimConnect
+// NewSimConnect get instance of SimConnect using SimConnect.dll (only on windows)
 func NewSimConnect() (*SimConnect, error) {
-	var err error
-	simConnect := &SimConnect{}
-	simConnect.syscallSC, err = NewSyscallSC()
+	transport, err := newDefaultTransport()
 	if err != nil {
 		return nil, err
 	}
-	return simConnect, nil
+	return NewSimConnectWithTransport(transport), nil
+}
+
+// NewSimConnectWithTransport get instance of SimConnect using your Transport
+func NewSimConnectWithTransport(transport Transport) *SimConnect {
+	return &SimConnect{transport: transport}
 }
 
 // MapClientEventToSimEvent SimConnect_MapClientEventToSimEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * EventName = "")
 func (sc *SimConnect) MapClientEventToSimEvent(EventID uint32, EventName string) (error, uint32) {
-	err := sc.syscallSC.MapClientEventToSimEvent(sc.hSimConnect, uintptr(EventID), cChar(EventName))
+	err := sc.transport.MapClientEventToSimEvent(EventID, EventName)
 	id := new(uint32)
 	sc.GetLastSentPacketID(id)
 	return err, *id
@@ -48,7 +40,7 @@ func (sc *SimConnect) MapClientEventToSimEvent(EventID uint32, EventName string)
 
 // TransmitClientEvent SimConnect_TransmitClientEvent(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_CLIENT_EVENT_ID EventID, DWORD dwData, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_EVENT_FLAG Flags);
 func (sc *SimConnect) TransmitClientEvent(ObjectID uint32, EventID uint32, dwData int, GroupID GroupPriority, Flags EventFlag) (error, uint32) {
-	err := sc.syscallSC.TransmitClientEvent(sc.hSimConnect, uintptr(ObjectID), uintptr(EventID), uintptr(dwData), uintptr(GroupID), uintptr(Flags))
+	err := sc.transport.TransmitClientEvent(ObjectID, EventID, dwData, GroupID, Flags)
 	id := new(uint32)
 	sc.GetLastSentPacketID(id)
 	return err, *id
@@ -61,7 +53,7 @@ func (sc *SimConnect) SetSystemEventState(EventID uint32, dwState uint32) (error
 
 // AddClientEventToNotificationGroup SimConnect_AddClientEventToNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_CLIENT_EVENT_ID EventID, BOOL bMaskable = FALSE);
 func (sc *SimConnect) AddClientEventToNotificationGroup(GroupID uint32, EventID uint32, bMaskable bool) (error, uint32) {
-	err := sc.syscallSC.AddClientEventToNotificationGroup(sc.hSimConnect, uintptr(GroupID), uintptr(EventID), cBool(bMaskable))
+	err := sc.transport.AddClientEventToNotificationGroup(GroupID, EventID, bMaskable)
 	id := new(uint32)
 	sc.GetLastSentPacketID(id)
 	return err, *id
@@ -69,27 +61,55 @@ func (sc *SimConnect) AddClientEventToNotificationGroup(GroupID uint32, EventID
 
 // RemoveClientEvent SimConnect_RemoveClientEvent(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_CLIENT_EVENT_ID EventID);
 func (sc *SimConnect) RemoveClientEvent(GroupID uint32, EventID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.notificationGroupTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.RemoveClientEvent(GroupID, EventID)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // SetNotificationGroupPriority SimConnect_SetNotificationGroupPriority(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, DWORD uPriority);
 func (sc *SimConnect) SetNotificationGroupPriority(GroupID uint32, uPriority GroupPriority) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.notificationGroupTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.SetNotificationGroupPriority(GroupID, uPriority)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // ClearNotificationGroup SimConnect_ClearNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID);
 func (sc *SimConnect) ClearNotificationGroup(GroupID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.notificationGroupTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.ClearNotificationGroup(GroupID)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // RequestNotificationGroup SimConnect_RequestNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, DWORD dwReserved = 0, DWORD Flags = 0);
 func (sc *SimConnect) RequestNotificationGroup(GroupID uint32, dwReserved uint32, Flags uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.notificationGroupTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.RequestNotificationGroup(GroupID, dwReserved, Flags)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // AddToDataDefinition SimConnect_AddToDataDefinition(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID, const char * DatumName, const char * UnitsName, SIMCONNECT_DATATYPE DatumType = SIMCONNECT_DATATYPE_FLOAT64, float fEpsilon = 0, DWORD DatumID = SIMCONNECT_UNUSED);
 func (sc *SimConnect) AddToDataDefinition(DefineID uint32, DatumName string, UnitsName string, DatumType uint32, fEpsilon float32, DatumID uint32) (error, uint32) {
-	err := sc.syscallSC.AddToDataDefinition(sc.hSimConnect, uintptr(DefineID), cChar(DatumName), cChar(UnitsName), uintptr(DatumType), uintptr(fEpsilon), uintptr(DatumID))
+	err := sc.transport.AddToDataDefinition(DefineID, DatumName, UnitsName, DatumType, fEpsilon, DatumID)
 	id := new(uint32)
 	sc.GetLastSentPacketID(id)
 	return err, *id
@@ -97,7 +117,7 @@ func (sc *SimConnect) AddToDataDefinition(DefineID uint32, DatumName string, Uni
 
 // ClearDataDefinition SimConnect_ClearDataDefinition(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID);
 func (sc *SimConnect) ClearDataDefinition(DefineID uint32) (error, uint32) {
-	err := sc.syscallSC.ClearDataDefinition(sc.hSimConnect, uintptr(DefineID))
+	err := sc.transport.ClearDataDefinition(DefineID)
 	id := new(uint32)
 	sc.GetLastSentPacketID(id)
 	return err, *id
@@ -105,12 +125,19 @@ func (sc *SimConnect) ClearDataDefinition(DefineID uint32) (error, uint32) {
 
 // RequestDataOnSimObject SimConnect_RequestDataOnSimObject(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, SIMCONNECT_DATA_DEFINITION_ID DefineID, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_PERIOD Period, SIMCONNECT_DATA_REQUEST_FLAG Flags = 0, DWORD origin = 0, DWORD interval = 0, DWORD limit = 0);
 func (sc *SimConnect) RequestDataOnSimObject(RequestID uint32, DefineID uint32, ObjectID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.dataTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.RequestDataOnSimObject(RequestID, DefineID, ObjectID, Period, Flags, origin, interval, limit)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // RequestDataOnSimObjectType SimConnect_RequestDataOnSimObjectType(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, SIMCONNECT_DATA_DEFINITION_ID DefineID, DWORD dwRadiusMeters, SIMCONNECT_SIMOBJECT_TYPE type);
 func (sc *SimConnect) RequestDataOnSimObjectType(RequestID uint32, DefineID uint32, dwRadiusMeters uint32, t uint32) (error, uint32) {
-	err := sc.syscallSC.RequestDataOnSimObjectType(sc.hSimConnect, uintptr(RequestID), uintptr(DefineID), uintptr(dwRadiusMeters), uintptr(t))
+	err := sc.transport.RequestDataOnSimObjectType(RequestID, DefineID, dwRadiusMeters, t)
 	id := new(uint32)
 	sc.GetLastSentPacketID(id)
 	return err, *id
@@ -118,10 +145,10 @@ func (sc *SimConnect) RequestDataOnSimObjectType(RequestID uint32, DefineID uint
 
 // SetDataOnSimObject SimConnect_SetDataOnSimObject(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_SET_FLAG Flags, DWORD ArrayCount, DWORD cbUnitSize, void * pDataSet);
 func (sc *SimConnect) SetDataOnSimObject(DefineID uint32, ObjectID uint32, Flags uint32, ArrayCount uint32, cbUnitSize uint32, pDataSet []byte) (error, uint32) {
-	if len(pDataSet) < 0 {
+	if len(pDataSet) == 0 {
 		return errors.New("Your pDataSet is too short on SetDataOnSimObject"), 0
 	}
-	err := sc.syscallSC.SetDataOnSimObject(sc.hSimConnect, uintptr(DefineID), uintptr(ObjectID), uintptr(Flags), uintptr(ArrayCount), uintptr(cbUnitSize), uintptr(unsafe.Pointer(&pDataSet[0])))
+	err := sc.transport.SetDataOnSimObject(DefineID, ObjectID, Flags, ArrayCount, cbUnitSize, pDataSet)
 
 	id := new(uint32)
 	sc.GetLastSentPacketID(id)
@@ -130,8 +157,7 @@ func (sc *SimConnect) SetDataOnSimObject(DefineID uint32, ObjectID uint32, Flags
 
 // MapInputEventToClientEvent SimConnect_MapInputEventToClientEvent(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, const char * szInputDefinition, SIMCONNECT_CLIENT_EVENT_ID DownEventID, DWORD DownValue = 0, SIMCONNECT_CLIENT_EVENT_ID UpEventID = (SIMCONNECT_CLIENT_EVENT_ID)SIMCONNECT_UNUSED, DWORD UpValue = 0, BOOL bMaskable = FALSE);
 func (sc *SimConnect) MapInputEventToClientEvent(GroupID uint32, szInputDefinition string, DownEventID uint32, DownValue uint32, UpEventID uint32, UpValue uint32, bMaskable bool) (error, uint32) {
-
-	err := sc.syscallSC.MapInputEventToClientEvent(sc.hSimConnect, uintptr(GroupID), cChar(szInputDefinition), uintptr(DownEventID), uintptr(DownValue), uintptr(UpEventID), uintptr(UpValue), cBool(bMaskable))
+	err := sc.transport.MapInputEventToClientEvent(GroupID, szInputDefinition, DownEventID, DownValue, UpEventID, UpValue, bMaskable)
 	id := new(uint32)
 	sc.GetLastSentPacketID(id)
 	return err, *id
@@ -139,22 +165,43 @@ func (sc *SimConnect) MapInputEventToClientEvent(GroupID uint32, szInputDefiniti
 
 // SetInputGroupPriority SimConnect_SetInputGroupPriority(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, DWORD uPriority);
 func (sc *SimConnect) SetInputGroupPriority(GroupID uint32, uPriority uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.inputTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.SetInputGroupPriority(GroupID, uPriority)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // RemoveInputEvent SimConnect_RemoveInputEvent(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, const char * szInputDefinition);
 func (sc *SimConnect) RemoveInputEvent(GroupID uint32, szInputDefinition string) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.inputTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.RemoveInputEvent(GroupID, szInputDefinition)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // ClearInputGroup SimConnect_ClearInputGroup(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID);
 func (sc *SimConnect) ClearInputGroup(GroupID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.inputTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.ClearInputGroup(GroupID)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // SetInputGroupState SimConnect_SetInputGroupState(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, DWORD dwState);
 func (sc *SimConnect) SetInputGroupState(GroupID uint32, dwState SimConnectStat) (error, uint32) {
-	err := sc.syscallSC.SetInputGroupState(sc.hSimConnect, uintptr(GroupID), uintptr(dwState))
+	err := sc.transport.SetInputGroupState(GroupID, dwState)
 	id := new(uint32)
 	sc.GetLastSentPacketID(id)
 	return err, *id
@@ -162,12 +209,19 @@ func (sc *SimConnect) SetInputGroupState(GroupID uint32, dwState SimConnectStat)
 
 // RequestReservedKey SimConnect_RequestReservedKey(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * szKeyChoice1 = "", const char * szKeyChoice2 = "", const char * szKeyChoice3 = "");
 func (sc *SimConnect) RequestReservedKey(EventID uint32, szKeyChoice1 string, szKeyChoice2 string, szKeyChoice3 string) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.inputTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.RequestReservedKey(EventID, szKeyChoice1, szKeyChoice2, szKeyChoice3)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // SubscribeToSystemEvent SimConnect_SubscribeToSystemEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * SystemEventName);
 func (sc *SimConnect) SubscribeToSystemEvent(EventID uint32, SystemEventName SystemEvent) (error, uint32) {
-	err := sc.syscallSC.SubscribeToSystemEvent(sc.hSimConnect, uintptr(EventID), cChar(string(SystemEventName)))
+	err := sc.transport.SubscribeToSystemEvent(EventID, SystemEventName)
 	id := new(uint32)
 	sc.GetLastSentPacketID(id)
 	return err, *id
@@ -175,112 +229,266 @@ func (sc *SimConnect) SubscribeToSystemEvent(EventID uint32, SystemEventName Sys
 
 // UnsubscribeFromSystemEvent SimConnect_UnsubscribeFromSystemEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID);
 func (sc *SimConnect) UnsubscribeFromSystemEvent(EventID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.systemTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.UnsubscribeFromSystemEvent(EventID)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // WeatherRequestInterpolatedObservation SimConnect_WeatherRequestInterpolatedObservation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float lat, float lon, float alt);
 func (sc *SimConnect) WeatherRequestInterpolatedObservation(RequestID uint32, lat float32, lon float32, alt float32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.weatherTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.WeatherRequestInterpolatedObservation(RequestID, lat, lon, alt)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // WeatherRequestObservationAtStation SimConnect_WeatherRequestObservationAtStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szICAO);
 func (sc *SimConnect) WeatherRequestObservationAtStation(RequestID uint32, szICAO string) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.weatherTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.WeatherRequestObservationAtStation(RequestID, szICAO)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // WeatherRequestObservationAtNearestStation SimConnect_WeatherRequestObservationAtNearestStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float lat, float lon);
 func (sc *SimConnect) WeatherRequestObservationAtNearestStation(RequestID uint32, lat float32, lon float32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.weatherTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.WeatherRequestObservationAtNearestStation(RequestID, lat, lon)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // WeatherCreateStation SimConnect_WeatherCreateStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szICAO, const char * szName, float lat, float lon, float alt);
 func (sc *SimConnect) WeatherCreateStation(RequestID uint32, szICAO string, szName string, lat float32, lon float32, alt float32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.weatherTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.WeatherCreateStation(RequestID, szICAO, szName, lat, lon, alt)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // WeatherRemoveStation SimConnect_WeatherRemoveStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szICAO);
 func (sc *SimConnect) WeatherRemoveStation(RequestID uint32, szICAO string) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.weatherTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.WeatherRemoveStation(RequestID, szICAO)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // WeatherSetObservation SimConnect_WeatherSetObservation(HANDLE hSimConnect, DWORD Seconds, const char * szMETAR);
 func (sc *SimConnect) WeatherSetObservation(Seconds uint32, szMETAR string) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.weatherTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.WeatherSetObservation(Seconds, szMETAR)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // WeatherSetModeServer SimConnect_WeatherSetModeServer(HANDLE hSimConnect, DWORD dwPort, DWORD dwSeconds);
 func (sc *SimConnect) WeatherSetModeServer(dwPort uint32, dwSeconds uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.weatherTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.WeatherSetModeServer(dwPort, dwSeconds)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // WeatherSetModeTheme SimConnect_WeatherSetModeTheme(HANDLE hSimConnect, const char * szThemeName);
 func (sc *SimConnect) WeatherSetModeTheme(szThemeName string) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.weatherTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.WeatherSetModeTheme(szThemeName)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // WeatherSetModeGlobal SimConnect_WeatherSetModeGlobal(HANDLE hSimConnect);
 func (sc *SimConnect) WeatherSetModeGlobal() (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.weatherTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.WeatherSetModeGlobal()
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // WeatherSetModeCustom SimConnect_WeatherSetModeCustom(HANDLE hSimConnect);
 func (sc *SimConnect) WeatherSetModeCustom() (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.weatherTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.WeatherSetModeCustom()
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // WeatherSetDynamicUpdateRate SimConnect_WeatherSetDynamicUpdateRate(HANDLE hSimConnect, DWORD dwRate);
 func (sc *SimConnect) WeatherSetDynamicUpdateRate(dwRate uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.weatherTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.WeatherSetDynamicUpdateRate(dwRate)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // WeatherRequestCloudState SimConnect_WeatherRequestCloudState(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float minLat, float minLon, float minAlt, float maxLat, float maxLon, float maxAlt, DWORD dwFlags = 0);
 func (sc *SimConnect) WeatherRequestCloudState(RequestID uint32, minLat float32, minLon float32, minAlt float32, maxLat float32, maxLon float32, maxAlt float32, dwFlags uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.weatherTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.WeatherRequestCloudState(RequestID, minLat, minLon, minAlt, maxLat, maxLon, maxAlt, dwFlags)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // WeatherCreateThermal SimConnect_WeatherCreateThermal(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float lat, float lon, float alt, float radius, float height, float coreRate = 3.0f, float coreTurbulence = 0.05f, float sinkRate = 3.0f, float sinkTurbulence = 0.2f, float coreSize = 0.4f, float coreTransitionSize = 0.1f, float sinkLayerSize = 0.4f, float sinkTransitionSize = 0.1f);
 func (sc *SimConnect) WeatherCreateThermal(RequestID uint32, lat float32, lon float32, alt float32, radius float32, height float32, coreRate float32, coreTurbulence float32, sinkRate float32, sinkTurbulence float32, coreSize float32, coreTransitionSize float32, sinkLayerSize float32, sinkTransitionSize float32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.weatherTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.WeatherCreateThermal(RequestID, lat, lon, alt, radius, height, coreRate, coreTurbulence, sinkRate, sinkTurbulence, coreSize, coreTransitionSize, sinkLayerSize, sinkTransitionSize)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // WeatherRemoveThermal SimConnect_WeatherRemoveThermal(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID);
 func (sc *SimConnect) WeatherRemoveThermal(ObjectID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.weatherTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.WeatherRemoveThermal(ObjectID)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // AICreateParkedATCAircraft SimConnect_AICreateParkedATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, const char * szAirportID, SIMCONNECT_DATA_REQUEST_ID RequestID);
 func (sc *SimConnect) AICreateParkedATCAircraft(szContainerTitle string, szTailNumber string, szAirportID string, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.aITransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.AICreateParkedATCAircraft(szContainerTitle, szTailNumber, szAirportID, RequestID)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // AICreateEnrouteATCAircraft SimConnect_AICreateEnrouteATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, int iFlightNumber, const char * szFlightPlanPath, double dFlightPlanPosition, BOOL bTouchAndGo, SIMCONNECT_DATA_REQUEST_ID RequestID);
 func (sc *SimConnect) AICreateEnrouteATCAircraft(szContainerTitle string, szTailNumber string, iFlightNumber int, szFlightPlanPath string, dFlightPlanPosition float64, bTouchAndGo uint32, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.aITransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.AICreateEnrouteATCAircraft(szContainerTitle, szTailNumber, iFlightNumber, szFlightPlanPath, dFlightPlanPosition, bTouchAndGo, RequestID)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // AICreateNonATCAircraft SimConnect_AICreateNonATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, SIMCONNECT_DATA_INITPOSITION InitPos, SIMCONNECT_DATA_REQUEST_ID RequestID);
-func (sc *SimConnect) AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, InitPos uint32, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+func (sc *SimConnect) AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) (error, uint32) {
+	t, err := sc.aITransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.AICreateNonATCAircraft(szContainerTitle, szTailNumber, InitPos, RequestID)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // AICreateSimulatedObject SimConnect_AICreateSimulatedObject(HANDLE hSimConnect, const char * szContainerTitle, SIMCONNECT_DATA_INITPOSITION InitPos, SIMCONNECT_DATA_REQUEST_ID RequestID);
-func (sc *SimConnect) AICreateSimulatedObject(szContainerTitle string, InitPos uint32, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+func (sc *SimConnect) AICreateSimulatedObject(szContainerTitle string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) (error, uint32) {
+	t, err := sc.aITransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.AICreateSimulatedObject(szContainerTitle, InitPos, RequestID)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // AIReleaseControl SimConnect_AIReleaseControl(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_REQUEST_ID RequestID);
 func (sc *SimConnect) AIReleaseControl(ObjectID uint32, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.aITransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.AIReleaseControl(ObjectID, RequestID)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // AIRemoveObject SimConnect_AIRemoveObject(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_REQUEST_ID RequestID);
 func (sc *SimConnect) AIRemoveObject(ObjectID uint32, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.aITransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.AIRemoveObject(ObjectID, RequestID)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // AISetAircraftFlightPlan SimConnect_AISetAircraftFlightPlan(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, const char * szFlightPlanPath, SIMCONNECT_DATA_REQUEST_ID RequestID);
 func (sc *SimConnect) AISetAircraftFlightPlan(ObjectID uint32, szFlightPlanPath string, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.aITransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.AISetAircraftFlightPlan(ObjectID, szFlightPlanPath, RequestID)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // ExecuteMissionAction SimConnect_ExecuteMissionAction(HANDLE hSimConnect, const GUID guidInstanceId);
@@ -295,7 +503,7 @@ func (sc *SimConnect) CompleteCustomMissionAction(guidInstanceID GUID) (error, u
 
 // Close SimConnect_Close(HANDLE hSimConnect);
 func (sc *SimConnect) Close() (error, uint32) {
-	err := sc.syscallSC.Close(sc.hSimConnect)
+	err := sc.transport.Close()
 	id := new(uint32)
 	sc.GetLastSentPacketID(id)
 	return err, *id
@@ -308,14 +516,17 @@ func (sc *SimConnect) RetrieveString(pData *uint32, cbData uint32, pStringV stri
 
 // GetLastSentPacketID SimConnect_GetLastSentPacketID(HANDLE hSimConnect, DWORD * pdwError);
 func (sc *SimConnect) GetLastSentPacketID(pdwError *uint32) error {
-	err := sc.syscallSC.GetLastSentPacketID(sc.hSimConnect, uintptr(unsafe.Pointer(pdwError)))
-
-	return err
+	id, err := sc.transport.GetLastSentPacketID()
+	if err != nil {
+		return err
+	}
+	*pdwError = id
+	return nil
 }
 
 // Open SimConnect_Open(HANDLE * phSimConnect, LPCSTR szName, HWND hWnd, DWORD UserEventWin32, HANDLE hEventHandle, DWORD ConfigIndex);
 func (sc *SimConnect) Open(appTitle string) (error, uint32) {
-	err := sc.syscallSC.Open(uintptr(unsafe.Pointer(&sc.hSimConnect)), cChar(appTitle), uintptr(unsafe.Pointer(nil)), 0, 0, 0)
+	err := sc.transport.Open(appTitle)
 	if err != nil {
 		return errors.New("No connected"), 0
 	}
@@ -330,15 +541,38 @@ func (sc *SimConnect) Open(appTitle string) (error, uint32) {
 
 // GetNextDispatch SimConnect_GetNextDispatch(HANDLE hSimConnect, SIMCONNECT_RECV ** ppData, DWORD * pcbData);
 func (sc *SimConnect) GetNextDispatch(ppData *unsafe.Pointer, pcbData *uint32) (error, uint32) {
-	err := sc.syscallSC.GetNextDispatch(sc.hSimConnect, uintptr(unsafe.Pointer(ppData)), uintptr(unsafe.Pointer(pcbData)))
+	buf, err := sc.transport.GetNextDispatch()
+	if err != nil {
+		return err, 0
+	}
+	if len(buf) == 0 {
+		return errors.New("Empty dispatch"), 0
+	}
+	*ppData = unsafe.Pointer(&buf[0])
+	*pcbData = uint32(len(buf))
 	id := new(uint32)
 	sc.GetLastSentPacketID(id)
 	return err, *id
 }
 
 // RequestResponseTimes SimConnect_RequestResponseTimes(HANDLE hSimConnect, DWORD nCount, float * fElapsedSeconds);
+// fElapsedSeconds must point to an array of nCount float, nCount is 65536 at most.
 func (sc *SimConnect) RequestResponseTimes(nCount uint32, fElapsedSeconds *float32) (error, uint32) {
-	return errors.New("not implemented"), 0
+	if nCount == 0 || fElapsedSeconds == nil {
+		return errors.New("RequestResponseTimes need at least one float"), 0
+	}
+	if nCount > maxResponseTimes {
+		return fmt.Errorf("RequestResponseTimes accept %d floats at most", maxResponseTimes), 0
+	}
+	times := (*[maxResponseTimes]float32)(unsafe.Pointer(fElapsedSeconds))[:nCount:nCount]
+	t, err := sc.systemTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.RequestResponseTimes(nCount, times)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // InsertString SimConnect_InsertString(char * pDest, DWORD cbDest, void ** ppEnd, DWORD * pcbStringV, const char * pSource);
@@ -373,7 +607,14 @@ func (sc *SimConnect) MenuDeleteSubItem(MenuEventID uint32, constSubMenuEventID
 
 // RequestSystemState SimConnect_RequestSystemState(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szState);
 func (sc *SimConnect) RequestSystemState(RequestID uint32, szState string) (error, uint32) {
-	return errors.New("not implemented"), 0
+	t, err := sc.systemTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.RequestSystemState(RequestID, szState)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // SetSystemState SimConnect_SetSystemState(HANDLE hSimConnect, const char * szState, DWORD dwInteger, float fFloat, const char * szString);
@@ -428,25 +669,44 @@ func (sc *SimConnect) FlightPlanLoad(szFileName string) (error, uint32) {
 
 // Text SimConnect_Text(HANDLE hSimConnect, SIMCONNECT_TEXT_TYPE type, float fTimeSeconds, SIMCONNECT_CLIENT_EVENT_ID EventID, DWORD cbUnitSize, void * pDataSet);
 func (sc *SimConnect) Text(t uint32, fTimeSeconds float32, EventID uint32, pDataSet string) (error, uint32) {
-	str := convGoStringtoBytes(pDataSet)
-	size := len(str)
-	err := sc.syscallSC.Text(sc.hSimConnect, uintptr(t), uintptr(fTimeSeconds), uintptr(EventID), uintptr(size), uintptr(unsafe.Pointer(&str[0])))
+	err := sc.transport.Text(t, fTimeSeconds, EventID, pDataSet)
 	id := new(uint32)
 	sc.GetLastSentPacketID(id)
 	return err, *id
 }
 
 // SubscribeToFacilities SimConnect_SubscribeToFacilities(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type, SIMCONNECT_DATA_REQUEST_ID RequestID);
-func (sc *SimConnect) SubscribeToFacilities(t uint32, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+func (sc *SimConnect) SubscribeToFacilities(listType uint32, RequestID uint32) (error, uint32) {
+	t, err := sc.facilitiesTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.SubscribeToFacilities(listType, RequestID)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // UnsubscribeToFacilities SimConnect_UnsubscribeToFacilities(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type);
-func (sc *SimConnect) UnsubscribeToFacilities(t uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+func (sc *SimConnect) UnsubscribeToFacilities(listType uint32) (error, uint32) {
+	t, err := sc.facilitiesTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.UnsubscribeToFacilities(listType)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
 
 // RequestFacilitiesList SimConnect_RequestFacilitiesList(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type, SIMCONNECT_DATA_REQUEST_ID RequestID);
-func (sc *SimConnect) RequestFacilitiesList(t uint32, RequestID uint32) (error, uint32) {
-	return errors.New("not implemented"), 0
+func (sc *SimConnect) RequestFacilitiesList(listType uint32, RequestID uint32) (error, uint32) {
+	t, err := sc.facilitiesTransport()
+	if err != nil {
+		return err, 0
+	}
+	err = t.RequestFacilitiesList(listType, RequestID)
+	id := new(uint32)
+	sc.GetLastSentPacketID(id)
+	return err, *id
 }
@@ -0,0 +1,116 @@
+package simconnecttest
+
+import (
+	"fmt"
+	"sort"
+
+	sim "github.com/micmonay/simconnect"
+)
+
+// facilitiesPerMessage is the number of facilities by message, small for testing the reassembly of the lists
+const facilitiesPerMessage = 4
+
+// facilityList is the facilities of a type in the reality bubble
+type facilityList struct {
+	records    map[string][]byte // encoded facilities by facilityKey
+	subscribed bool
+	requestID  uint32
+}
+
+// facilityKey return the key of a facility, the waypoints, NDBs and VORs can have the same ICAO at different positions
+func facilityKey(facility sim.FacilityAirport) string {
+	return fmt.Sprintf("%s %g %g", facility.ICAO, facility.Latitude, facility.Longitude)
+}
+
+// sorted return the records sorted by ICAO
+func (l *facilityList) sorted() [][]byte {
+	keys := make([]string, 0, len(l.records))
+	for key := range l.records {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	records := make([][]byte, len(keys))
+	for i, key := range keys {
+		records[i] = l.records[key]
+	}
+	return records
+}
+
+// SetAirports set the airports of the reality bubble, the new airports are sent to the subscription
+func (s *Simulator) SetAirports(airports ...sim.FacilityAirport) {
+	records := make(map[string][]byte)
+	for _, airport := range airports {
+		records[facilityKey(airport)] = putAirport(new(recvWriter), airport).Bytes()
+	}
+	s.setFacilities(sim.SIMCONNECT_FACILITY_LIST_TYPE_AIRPORT, records)
+}
+
+// SetWaypoints set the waypoints of the reality bubble, the new waypoints are sent to the subscription
+func (s *Simulator) SetWaypoints(waypoints ...sim.FacilityWaypoint) {
+	records := make(map[string][]byte)
+	for _, waypoint := range waypoints {
+		records[facilityKey(waypoint.FacilityAirport)] = putWaypoint(new(recvWriter), waypoint).Bytes()
+	}
+	s.setFacilities(sim.SIMCONNECT_FACILITY_LIST_TYPE_WAYPOINT, records)
+}
+
+// SetNDBs set the NDBs of the reality bubble, the new NDBs are sent to the subscription
+func (s *Simulator) SetNDBs(ndbs ...sim.FacilityNDB) {
+	records := make(map[string][]byte)
+	for _, ndb := range ndbs {
+		records[facilityKey(ndb.FacilityAirport)] = putNDB(new(recvWriter), ndb).Bytes()
+	}
+	s.setFacilities(sim.SIMCONNECT_FACILITY_LIST_TYPE_NDB, records)
+}
+
+// SetVORs set the VORs of the reality bubble, the new VORs are sent to the subscription
+func (s *Simulator) SetVORs(vors ...sim.FacilityVOR) {
+	records := make(map[string][]byte)
+	for _, vor := range vors {
+		records[facilityKey(vor.FacilityAirport)] = putVOR(new(recvWriter), vor).Bytes()
+	}
+	s.setFacilities(sim.SIMCONNECT_FACILITY_LIST_TYPE_VOR, records)
+}
+
+func (s *Simulator) setFacilities(listType uint32, records map[string][]byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	list := s.facilityList(listType)
+	added := &facilityList{records: make(map[string][]byte)}
+	for key, record := range records {
+		if _, found := list.records[key]; !found {
+			added.records[key] = record
+		}
+	}
+	list.records = records
+	if list.subscribed && len(added.records) > 0 {
+		s.sendFacilities(listType, list.requestID, added.sorted())
+	}
+}
+
+// facilityList return the list of the type, must be called with the lock
+func (s *Simulator) facilityList(listType uint32) *facilityList {
+	list, found := s.facilities[listType]
+	if !found {
+		list = &facilityList{records: make(map[string][]byte)}
+		s.facilities[listType] = list
+	}
+	return list
+}
+
+// sendFacilities send the records in messages of facilitiesPerMessage, an empty list is one empty message. Must be
+// called with the lock.
+func (s *Simulator) sendFacilities(listType uint32, requestID uint32, records [][]byte) {
+	outOf := (len(records) + facilitiesPerMessage - 1) / facilitiesPerMessage
+	if outOf == 0 {
+		outOf = 1
+	}
+	for entry := 0; entry < outOf; entry++ {
+		end := (entry + 1) * facilitiesPerMessage
+		if end > len(records) {
+			end = len(records)
+		}
+		chunk := records[entry*facilitiesPerMessage : end]
+		s.push(recvFacilitiesList(listType, requestID, uint32(entry), uint32(outOf), chunk))
+	}
+}
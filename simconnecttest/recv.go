@@ -0,0 +1,279 @@
+package simconnecttest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"unsafe"
+
+	sim "github.com/micmonay/simconnect"
+)
+
+// version written in dwVersion of all the records
+const recvVersion = 4
+
+// recvWriter build a SIMCONNECT_RECV_* record like the simulator
+type recvWriter struct {
+	bytes.Buffer
+}
+
+func newRecv(id uint32) *recvWriter {
+	r := &recvWriter{}
+	// dwSize is written by bytes()
+	r.putUint32(0).putUint32(recvVersion).putUint32(id)
+	return r
+}
+
+func (r *recvWriter) putUint32(v uint32) *recvWriter {
+	binary.Write(&r.Buffer, binary.LittleEndian, v)
+	return r
+}
+
+func (r *recvWriter) putFloat32(v float32) *recvWriter {
+	binary.Write(&r.Buffer, binary.LittleEndian, v)
+	return r
+}
+
+func (r *recvWriter) putFloat64(v float64) *recvWriter {
+	binary.Write(&r.Buffer, binary.LittleEndian, v)
+	return r
+}
+
+func (r *recvWriter) putString(str string, size int) *recvWriter {
+	buf := make([]byte, size)
+	copy(buf[:size-1], str)
+	r.Write(buf)
+	return r
+}
+
+func (r *recvWriter) putBytes(b []byte) *recvWriter {
+	r.Write(b)
+	return r
+}
+
+func (r *recvWriter) bytes() []byte {
+	buf := r.Bytes()
+	binary.LittleEndian.PutUint32(buf, uint32(len(buf)))
+	return buf
+}
+
+func recvOpen() []byte {
+	return newRecv(sim.SIMCONNECT_RECV_ID_OPEN).
+		putString("simconnecttest", 256).
+		putUint32(11).putUint32(0).putUint32(0).putUint32(0). // application version and build
+		putUint32(4).putUint32(0).putUint32(0).putUint32(0).  // SimConnect version and build
+		putUint32(0).putUint32(0).
+		bytes()
+}
+
+func recvQuit() []byte {
+	return newRecv(sim.SIMCONNECT_RECV_ID_QUIT).bytes()
+}
+
+func recvException(exception uint32, sendID uint32, index uint32) []byte {
+	return newRecv(sim.SIMCONNECT_RECV_ID_EXCEPTION).
+		putUint32(exception).
+		putUint32(sendID).
+		putUint32(index).
+		bytes()
+}
+
+func recvEvent(groupID uint32, eventID uint32, data uint32) []byte {
+	return newRecv(sim.SIMCONNECT_RECV_ID_EVENT).
+		putUint32(groupID).
+		putUint32(eventID).
+		putUint32(data).
+		bytes()
+}
+
+func recvReservedKey(choice string, key string) []byte {
+	return newRecv(sim.SIMCONNECT_RECV_ID_RESERVED_KEY).
+		putString(choice, 30).
+		putString(key, 50).
+		bytes()
+}
+
+func recvEventFilename(groupID uint32, eventID uint32, filename string) []byte {
+	return newRecv(sim.SIMCONNECT_RECV_ID_EVENT_FILENAME).
+		putUint32(groupID).
+		putUint32(eventID).
+		putUint32(0).
+		putString(filename, sim.MAX_PATH).
+		putUint32(0).
+		bytes()
+}
+
+func recvEventFrame(groupID uint32, eventID uint32, frameRate float32, simSpeed float32) []byte {
+	return newRecv(sim.SIMCONNECT_RECV_ID_EVENT_FRAME).
+		putUint32(groupID).
+		putUint32(eventID).
+		putUint32(0).
+		putFloat32(frameRate).
+		putFloat32(simSpeed).
+		bytes()
+}
+
+func recvEventObjectAddRemove(groupID uint32, eventID uint32, objectID uint32, objectType uint32) []byte {
+	return newRecv(sim.SIMCONNECT_RECV_ID_EVENT_OBJECT_ADDREMOVE).
+		putUint32(groupID).
+		putUint32(eventID).
+		putUint32(objectID).
+		putUint32(objectType).
+		bytes()
+}
+
+// recvEventRace build SIMCONNECT_RECV_EVENT_RACE_END or SIMCONNECT_RECV_EVENT_RACE_LAP
+func recvEventRace(id uint32, groupID uint32, eventID uint32, number uint32, result sim.RaceResult) []byte {
+	r := newRecv(id).
+		putUint32(groupID).
+		putUint32(eventID).
+		putUint32(0).
+		putUint32(number).
+		putUint32(result.NumberOfRacers).
+		putUint32(uint32(result.MissionGUID.Data1))
+	guid := make([]byte, 4)
+	binary.LittleEndian.PutUint16(guid, result.MissionGUID.Data2)
+	binary.LittleEndian.PutUint16(guid[2:], result.MissionGUID.Data3)
+	r.putBytes(guid).putBytes(result.MissionGUID.Data4[:])
+	r.putString(result.PlayerName, sim.MAX_PATH).
+		putString(result.SessionType, sim.MAX_PATH).
+		putString(result.Aircraft, sim.MAX_PATH).
+		putString(result.PlayerRole, sim.MAX_PATH).
+		putFloat64(result.TotalTime).
+		putFloat64(result.PenaltyTime)
+	if result.IsDisqualified {
+		r.putUint32(1)
+	} else {
+		r.putUint32(0)
+	}
+	return r.bytes()
+}
+
+func recvSystemState(requestID uint32, integer uint32, float float32, str string) []byte {
+	return newRecv(sim.SIMCONNECT_RECV_ID_SYSTEM_STATE).
+		putUint32(requestID).
+		putUint32(integer).
+		putFloat32(float).
+		putString(str, sim.MAX_PATH).
+		bytes()
+}
+
+func recvAssignedObjectID(requestID uint32, objectID uint32) []byte {
+	return newRecv(sim.SIMCONNECT_RECV_ID_ASSIGNED_OBJECT_ID).
+		putUint32(requestID).
+		putUint32(objectID).
+		bytes()
+}
+
+func recvWeatherObservation(requestID uint32, metar string) []byte {
+	return newRecv(sim.SIMCONNECT_RECV_ID_WEATHER_OBSERVATION).
+		putUint32(requestID).
+		putBytes([]byte(metar + "\x00")).
+		bytes()
+}
+
+func recvCloudState(requestID uint32, data []byte) []byte {
+	return newRecv(sim.SIMCONNECT_RECV_ID_CLOUD_STATE).
+		putUint32(requestID).
+		putUint32(uint32(len(data))).
+		putBytes(data).
+		bytes()
+}
+
+func recvEventWeatherMode(groupID uint32, eventID uint32, mode uint32) []byte {
+	return newRecv(sim.SIMCONNECT_RECV_ID_EVENT_WEATHER_MODE).
+		putUint32(groupID).
+		putUint32(eventID).
+		putUint32(mode).
+		bytes()
+}
+
+// facilitiesRecvID is the message of each SIMCONNECT_FACILITY_LIST_TYPE
+var facilitiesRecvID = [...]uint32{
+	sim.SIMCONNECT_RECV_ID_AIRPORT_LIST,
+	sim.SIMCONNECT_RECV_ID_WAYPOINT_LIST,
+	sim.SIMCONNECT_RECV_ID_NDB_LIST,
+	sim.SIMCONNECT_RECV_ID_VOR_LIST,
+}
+
+func recvFacilitiesList(listType uint32, requestID uint32, entry uint32, outOf uint32, records [][]byte) []byte {
+	r := newRecv(facilitiesRecvID[listType]).
+		putUint32(requestID).
+		putUint32(uint32(len(records))).
+		putUint32(entry).
+		putUint32(outOf)
+	for _, record := range records {
+		r.putBytes(record)
+	}
+	return r.bytes()
+}
+
+func putAirport(r *recvWriter, airport sim.FacilityAirport) *recvWriter {
+	return r.putString(airport.ICAO, 9).
+		putFloat64(airport.Latitude).
+		putFloat64(airport.Longitude).
+		putFloat64(airport.Altitude)
+}
+
+func putWaypoint(r *recvWriter, waypoint sim.FacilityWaypoint) *recvWriter {
+	return putAirport(r, waypoint.FacilityAirport).putFloat32(waypoint.MagVar)
+}
+
+func putNDB(r *recvWriter, ndb sim.FacilityNDB) *recvWriter {
+	return putWaypoint(r, ndb.FacilityWaypoint).putUint32(ndb.Frequency)
+}
+
+func putVOR(r *recvWriter, vor sim.FacilityVOR) *recvWriter {
+	return putNDB(r, vor.FacilityNDB).
+		putUint32(vor.Flags).
+		putFloat32(vor.Localizer).
+		putFloat64(vor.GlideLat).
+		putFloat64(vor.GlideLon).
+		putFloat64(vor.GlideAlt).
+		putFloat32(vor.GlideSlopeAngle)
+}
+
+func recvSimObjectData(id uint32, requestID uint32, objectID uint32, defineID uint32, entry uint32, outOf uint32, count uint32, data []byte) []byte {
+	return newRecv(id).
+		putUint32(requestID).
+		putUint32(objectID).
+		putUint32(defineID).
+		putUint32(0).
+		putUint32(entry).
+		putUint32(outOf).
+		putUint32(count).
+		putBytes(data).
+		bytes()
+}
+
+// datumSize return the size in the data of a SIMCONNECT_DATATYPE
+func datumSize(datumType uint32) int {
+	switch datumType {
+	case sim.SIMCONNECT_DATATYPE_INT32, sim.SIMCONNECT_DATATYPE_FLOAT32:
+		return 4
+	case sim.SIMCONNECT_DATATYPE_STRING32:
+		return 32
+	case sim.SIMCONNECT_DATATYPE_STRING64:
+		return 64
+	case sim.SIMCONNECT_DATATYPE_STRING128:
+		return 128
+	case sim.SIMCONNECT_DATATYPE_STRING256:
+		return 256
+	case sim.SIMCONNECT_DATATYPE_STRING260:
+		return 260
+	case sim.SIMCONNECT_DATATYPE_INITPOSITION:
+		return int(unsafe.Sizeof(sim.SIMCONNECT_DATA_INITPOSITION{}))
+	case sim.SIMCONNECT_DATATYPE_MARKERSTATE:
+		return int(unsafe.Sizeof(sim.SIMCONNECT_DATA_MARKERSTATE{}))
+	case sim.SIMCONNECT_DATATYPE_WAYPOINT:
+		return int(unsafe.Sizeof(sim.SIMCONNECT_DATA_WAYPOINT{}))
+	case sim.SIMCONNECT_DATATYPE_LATLONALT:
+		return int(unsafe.Sizeof(sim.SIMCONNECT_DATA_LATLONALT{}))
+	case sim.SIMCONNECT_DATATYPE_XYZ:
+		return int(unsafe.Sizeof(sim.SIMCONNECT_DATA_XYZ{}))
+	}
+	return 8
+}
+
+func isStringDatum(datumType uint32) bool {
+	return datumType >= sim.SIMCONNECT_DATATYPE_STRING8 && datumType <= sim.SIMCONNECT_DATATYPE_STRINGV
+}
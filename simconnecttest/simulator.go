@@ -0,0 +1,1415 @@
+// Package simconnecttest provide a scripted fake simulator for testing the code using EasySimConnect without the simulator.
+//
+//	fake := simconnecttest.NewSimulator()
+//	fake.SetSimVar("PLANE ALTITUDE", 1000)
+//	esc := simconnect.NewEasySimConnectWithTransport(fake)
+//
+// The Simulator answer the data requests with the values set by the test, raise the exceptions of SimConnect
+// for the unknown SimVar and record the SimEvent and texts sent by the client.
+package simconnecttest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sim "github.com/micmonay/simconnect"
+)
+
+// SimEvent is an event executed by the simulator
+type SimEvent struct {
+	Name     sim.KeySimEvent
+	Value    int
+	ObjectID uint32
+}
+
+// userObjectID is the object ID of the user aircraft in the responses
+const userObjectID = 1
+
+// firstAIObjectID is the object ID of the first object created by the client
+const firstAIObjectID = 100
+
+// simObject is an AI object added by AddSimObject or created by the client
+type simObject struct {
+	objectType uint32
+	distance   float64
+	simVars    map[string]*simVarValue
+	ai         *AIObject // nil if the object is not created by the client
+}
+
+// AIObject is an object created by the AICreate* functions of the client
+type AIObject struct {
+	ObjectType   uint32 // SIMCONNECT_SIMOBJECT_TYPE_*
+	Title        string
+	TailNumber   string
+	AirportID    string
+	FlightNumber int
+	FlightPlan   string
+	Position     sim.SIMCONNECT_DATA_INITPOSITION
+	Released     bool
+}
+
+// SystemState is a state returned by RequestSystemState
+type SystemState struct {
+	Integer uint32
+	Float   float32
+	String  string
+}
+
+// notification is a client event in a notification group
+type notification struct {
+	groupID  uint32
+	maskable bool
+}
+
+// inputGroupState is the priority and the state of an input group
+type inputGroupState struct {
+	priority uint32
+	off      bool
+}
+
+// inputMapping is an input of the user mapped to the client events by MapInputEventToClientEvent
+type inputMapping struct {
+	down      uint32
+	downValue uint32
+	up        uint32
+	upValue   uint32
+	maskable  bool
+}
+
+type datum struct {
+	name      string
+	unit      string
+	datumType uint32
+}
+
+// dataRequest is a request of RequestDataOnSimObject sent at each period
+type dataRequest struct {
+	requestID  uint32
+	defineID   uint32
+	objectID   uint32
+	period     uint32
+	flags      uint32
+	origin     uint32
+	interval   uint32
+	limit      uint32
+	periods    uint32
+	sent       uint32
+	lastSecond time.Time
+	last       []byte
+}
+
+type simVarValue struct {
+	number float64
+	str    string
+	raw    []byte
+	units  []string
+}
+
+func (v *simVarValue) acceptUnit(unit string) bool {
+	if len(v.units) == 0 || unit == "" {
+		return true
+	}
+	for _, u := range v.units {
+		if strings.EqualFold(strings.Replace(u, " ", "", -1), strings.Replace(unit, " ", "", -1)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v *simVarValue) encode(datumType uint32) []byte {
+	size := datumSize(datumType)
+	buf := make([]byte, size)
+	switch {
+	case v.raw != nil:
+		copy(buf, v.raw)
+	case isStringDatum(datumType):
+		copy(buf[:size-1], v.str)
+	case datumType == sim.SIMCONNECT_DATATYPE_INT32:
+		binary.LittleEndian.PutUint32(buf, uint32(int32(v.number)))
+	case datumType == sim.SIMCONNECT_DATATYPE_INT64:
+		binary.LittleEndian.PutUint64(buf, uint64(int64(v.number)))
+	case datumType == sim.SIMCONNECT_DATATYPE_FLOAT32:
+		binary.LittleEndian.PutUint32(buf, math.Float32bits(float32(v.number)))
+	default:
+		binary.LittleEndian.PutUint64(buf, math.Float64bits(v.number))
+	}
+	return buf
+}
+
+func (v *simVarValue) decode(datumType uint32, buf []byte) {
+	switch {
+	case v.raw != nil:
+		v.raw = append([]byte{}, buf...)
+	case isStringDatum(datumType):
+		v.str = strings.TrimRight(string(buf), "\x00")
+	case datumType == sim.SIMCONNECT_DATATYPE_INT32:
+		v.number = float64(int32(binary.LittleEndian.Uint32(buf)))
+	case datumType == sim.SIMCONNECT_DATATYPE_INT64:
+		v.number = float64(int64(binary.LittleEndian.Uint64(buf)))
+	case datumType == sim.SIMCONNECT_DATATYPE_FLOAT32:
+		v.number = float64(math.Float32frombits(binary.LittleEndian.Uint32(buf)))
+	default:
+		v.number = math.Float64frombits(binary.LittleEndian.Uint64(buf))
+	}
+}
+
+// maxRadius is the maximum radius of RequestDataOnSimObjectType
+const maxRadius = 200000
+
+var (
+	_ sim.Transport                  = (*Simulator)(nil)
+	_ sim.DataTransport              = (*Simulator)(nil)
+	_ sim.SystemTransport            = (*Simulator)(nil)
+	_ sim.NotificationGroupTransport = (*Simulator)(nil)
+	_ sim.InputTransport             = (*Simulator)(nil)
+	_ sim.AITransport                = (*Simulator)(nil)
+	_ sim.WeatherTransport           = (*Simulator)(nil)
+	_ sim.FacilitiesTransport        = (*Simulator)(nil)
+)
+
+// Simulator is a fake simulator implementing simconnect.Transport
+type Simulator struct {
+	mutex         sync.Mutex
+	open          bool
+	appName       string
+	sendID        uint32
+	queue         [][]byte
+	simVars       map[string]*simVarValue
+	definitions   map[uint32][]datum
+	requests      map[uint32]*dataRequest
+	clientEvents  map[uint32]sim.KeySimEvent
+	groups        map[uint32]notification
+	priorities    map[uint32]sim.GroupPriority
+	inputs        map[uint32]map[string]inputMapping
+	inputState    map[uint32]inputGroupState
+	usedKeys      map[string]bool
+	reservedKeys  map[string]uint32
+	systemEvents  map[sim.SystemEvent][]uint32
+	objects       map[uint32]*simObject
+	objectID      uint32
+	titles        map[string]bool
+	states        map[sim.SystemEvent]uint32
+	systemStates  map[string]SystemState
+	responseTimes []float32
+	simEvents     []SimEvent
+	texts         []string
+	stations      map[string]*WeatherStation
+	globalMETAR   string
+	weatherMode   uint32
+	weatherTheme  string
+	weatherPort   uint32
+	weatherRate   uint32
+	cloudDensity  func(latitude float64, longitude float64) byte
+	thermals      map[uint32]sim.Thermal
+	facilities    map[uint32]*facilityList
+}
+
+// NewSimulator return a running Simulator (Sim = 1 and Pause = 0) without SimVar
+func NewSimulator() *Simulator {
+	return &Simulator{
+		simVars:      make(map[string]*simVarValue),
+		definitions:  make(map[uint32][]datum),
+		requests:     make(map[uint32]*dataRequest),
+		clientEvents: make(map[uint32]sim.KeySimEvent),
+		groups:       make(map[uint32]notification),
+		priorities:   make(map[uint32]sim.GroupPriority),
+		inputs:       make(map[uint32]map[string]inputMapping),
+		inputState:   make(map[uint32]inputGroupState),
+		usedKeys:     make(map[string]bool),
+		reservedKeys: make(map[string]uint32),
+		systemEvents: make(map[sim.SystemEvent][]uint32),
+		objects:      make(map[uint32]*simObject),
+		objectID:     firstAIObjectID - 1,
+		systemStates: make(map[string]SystemState),
+		stations:     make(map[string]*WeatherStation),
+		thermals:     make(map[uint32]sim.Thermal),
+		facilities:   make(map[uint32]*facilityList),
+		weatherMode:  sim.SIMCONNECT_WEATHER_MODE_GLOBAL,
+		states: map[sim.SystemEvent]uint32{
+			sim.SystemEventSim:   1,
+			sim.SystemEventPause: 0,
+		},
+	}
+}
+
+func (s *Simulator) push(record []byte) {
+	s.queue = append(s.queue, record)
+}
+
+// next return a new send ID, must be called with the lock
+func (s *Simulator) next() uint32 {
+	s.sendID++
+	return s.sendID
+}
+
+func (s *Simulator) simVar(name string) *simVarValue {
+	v, found := s.simVars[name]
+	if !found {
+		v = &simVarValue{}
+		s.simVars[name] = v
+	}
+	return v
+}
+
+// SetSimVar set the value of a numeric SimVar. The name must contain the index if needed (ex: "GENERAL ENG RPM:1")
+func (s *Simulator) SetSimVar(name string, value float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.simVar(name).number = value
+}
+
+// SetSimVarString set the value of a string SimVar (ex: "TITLE")
+func (s *Simulator) SetSimVarString(name string, value string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.simVar(name).str = value
+}
+
+// SetSimVarData set the value of a structure SimVar (ex: SIMCONNECT_DATA_LATLONALT), data is written in little endian
+func (s *Simulator) SetSimVarData(name string, data interface{}) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	buf := new(bytes.Buffer)
+	err := binary.Write(buf, binary.LittleEndian, data)
+	if err != nil {
+		return err
+	}
+	s.simVar(name).raw = buf.Bytes()
+	return nil
+}
+
+// SetUnits restrict the units accepted for a SimVar, AddToDataDefinition with another unit raise an exception
+func (s *Simulator) SetUnits(name string, units ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.simVar(name).units = units
+}
+
+// AddSimObject add an AI object (SIMCONNECT_SIMOBJECT_TYPE_*) at distance meters of the user aircraft, the
+// subscribers of ObjectAdded are notified. The SimVars of the object are set by SetObjectSimVar.
+func (s *Simulator) AddSimObject(objectID uint32, objectType uint32, distance float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.objects[objectID] = &simObject{objectType, distance, make(map[string]*simVarValue), nil}
+	for _, eventID := range s.systemEvents[sim.SystemEventObjectAdded] {
+		s.push(recvEventObjectAddRemove(0, eventID, objectID, objectType))
+	}
+}
+
+// RemoveSimObject remove an AI object, the subscribers of ObjectRemoved are notified
+func (s *Simulator) RemoveSimObject(objectID uint32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.removeObject(objectID)
+}
+
+// removeObject remove the object and notify ObjectRemoved, must be called with the lock
+func (s *Simulator) removeObject(objectID uint32) {
+	object, found := s.objects[objectID]
+	if !found {
+		return
+	}
+	delete(s.objects, objectID)
+	for _, eventID := range s.systemEvents[sim.SystemEventObjectRemoved] {
+		s.push(recvEventObjectAddRemove(0, eventID, objectID, object.objectType))
+	}
+}
+
+// SetContainerTitles restrict the titles of the objects created by the client, the other titles raise
+// SIMCONNECT_EXCEPTION_CREATE_OBJECT_FAILED. Without titles all are accepted.
+func (s *Simulator) SetContainerTitles(titles ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.titles = make(map[string]bool)
+	for _, title := range titles {
+		s.titles[title] = true
+	}
+}
+
+// AIObjects return the objects created by the client and not removed, by object ID
+func (s *Simulator) AIObjects() map[uint32]AIObject {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	objects := make(map[uint32]AIObject)
+	for objectID, object := range s.objects {
+		if object.ai != nil {
+			objects[objectID] = *object.ai
+		}
+	}
+	return objects
+}
+
+// createAI add an object created by the client and send his object ID, must be called with the lock
+func (s *Simulator) createAI(sendID uint32, requestID uint32, objectType uint32, ai AIObject) {
+	if len(s.titles) != 0 && !s.titles[ai.Title] {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_CREATE_OBJECT_FAILED, sendID, 1))
+		return
+	}
+	s.objectID++
+	ai.ObjectType = objectType
+	s.objects[s.objectID] = &simObject{objectType, 0, make(map[string]*simVarValue), &ai}
+	s.push(recvAssignedObjectID(requestID, s.objectID))
+	for _, eventID := range s.systemEvents[sim.SystemEventObjectAdded] {
+		s.push(recvEventObjectAddRemove(0, eventID, s.objectID, objectType))
+	}
+}
+
+// aiObject return the object created by the client or raise an exception, must be called with the lock
+func (s *Simulator) aiObject(sendID uint32, objectID uint32) *simObject {
+	object, found := s.objects[objectID]
+	if !found || object.ai == nil {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1))
+		return nil
+	}
+	return object
+}
+
+// SetObjectSimVar set the value of a numeric SimVar of an AI object, the object must be added by AddSimObject
+func (s *Simulator) SetObjectSimVar(objectID uint32, name string, value float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if v := s.objectSimVar(objectID, name); v != nil {
+		v.number = value
+	}
+}
+
+// isObject return true if the object is the user aircraft or an object added, must be called with the lock
+func (s *Simulator) isObject(objectID uint32) bool {
+	if objectID == sim.SIMCONNECT_OBJECT_ID_USER || objectID == userObjectID {
+		return true
+	}
+	_, found := s.objects[objectID]
+	return found
+}
+
+// ObjectSimVar return the value of a numeric SimVar of an object, ok is false if the object or the SimVar is unknown
+func (s *Simulator) ObjectSimVar(objectID uint32, name string) (value float64, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if objectID == sim.SIMCONNECT_OBJECT_ID_USER || objectID == userObjectID {
+		v, found := s.simVars[name]
+		if !found {
+			return 0, false
+		}
+		return v.number, true
+	}
+	object, found := s.objects[objectID]
+	if !found {
+		return 0, false
+	}
+	v, found := object.simVars[name]
+	if !found {
+		return 0, false
+	}
+	return v.number, true
+}
+
+// objectSimVar return the SimVar of an object or nil if the object is unknown, must be called with the lock
+func (s *Simulator) objectSimVar(objectID uint32, name string) *simVarValue {
+	if objectID == sim.SIMCONNECT_OBJECT_ID_USER || objectID == userObjectID {
+		return s.simVar(name)
+	}
+	object, found := s.objects[objectID]
+	if !found {
+		return nil
+	}
+	v, found := object.simVars[name]
+	if !found {
+		v = &simVarValue{}
+		object.simVars[name] = v
+	}
+	return v
+}
+
+// SimVar return the value of a numeric SimVar, ok is false if the SimVar is unknown
+func (s *Simulator) SimVar(name string) (value float64, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	v, found := s.simVars[name]
+	if !found {
+		return 0, false
+	}
+	return v.number, true
+}
+
+// SimVarString return the value of a string SimVar, ok is false if the SimVar is unknown
+func (s *Simulator) SimVarString(name string) (value string, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	v, found := s.simVars[name]
+	if !found {
+		return "", false
+	}
+	return v.str, true
+}
+
+// FireSystemEvent send the system event to the client if subscribed. The data is kept and sent immediately
+// on the next subscription for Pause, Sim, Sound and View like the simulator.
+func (s *Simulator) FireSystemEvent(name sim.SystemEvent, data uint32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.states[name] = data
+	for _, eventID := range s.systemEvents[name] {
+		s.push(recvEvent(0, eventID, data))
+	}
+}
+
+// FireSystemEventFilename send a system event with a filename (AircraftLoaded, FlightLoaded, FlightSaved, FlightPlanActivated)
+func (s *Simulator) FireSystemEventFilename(name sim.SystemEvent, filename string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, eventID := range s.systemEvents[name] {
+		s.push(recvEventFilename(0, eventID, filename))
+	}
+}
+
+// FireSystemEventFrame send a system event with the frame rate and the simulation speed (Frame, PauseFrame)
+func (s *Simulator) FireSystemEventFrame(name sim.SystemEvent, frameRate float32, simSpeed float32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, eventID := range s.systemEvents[name] {
+		s.push(recvEventFrame(0, eventID, frameRate, simSpeed))
+	}
+}
+
+// FireSystemEventObject send a system event with an object and its type SIMCONNECT_SIMOBJECT_TYPE_* (ObjectAdded, ObjectRemoved)
+func (s *Simulator) FireSystemEventObject(name sim.SystemEvent, objectID uint32, objectType uint32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	for _, eventID := range s.systemEvents[name] {
+		s.push(recvEventObjectAddRemove(0, eventID, objectID, objectType))
+	}
+}
+
+// FireSystemEventRace send the result of a racer (RaceEnd) or of a lap (RaceLap), number is the racer or the lap
+func (s *Simulator) FireSystemEventRace(name sim.SystemEvent, number uint32, result sim.RaceResult) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	id := uint32(sim.SIMCONNECT_RECV_ID_EVENT_RACE_LAP)
+	if name == sim.SystemEventRaceEnd {
+		id = sim.SIMCONNECT_RECV_ID_EVENT_RACE_END
+	}
+	for _, eventID := range s.systemEvents[name] {
+		s.push(recvEventRace(id, 0, eventID, number, result))
+	}
+}
+
+// TriggerSimEvent simulate a SimEvent done by the user in the simulator (ex: the gear lever). The notification groups of
+// the client receive the event in the order of priority. Return false if a group mask the event, then the event is not
+// recorded in SimEvents.
+func (s *Simulator) TriggerSimEvent(name sim.KeySimEvent, data uint32) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if !s.notify(name, data, sim.SIMCONNECT_GROUP_PRIORITY_HIGHEST) {
+		return false
+	}
+	s.simEvents = append(s.simEvents, SimEvent{name, int(data), sim.SIMCONNECT_OBJECT_ID_USER})
+	return true
+}
+
+// SetSystemState set the state returned by RequestSystemState (ex: "DialogMode", "AircraftLoaded")
+func (s *Simulator) SetSystemState(name string, state SystemState) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.systemStates[name] = state
+}
+
+// SetResponseTimes set the times in seconds returned by RequestResponseTimes, the first is the total round trip
+func (s *Simulator) SetResponseTimes(times ...float32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.responseTimes = times
+}
+
+// SetUsedKeys set the keys used by the bindings of the user, RequestReservedKey don't reserve them
+func (s *Simulator) SetUsedKeys(keys ...string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.usedKeys = make(map[string]bool)
+	for _, key := range keys {
+		s.usedKeys[strings.ToLower(key)] = true
+	}
+}
+
+// TriggerInput simulate the user pressing (down is true) or releasing a key or a joystick button. The input groups
+// which are on receive the client event of the input in the order of priority. Return false if a group mask the input.
+// A key reserved with RequestReservedKey is always masked and send his event when pressed.
+func (s *Simulator) TriggerInput(definition string, down bool) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	type target struct {
+		groupID  uint32
+		priority uint32
+		mapping  inputMapping
+	}
+	definition = strings.ToLower(definition)
+	if eventID, found := s.reservedKeys[definition]; found {
+		if down {
+			s.push(recvEvent(0, eventID, 0))
+		}
+		return false
+	}
+	targets := make([]target, 0)
+	for groupID, inputs := range s.inputs {
+		mapping, found := inputs[definition]
+		state := s.inputGroupState(groupID)
+		if !found || state.off {
+			continue
+		}
+		targets = append(targets, target{groupID, state.priority, mapping})
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].priority == targets[j].priority {
+			return targets[i].groupID < targets[j].groupID
+		}
+		return targets[i].priority < targets[j].priority
+	})
+	for _, t := range targets {
+		if down {
+			s.push(recvEvent(t.groupID, t.mapping.down, t.mapping.downValue))
+		} else if t.mapping.up != sim.SIMCONNECT_UNUSED {
+			s.push(recvEvent(t.groupID, t.mapping.up, t.mapping.upValue))
+		}
+		if t.mapping.maskable {
+			return false
+		}
+	}
+	return true
+}
+
+// RaiseException send a SIMCONNECT_RECV_EXCEPTION to the client
+func (s *Simulator) RaiseException(exception uint32, sendID uint32, index uint32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.push(recvException(exception, sendID, index))
+}
+
+// Push send a raw SIMCONNECT_RECV_* record to the client
+func (s *Simulator) Push(record []byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.push(record)
+}
+
+// Quit simulate the user closing the simulator
+func (s *Simulator) Quit() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.push(recvQuit())
+}
+
+// SimEvents return the events executed by the simulator, transmitted by the client or triggered with TriggerSimEvent.
+// The masked events are not executed.
+func (s *Simulator) SimEvents() []SimEvent {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]SimEvent{}, s.simEvents...)
+}
+
+// Texts return all the texts displayed by the client
+func (s *Simulator) Texts() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]string{}, s.texts...)
+}
+
+// IsSubscribed return true if the client is subscribed to the system event
+func (s *Simulator) IsSubscribed(name sim.SystemEvent) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.systemEvents[name]) > 0
+}
+
+// DataDefinitions return the number of data definitions of the client
+func (s *Simulator) DataDefinitions() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return len(s.definitions)
+}
+
+// AppName return the name given by the client on Open
+func (s *Simulator) AppName() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.appName
+}
+
+// IsOpen return true between Open and Close
+func (s *Simulator) IsOpen() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.open
+}
+
+// Open SimConnect_Open, the definitions and subscriptions of a previous connection are removed like after a restart
+// of the simulator
+func (s *Simulator) Open(appTitle string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	s.open = true
+	s.appName = appTitle
+	s.queue = nil
+	s.definitions = make(map[uint32][]datum)
+	s.requests = make(map[uint32]*dataRequest)
+	s.clientEvents = make(map[uint32]sim.KeySimEvent)
+	s.groups = make(map[uint32]notification)
+	s.priorities = make(map[uint32]sim.GroupPriority)
+	s.inputs = make(map[uint32]map[string]inputMapping)
+	s.inputState = make(map[uint32]inputGroupState)
+	s.reservedKeys = make(map[string]uint32)
+	s.systemEvents = make(map[sim.SystemEvent][]uint32)
+	s.push(recvOpen())
+	return nil
+}
+
+// Close SimConnect_Close
+func (s *Simulator) Close() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.open = false
+	return nil
+}
+
+// GetLastSentPacketID SimConnect_GetLastSentPacketID
+func (s *Simulator) GetLastSentPacketID() (uint32, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.sendID, nil
+}
+
+// GetNextDispatch SimConnect_GetNextDispatch, a frame is simulated when no message is waiting
+func (s *Simulator) GetNextDispatch() ([]byte, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.queue) == 0 {
+		s.frame()
+	}
+	if len(s.queue) == 0 {
+		return nil, errors.New("No dispatch")
+	}
+	record := s.queue[0]
+	s.queue = s.queue[1:]
+	return record, nil
+}
+
+// RequestResponseTimes SimConnect_RequestResponseTimes, the times are set by SetResponseTimes
+func (s *Simulator) RequestResponseTimes(nCount uint32, fElapsedSeconds []float32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	copy(fElapsedSeconds, s.responseTimes)
+	return nil
+}
+
+// MapClientEventToSimEvent SimConnect_MapClientEventToSimEvent
+func (s *Simulator) MapClientEventToSimEvent(EventID uint32, EventName string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	s.clientEvents[EventID] = sim.KeySimEvent(EventName)
+	return nil
+}
+
+// TransmitClientEvent SimConnect_TransmitClientEvent, the event is sent to the notification groups with a lower priority
+// and recorded if it is not masked
+func (s *Simulator) TransmitClientEvent(ObjectID uint32, EventID uint32, dwData int, GroupID sim.GroupPriority, Flags sim.EventFlag) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	name, found := s.clientEvents[EventID]
+	if !found {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 2))
+		return nil
+	}
+	priority := GroupID
+	if Flags&sim.SIMCONNECT_EVENT_FLAG_GROUPID_IS_PRIORITY == 0 {
+		priority = s.priorities[uint32(GroupID)]
+	}
+	if s.notify(name, uint32(dwData), priority) {
+		s.simEvents = append(s.simEvents, SimEvent{name, dwData, ObjectID})
+	}
+	return nil
+}
+
+// notify send the event to the notification groups with a priority lower or equal to priority, in the order of
+// priority. Return false if the event is masked by a group. Must be called with the lock.
+func (s *Simulator) notify(name sim.KeySimEvent, data uint32, priority sim.GroupPriority) bool {
+	type target struct {
+		eventID  uint32
+		groupID  uint32
+		priority sim.GroupPriority
+		maskable bool
+	}
+	targets := make([]target, 0)
+	for eventID, n := range s.groups {
+		groupPriority, found := s.priorities[n.groupID]
+		if s.clientEvents[eventID] != name || !found || groupPriority < priority {
+			continue
+		}
+		targets = append(targets, target{eventID, n.groupID, groupPriority, n.maskable})
+	}
+	sort.Slice(targets, func(i, j int) bool {
+		if targets[i].priority == targets[j].priority {
+			return targets[i].eventID < targets[j].eventID
+		}
+		return targets[i].priority < targets[j].priority
+	})
+	for _, t := range targets {
+		s.push(recvEvent(t.groupID, t.eventID, data))
+		if t.maskable && t.priority >= sim.SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE {
+			return false
+		}
+	}
+	return true
+}
+
+// AddClientEventToNotificationGroup SimConnect_AddClientEventToNotificationGroup
+func (s *Simulator) AddClientEventToNotificationGroup(GroupID uint32, EventID uint32, bMaskable bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	s.groups[EventID] = notification{GroupID, bMaskable}
+	return nil
+}
+
+// RemoveClientEvent SimConnect_RemoveClientEvent, raise SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID if the event is not in the group
+func (s *Simulator) RemoveClientEvent(GroupID uint32, EventID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	if n, found := s.groups[EventID]; !found || n.groupID != GroupID {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 2))
+		return nil
+	}
+	delete(s.groups, EventID)
+	return nil
+}
+
+// SetNotificationGroupPriority SimConnect_SetNotificationGroupPriority, the group receive the events only after this call
+func (s *Simulator) SetNotificationGroupPriority(GroupID uint32, uPriority sim.GroupPriority) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	s.priorities[GroupID] = uPriority
+	return nil
+}
+
+// ClearNotificationGroup SimConnect_ClearNotificationGroup, the priority of the group is kept
+func (s *Simulator) ClearNotificationGroup(GroupID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	for eventID, n := range s.groups {
+		if n.groupID == GroupID {
+			delete(s.groups, eventID)
+		}
+	}
+	return nil
+}
+
+// RequestNotificationGroup SimConnect_RequestNotificationGroup
+func (s *Simulator) RequestNotificationGroup(GroupID uint32, dwReserved uint32, Flags uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	return nil
+}
+
+// AddToDataDefinition SimConnect_AddToDataDefinition, raise SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED if the SimVar
+// is unknown or the unit not accepted
+func (s *Simulator) AddToDataDefinition(DefineID uint32, DatumName string, UnitsName string, DatumType uint32, fEpsilon float32, DatumID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	v, found := s.simVars[DatumName]
+	if !found {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED, sendID, 2))
+		return nil
+	}
+	if !v.acceptUnit(UnitsName) {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_NAME_UNRECOGNIZED, sendID, 3))
+		return nil
+	}
+	s.definitions[DefineID] = append(s.definitions[DefineID], datum{DatumName, UnitsName, DatumType})
+	return nil
+}
+
+// ClearDataDefinition SimConnect_ClearDataDefinition
+func (s *Simulator) ClearDataDefinition(DefineID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	delete(s.definitions, DefineID)
+	return nil
+}
+
+// data return the values of a definition in the SIMCONNECT_RECV_SIMOBJECT_DATA format, must be called with the lock
+// data return the values of the definition for the object or nil if the object is unknown
+func (s *Simulator) data(objectID uint32, definition []datum) []byte {
+	buf := make([]byte, 0)
+	for _, d := range definition {
+		v := s.objectSimVar(objectID, d.name)
+		if v == nil {
+			return nil
+		}
+		buf = append(buf, v.encode(d.datumType)...)
+	}
+	return buf
+}
+
+// RequestDataOnSimObject SimConnect_RequestDataOnSimObject, each call of GetNextDispatch without waiting message
+// is a visual frame and a sim frame
+func (s *Simulator) RequestDataOnSimObject(RequestID uint32, DefineID uint32, ObjectID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	if _, found := s.definitions[DefineID]; !found {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 2))
+		return nil
+	}
+	if !s.isObject(ObjectID) {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 3))
+		return nil
+	}
+	request := &dataRequest{
+		requestID: RequestID,
+		defineID:  DefineID,
+		objectID:  ObjectID,
+		period:    Period,
+		flags:     Flags,
+		origin:    origin,
+		interval:  interval,
+		limit:     limit,
+	}
+	switch Period {
+	case sim.SIMCONNECT_PERIOD_NEVER:
+		delete(s.requests, RequestID)
+	case sim.SIMCONNECT_PERIOD_ONCE:
+		delete(s.requests, RequestID)
+		s.sendRequest(request)
+	default:
+		s.requests[RequestID] = request
+	}
+	return nil
+}
+
+// frame send the data of the requests for this frame, must be called with the lock
+func (s *Simulator) frame() {
+	now := time.Now()
+	for requestID, request := range s.requests {
+		if request.period == sim.SIMCONNECT_PERIOD_SECOND {
+			if now.Sub(request.lastSecond) < time.Second {
+				continue
+			}
+			request.lastSecond = now
+		}
+		request.periods++
+		if request.periods <= request.origin || (request.periods-request.origin-1)%(request.interval+1) != 0 {
+			continue
+		}
+		if !s.sendRequest(request) {
+			continue
+		}
+		if request.limit != 0 && request.sent >= request.limit {
+			delete(s.requests, requestID)
+		}
+	}
+}
+
+// sendRequest push the data of the request and return true if sent, must be called with the lock
+func (s *Simulator) sendRequest(request *dataRequest) bool {
+	definition, found := s.definitions[request.defineID]
+	if !found {
+		return false
+	}
+	data := s.data(request.objectID, definition)
+	if data == nil {
+		return false
+	}
+	if request.flags&sim.SIMCONNECT_DATA_REQUEST_FLAG_CHANGED != 0 && request.last != nil && bytes.Equal(data, request.last) {
+		return false
+	}
+	request.last = data
+	request.sent++
+	objectID := request.objectID
+	if objectID == sim.SIMCONNECT_OBJECT_ID_USER {
+		objectID = userObjectID
+	}
+	s.push(recvSimObjectData(sim.SIMCONNECT_RECV_ID_SIMOBJECT_DATA, request.requestID, objectID, request.defineID, 0, 0, uint32(len(definition)), data))
+	return true
+}
+
+// RequestDataOnSimObjectType SimConnect_RequestDataOnSimObjectType, the user aircraft is at the distance 0 and the
+// objects are added by AddSimObject. Without object in the radius a message with dwoutof 0 is sent.
+func (s *Simulator) RequestDataOnSimObjectType(RequestID uint32, DefineID uint32, dwRadiusMeters uint32, t uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	definition, found := s.definitions[DefineID]
+	if !found {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 2))
+		return nil
+	}
+	if dwRadiusMeters > maxRadius {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_OUT_OF_BOUNDS, sendID, 3))
+		return nil
+	}
+	objectIDs := []uint32{}
+	if dwRadiusMeters == 0 || t == sim.SIMCONNECT_SIMOBJECT_TYPE_USER || t == sim.SIMCONNECT_SIMOBJECT_TYPE_ALL || t == sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT {
+		objectIDs = append(objectIDs, userObjectID)
+	}
+	if dwRadiusMeters != 0 && t != sim.SIMCONNECT_SIMOBJECT_TYPE_USER {
+		for objectID, object := range s.objects {
+			if object.distance <= float64(dwRadiusMeters) && (t == sim.SIMCONNECT_SIMOBJECT_TYPE_ALL || t == object.objectType) {
+				objectIDs = append(objectIDs, objectID)
+			}
+		}
+	}
+	sort.Slice(objectIDs, func(i, j int) bool { return objectIDs[i] < objectIDs[j] })
+	if len(objectIDs) == 0 {
+		s.push(recvSimObjectData(sim.SIMCONNECT_RECV_ID_SIMOBJECT_DATA_BYTYPE, RequestID, 0, DefineID, 0, 0, 0, nil))
+		return nil
+	}
+	for i, objectID := range objectIDs {
+		data := s.data(objectID, definition)
+		s.push(recvSimObjectData(sim.SIMCONNECT_RECV_ID_SIMOBJECT_DATA_BYTYPE, RequestID, objectID, DefineID, uint32(i+1), uint32(len(objectIDs)), uint32(len(definition)), data))
+	}
+	return nil
+}
+
+// SetDataOnSimObject SimConnect_SetDataOnSimObject, the values are written in the SimVars
+func (s *Simulator) SetDataOnSimObject(DefineID uint32, ObjectID uint32, Flags uint32, ArrayCount uint32, cbUnitSize uint32, pDataSet []byte) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	definition, found := s.definitions[DefineID]
+	if !found {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1))
+		return nil
+	}
+	if !s.isObject(ObjectID) {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 2))
+		return nil
+	}
+	position := 0
+	for _, d := range definition {
+		size := datumSize(d.datumType)
+		if position+size > len(pDataSet) {
+			s.push(recvException(sim.SIMCONNECT_EXCEPTION_INVALID_DATA_SIZE, sendID, 6))
+			return nil
+		}
+		s.objectSimVar(ObjectID, d.name).decode(d.datumType, pDataSet[position:position+size])
+		position += size
+	}
+	return nil
+}
+
+// MapInputEventToClientEvent SimConnect_MapInputEventToClientEvent, the inputs are not case sensitive
+func (s *Simulator) MapInputEventToClientEvent(GroupID uint32, szInputDefinition string, DownEventID uint32, DownValue uint32, UpEventID uint32, UpValue uint32, bMaskable bool) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	if s.inputs[GroupID] == nil {
+		s.inputs[GroupID] = make(map[string]inputMapping)
+	}
+	s.inputs[GroupID][strings.ToLower(szInputDefinition)] = inputMapping{DownEventID, DownValue, UpEventID, UpValue, bMaskable}
+	return nil
+}
+
+// inputGroupState return the state of the input group, a new group is on with the default priority. Must be called
+// with the lock.
+func (s *Simulator) inputGroupState(groupID uint32) inputGroupState {
+	state, found := s.inputState[groupID]
+	if !found {
+		state.priority = uint32(sim.SIMCONNECT_GROUP_PRIORITY_DEFAULT)
+	}
+	return state
+}
+
+// SetInputGroupPriority SimConnect_SetInputGroupPriority
+func (s *Simulator) SetInputGroupPriority(GroupID uint32, uPriority uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	state := s.inputGroupState(GroupID)
+	state.priority = uPriority
+	s.inputState[GroupID] = state
+	return nil
+}
+
+// RemoveInputEvent SimConnect_RemoveInputEvent, raise SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID if the input is not in the group
+func (s *Simulator) RemoveInputEvent(GroupID uint32, szInputDefinition string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	definition := strings.ToLower(szInputDefinition)
+	if _, found := s.inputs[GroupID][definition]; !found {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 2))
+		return nil
+	}
+	delete(s.inputs[GroupID], definition)
+	return nil
+}
+
+// ClearInputGroup SimConnect_ClearInputGroup
+func (s *Simulator) ClearInputGroup(GroupID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	delete(s.inputs, GroupID)
+	return nil
+}
+
+// SetInputGroupState SimConnect_SetInputGroupState, raise SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID if the group has no input
+func (s *Simulator) SetInputGroupState(GroupID uint32, dwState sim.SimConnectStat) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	if _, found := s.inputs[GroupID]; !found {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1))
+		return nil
+	}
+	state := s.inputGroupState(GroupID)
+	state.off = dwState == sim.SIMCONNECT_STATE_OFF
+	s.inputState[GroupID] = state
+	return nil
+}
+
+// RequestReservedKey SimConnect_RequestReservedKey, the first choice not used by the user (see SetUsedKeys) or by another reservation is sent back in
+// SIMCONNECT_RECV_RESERVED_KEY, the key is empty if all the choices are used
+func (s *Simulator) RequestReservedKey(EventID uint32, szKeyChoice1 string, szKeyChoice2 string, szKeyChoice3 string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	for _, choice := range []string{szKeyChoice1, szKeyChoice2, szKeyChoice3} {
+		key := strings.ToLower(choice)
+		if _, reserved := s.reservedKeys[key]; choice == "" || s.usedKeys[key] || reserved {
+			continue
+		}
+		s.reservedKeys[key] = EventID
+		s.push(recvReservedKey(choice, choice))
+		return nil
+	}
+	s.push(recvReservedKey("", ""))
+	return nil
+}
+
+// SubscribeToSystemEvent SimConnect_SubscribeToSystemEvent, Pause, Sim, Sound and View send immediately the current state
+func (s *Simulator) SubscribeToSystemEvent(EventID uint32, SystemEventName sim.SystemEvent) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	s.systemEvents[SystemEventName] = append(s.systemEvents[SystemEventName], EventID)
+	switch SystemEventName {
+	case sim.SystemEventPause, sim.SystemEventSim, sim.SystemEventSound, sim.SystemEventView:
+		if state, found := s.states[SystemEventName]; found {
+			s.push(recvEvent(0, EventID, state))
+		}
+	}
+	return nil
+}
+
+// UnsubscribeFromSystemEvent SimConnect_UnsubscribeFromSystemEvent
+func (s *Simulator) UnsubscribeFromSystemEvent(EventID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	for name, eventIDs := range s.systemEvents {
+		for i, eventID := range eventIDs {
+			if eventID == EventID {
+				s.systemEvents[name] = append(eventIDs[:i:i], eventIDs[i+1:]...)
+				return nil
+			}
+		}
+	}
+	s.push(recvException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1))
+	return nil
+}
+
+// WeatherRequestInterpolatedObservation SimConnect_WeatherRequestInterpolatedObservation, the METAR of the nearest station or the global METAR
+func (s *Simulator) WeatherRequestInterpolatedObservation(RequestID uint32, lat float32, lon float32, alt float32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sendObservation(s.next(), RequestID, s.nearestStation(lat, lon, true))
+	return nil
+}
+
+// WeatherRequestObservationAtStation SimConnect_WeatherRequestObservationAtStation, raise SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_GET_OBSERVATION for an unknown station
+func (s *Simulator) WeatherRequestObservationAtStation(RequestID uint32, szICAO string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sendObservation(s.next(), RequestID, s.stations[strings.ToUpper(szICAO)])
+	return nil
+}
+
+// WeatherRequestObservationAtNearestStation SimConnect_WeatherRequestObservationAtNearestStation
+func (s *Simulator) WeatherRequestObservationAtNearestStation(RequestID uint32, lat float32, lon float32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.sendObservation(s.next(), RequestID, s.nearestStation(lat, lon, false))
+	return nil
+}
+
+// WeatherCreateStation SimConnect_WeatherCreateStation, an existing ICAO raise SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_CREATE_STATION
+func (s *Simulator) WeatherCreateStation(RequestID uint32, szICAO string, szName string, lat float32, lon float32, alt float32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	icao := strings.ToUpper(szICAO)
+	if _, found := s.stations[icao]; found {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_CREATE_STATION, sendID, 2))
+		return nil
+	}
+	s.stations[icao] = &WeatherStation{ICAO: icao, Name: szName, Latitude: float64(lat), Longitude: float64(lon), Altitude: float64(alt), Created: true, Located: true}
+	return nil
+}
+
+// WeatherRemoveStation SimConnect_WeatherRemoveStation, only the stations created by the client can be removed
+func (s *Simulator) WeatherRemoveStation(RequestID uint32, szICAO string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	icao := strings.ToUpper(szICAO)
+	if station, found := s.stations[icao]; !found || !station.Created {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_REMOVE_STATION, sendID, 2))
+		return nil
+	}
+	delete(s.stations, icao)
+	return nil
+}
+
+// WeatherSetObservation SimConnect_WeatherSetObservation, the first word is the ICAO of the station or GLOB for the global weather
+func (s *Simulator) WeatherSetObservation(Seconds uint32, szMETAR string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.setObservation(s.next(), szMETAR)
+	return nil
+}
+
+// WeatherSetModeServer SimConnect_WeatherSetModeServer, the port 0 raise SIMCONNECT_EXCEPTION_WEATHER_INVALID_PORT
+func (s *Simulator) WeatherSetModeServer(dwPort uint32, dwSeconds uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	if dwPort == 0 {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_WEATHER_INVALID_PORT, sendID, 1))
+		return nil
+	}
+	s.weatherPort = dwPort
+	return nil
+}
+
+// WeatherSetModeTheme SimConnect_WeatherSetModeTheme
+func (s *Simulator) WeatherSetModeTheme(szThemeName string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	s.weatherTheme = szThemeName
+	s.setWeatherMode(sim.SIMCONNECT_WEATHER_MODE_THEME)
+	return nil
+}
+
+// WeatherSetModeGlobal SimConnect_WeatherSetModeGlobal
+func (s *Simulator) WeatherSetModeGlobal() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	s.setWeatherMode(sim.SIMCONNECT_WEATHER_MODE_GLOBAL)
+	return nil
+}
+
+// WeatherSetModeCustom SimConnect_WeatherSetModeCustom
+func (s *Simulator) WeatherSetModeCustom() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	s.setWeatherMode(sim.SIMCONNECT_WEATHER_MODE_CUSTOM)
+	return nil
+}
+
+// WeatherSetDynamicUpdateRate SimConnect_WeatherSetDynamicUpdateRate
+func (s *Simulator) WeatherSetDynamicUpdateRate(dwRate uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	s.weatherRate = dwRate
+	return nil
+}
+
+// WeatherRequestCloudState SimConnect_WeatherRequestCloudState, the densities come from SetCloudDensity and a zone with min >= max raise SIMCONNECT_EXCEPTION_OUT_OF_BOUNDS
+func (s *Simulator) WeatherRequestCloudState(RequestID uint32, minLat float32, minLon float32, minAlt float32, maxLat float32, maxLon float32, maxAlt float32, dwFlags uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	if minLat >= maxLat || minLon >= maxLon || minAlt > maxAlt {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_OUT_OF_BOUNDS, sendID, 2))
+		return nil
+	}
+	s.push(recvCloudState(RequestID, s.cloudState(minLat, minLon, maxLat, maxLon)))
+	return nil
+}
+
+// WeatherCreateThermal SimConnect_WeatherCreateThermal, the thermals are returned by Thermals and a radius or a rate out of the limits raise SIMCONNECT_EXCEPTION_OUT_OF_BOUNDS
+func (s *Simulator) WeatherCreateThermal(RequestID uint32, lat float32, lon float32, alt float32, radius float32, height float32, coreRate float32, coreTurbulence float32, sinkRate float32, sinkTurbulence float32, coreSize float32, coreTransitionSize float32, sinkLayerSize float32, sinkTransitionSize float32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	if radius <= 0 || radius > sim.MAX_THERMAL_SIZE {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_OUT_OF_BOUNDS, sendID, 5))
+		return nil
+	}
+	if coreRate > sim.MAX_THERMAL_RATE || sinkRate > sim.MAX_THERMAL_RATE {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_OUT_OF_BOUNDS, sendID, 7))
+		return nil
+	}
+	s.objectID++
+	s.thermals[s.objectID] = sim.Thermal{Latitude: lat, Longitude: lon, Altitude: alt, Radius: radius, Height: height, CoreRate: sim.Float32(coreRate), CoreTurbulence: sim.Float32(coreTurbulence), SinkRate: sim.Float32(sinkRate), SinkTurbulence: sim.Float32(sinkTurbulence), CoreSize: sim.Float32(coreSize), CoreTransitionSize: sim.Float32(coreTransitionSize), SinkLayerSize: sim.Float32(sinkLayerSize), SinkTransitionSize: sim.Float32(sinkTransitionSize)}
+	s.push(recvAssignedObjectID(RequestID, s.objectID))
+	return nil
+}
+
+// WeatherRemoveThermal SimConnect_WeatherRemoveThermal, an unknown thermal raise SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID
+func (s *Simulator) WeatherRemoveThermal(ObjectID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	if _, found := s.thermals[ObjectID]; !found {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_UNRECOGNIZED_ID, sendID, 1))
+		return nil
+	}
+	delete(s.thermals, ObjectID)
+	return nil
+}
+
+// RequestSystemState SimConnect_RequestSystemState, the states are set by SetSystemState
+func (s *Simulator) RequestSystemState(RequestID uint32, szState string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	state := s.systemStates[szState]
+	s.push(recvSystemState(RequestID, state.Integer, state.Float, state.String))
+	return nil
+}
+
+// Text SimConnect_Text, the text is recorded and SIMCONNECT_TEXT_RESULT_DISPLAYED then SIMCONNECT_TEXT_RESULT_REMOVED
+// are sent back
+func (s *Simulator) Text(t uint32, fTimeSeconds float32, EventID uint32, pDataSet string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.next()
+	s.texts = append(s.texts, pDataSet)
+	s.push(recvEvent(0, EventID, sim.SIMCONNECT_TEXT_RESULT_DISPLAYED))
+	s.push(recvEvent(0, EventID, sim.SIMCONNECT_TEXT_RESULT_REMOVED))
+	return nil
+}
+
+// AICreateParkedATCAircraft SimConnect_AICreateParkedATCAircraft, the objects are returned by AIObjects
+func (s *Simulator) AICreateParkedATCAircraft(szContainerTitle string, szTailNumber string, szAirportID string, RequestID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.createAI(s.next(), RequestID, sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, AIObject{Title: szContainerTitle, TailNumber: szTailNumber, AirportID: szAirportID})
+	return nil
+}
+
+// AICreateEnrouteATCAircraft SimConnect_AICreateEnrouteATCAircraft, the objects are returned by AIObjects
+func (s *Simulator) AICreateEnrouteATCAircraft(szContainerTitle string, szTailNumber string, iFlightNumber int, szFlightPlanPath string, dFlightPlanPosition float64, bTouchAndGo uint32, RequestID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.createAI(s.next(), RequestID, sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, AIObject{Title: szContainerTitle, TailNumber: szTailNumber, FlightNumber: iFlightNumber, FlightPlan: szFlightPlanPath})
+	return nil
+}
+
+// AICreateNonATCAircraft SimConnect_AICreateNonATCAircraft, the objects are returned by AIObjects
+func (s *Simulator) AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, InitPos sim.SIMCONNECT_DATA_INITPOSITION, RequestID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.createAI(s.next(), RequestID, sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, AIObject{Title: szContainerTitle, TailNumber: szTailNumber, Position: InitPos})
+	return nil
+}
+
+// AICreateSimulatedObject SimConnect_AICreateSimulatedObject, the object is a ground vehicle returned by AIObjects
+func (s *Simulator) AICreateSimulatedObject(szContainerTitle string, InitPos sim.SIMCONNECT_DATA_INITPOSITION, RequestID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.createAI(s.next(), RequestID, sim.SIMCONNECT_SIMOBJECT_TYPE_GROUND, AIObject{Title: szContainerTitle, Position: InitPos})
+	return nil
+}
+
+// AIReleaseControl SimConnect_AIReleaseControl
+func (s *Simulator) AIReleaseControl(ObjectID uint32, RequestID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if object := s.aiObject(s.next(), ObjectID); object != nil {
+		object.ai.Released = true
+	}
+	return nil
+}
+
+// AIRemoveObject SimConnect_AIRemoveObject, only the objects created by the client can be removed
+func (s *Simulator) AIRemoveObject(ObjectID uint32, RequestID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if object := s.aiObject(s.next(), ObjectID); object != nil {
+		s.removeObject(ObjectID)
+	}
+	return nil
+}
+
+// AISetAircraftFlightPlan SimConnect_AISetAircraftFlightPlan
+func (s *Simulator) AISetAircraftFlightPlan(ObjectID uint32, szFlightPlanPath string, RequestID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if object := s.aiObject(s.next(), ObjectID); object != nil {
+		object.ai.FlightPlan = szFlightPlanPath
+	}
+	return nil
+}
+
+// SubscribeToFacilities SimConnect_SubscribeToFacilities, the current list is sent then the facilities added by SetAirports, SetWaypoints, SetNDBs and SetVORs
+func (s *Simulator) SubscribeToFacilities(listType uint32, RequestID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	if listType >= sim.SIMCONNECT_FACILITY_LIST_TYPE_COUNT {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_INVALID_ENUM, sendID, 1))
+		return nil
+	}
+	list := s.facilityList(listType)
+	list.subscribed = true
+	list.requestID = RequestID
+	s.sendFacilities(listType, RequestID, list.sorted())
+	return nil
+}
+
+// UnsubscribeToFacilities SimConnect_UnsubscribeToFacilities
+func (s *Simulator) UnsubscribeToFacilities(listType uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	if listType >= sim.SIMCONNECT_FACILITY_LIST_TYPE_COUNT {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_INVALID_ENUM, sendID, 1))
+		return nil
+	}
+	s.facilityList(listType).subscribed = false
+	return nil
+}
+
+// RequestFacilitiesList SimConnect_RequestFacilitiesList, the list is chopped in messages of facilitiesPerMessage facilities
+func (s *Simulator) RequestFacilitiesList(listType uint32, RequestID uint32) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	sendID := s.next()
+	if listType >= sim.SIMCONNECT_FACILITY_LIST_TYPE_COUNT {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_INVALID_ENUM, sendID, 1))
+		return nil
+	}
+	s.sendFacilities(listType, RequestID, s.facilityList(listType).sorted())
+	return nil
+}
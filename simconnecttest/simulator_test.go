@@ -0,0 +1,1851 @@
+package simconnecttest_test
+
+import (
+	"context"
+	"errors"
+	"math"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sim "github.com/micmonay/simconnect"
+	"github.com/micmonay/simconnect/simconnecttest"
+)
+
+func connect(t *testing.T, transport sim.Transport) *sim.EasySimConnect {
+	esc := sim.NewEasySimConnectWithTransport(transport)
+	esc.SetDelay(10 * time.Millisecond)
+	c, err := esc.Connect("TestApp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !<-c {
+		t.Fatal("not connected")
+	}
+	return esc
+}
+
+// waitUntil poll the condition until the timeout, return false if the condition is still false
+func waitUntil(timeout time.Duration, condition func() bool) bool {
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	return true
+}
+
+// failingSimulator is a Simulator returning an error for the functions in fail, slow for the functions in delay and
+// counting their calls
+type failingSimulator struct {
+	*simconnecttest.Simulator
+	mutex sync.Mutex
+	fail  map[string]bool
+	delay map[string]time.Duration
+	calls map[string]int
+}
+
+func newFailingSimulator() *failingSimulator {
+	return &failingSimulator{
+		Simulator: simconnecttest.NewSimulator(),
+		fail:      make(map[string]bool),
+		delay:     make(map[string]time.Duration),
+		calls:     make(map[string]int),
+	}
+}
+
+func (s *failingSimulator) setDelay(name string, delay time.Duration) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.delay[name] = delay
+}
+
+func (s *failingSimulator) count(name string) int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.calls[name]
+}
+
+func (s *failingSimulator) setFail(name string, fail bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.fail[name] = fail
+}
+
+func (s *failingSimulator) err(name string) error {
+	s.mutex.Lock()
+	s.calls[name]++
+	delay := s.delay[name]
+	s.mutex.Unlock()
+	time.Sleep(delay)
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.fail[name] {
+		return errors.New(name + " failed")
+	}
+	return nil
+}
+
+func (s *failingSimulator) AIRemoveObject(ObjectID uint32, RequestID uint32) error {
+	if err := s.err("AIRemoveObject"); err != nil {
+		return err
+	}
+	return s.Simulator.AIRemoveObject(ObjectID, RequestID)
+}
+
+func (s *failingSimulator) WeatherRemoveStation(RequestID uint32, szICAO string) error {
+	if err := s.err("WeatherRemoveStation"); err != nil {
+		return err
+	}
+	return s.Simulator.WeatherRemoveStation(RequestID, szICAO)
+}
+
+func (s *failingSimulator) WeatherCreateStation(RequestID uint32, szICAO string, szName string, lat float32, lon float32, alt float32) error {
+	if err := s.err("WeatherCreateStation"); err != nil {
+		return err
+	}
+	return s.Simulator.WeatherCreateStation(RequestID, szICAO, szName, lat, lon, alt)
+}
+
+func (s *failingSimulator) WeatherCreateThermal(RequestID uint32, lat float32, lon float32, alt float32, radius float32, height float32, coreRate float32, coreTurbulence float32, sinkRate float32, sinkTurbulence float32, coreSize float32, coreTransitionSize float32, sinkLayerSize float32, sinkTransitionSize float32) error {
+	if err := s.err("WeatherCreateThermal"); err != nil {
+		return err
+	}
+	return s.Simulator.WeatherCreateThermal(RequestID, lat, lon, alt, radius, height, coreRate, coreTurbulence, sinkRate, sinkTurbulence, coreSize, coreTransitionSize, sinkLayerSize, sinkTransitionSize)
+}
+
+func (s *failingSimulator) SetDataOnSimObject(DefineID uint32, ObjectID uint32, Flags uint32, ArrayCount uint32, cbUnitSize uint32, pDataSet []byte) error {
+	if err := s.err("SetDataOnSimObject"); err != nil {
+		return err
+	}
+	return s.Simulator.SetDataOnSimObject(DefineID, ObjectID, Flags, ArrayCount, cbUnitSize, pDataSet)
+}
+
+func (s *failingSimulator) WeatherRemoveThermal(ObjectID uint32) error {
+	if err := s.err("WeatherRemoveThermal"); err != nil {
+		return err
+	}
+	return s.Simulator.WeatherRemoveThermal(ObjectID)
+}
+
+func (s *failingSimulator) SubscribeToFacilities(listType uint32, RequestID uint32) error {
+	if err := s.err("SubscribeToFacilities"); err != nil {
+		return err
+	}
+	return s.Simulator.SubscribeToFacilities(listType, RequestID)
+}
+
+func (s *failingSimulator) TransmitClientEvent(ObjectID uint32, EventID uint32, dwData int, GroupID sim.GroupPriority, Flags sim.EventFlag) error {
+	if err := s.err("TransmitClientEvent"); err != nil {
+		return err
+	}
+	return s.Simulator.TransmitClientEvent(ObjectID, EventID, dwData, GroupID, Flags)
+}
+
+func (s *failingSimulator) AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, InitPos sim.SIMCONNECT_DATA_INITPOSITION, RequestID uint32) error {
+	if err := s.err("AICreateNonATCAircraft"); err != nil {
+		return err
+	}
+	return s.Simulator.AICreateNonATCAircraft(szContainerTitle, szTailNumber, InitPos, RequestID)
+}
+
+func (s *failingSimulator) MapClientEventToSimEvent(EventID uint32, EventName string) error {
+	if err := s.err("MapClientEventToSimEvent"); err != nil {
+		return err
+	}
+	return s.Simulator.MapClientEventToSimEvent(EventID, EventName)
+}
+
+func (s *failingSimulator) AddClientEventToNotificationGroup(GroupID uint32, EventID uint32, bMaskable bool) error {
+	if err := s.err("AddClientEventToNotificationGroup"); err != nil {
+		return err
+	}
+	return s.Simulator.AddClientEventToNotificationGroup(GroupID, EventID, bMaskable)
+}
+
+func (s *failingSimulator) RemoveClientEvent(GroupID uint32, EventID uint32) error {
+	if err := s.err("RemoveClientEvent"); err != nil {
+		return err
+	}
+	return s.Simulator.RemoveClientEvent(GroupID, EventID)
+}
+
+func TestConnectToSimVar(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetSimVar("PLANE ALTITUDE", 1000)
+	fake.SetSimVar("GENERAL ENG RPM:1", 2400)
+	fake.SetSimVarString("TITLE", "Cessna 152")
+	esc := connect(t, fake)
+	defer esc.Close()
+	if fake.AppName() != "TestApp" {
+		t.Errorf("AppName = %q", fake.AppName())
+	}
+
+	cSimVar, err := esc.ConnectToSimVar(sim.SimVarPlaneAltitude(), sim.SimVarGeneralEngRpm(1), sim.SimVarTitle())
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := <-cSimVar
+	if f, _ := result[0].GetFloat64(); f != 1000 {
+		t.Errorf("PLANE ALTITUDE = %f, want 1000", f)
+	}
+	if f, _ := result[1].GetFloat64(); f != 2400 {
+		t.Errorf("GENERAL ENG RPM:1 = %f, want 2400", f)
+	}
+	if str := result[2].GetString(); str != "Cessna 152" {
+		t.Errorf("TITLE = %q, want Cessna 152", str)
+	}
+
+	fake.SetSimVar("PLANE ALTITUDE", 2000)
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case result = <-cSimVar:
+		case <-timeout:
+			t.Fatal("timeout waiting new value")
+		}
+		if f, _ := result[0].GetFloat64(); f == 2000 {
+			return
+		}
+	}
+}
+
+func TestConnectToSimVarByType(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetSimVar("PLANE ALTITUDE", 1000)
+	fake.AddSimObject(10, sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, 1000)
+	fake.SetObjectSimVar(10, "PLANE ALTITUDE", 5000)
+	fake.AddSimObject(11, sim.SIMCONNECT_SIMOBJECT_TYPE_HELICOPTER, 1000)
+	fake.AddSimObject(12, sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, 100000)
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	cTraffic, err := esc.ConnectToSimVarByType(50000, sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, sim.SimVarPlaneAltitude())
+	if err != nil {
+		t.Fatal(err)
+	}
+	objects := <-cTraffic
+	if len(objects) != 2 {
+		t.Fatalf("got %d objects, want the user and 10", len(objects))
+	}
+	if f, _ := objects[1][0].GetFloat64(); f != 1000 {
+		t.Errorf("user PLANE ALTITUDE = %f, want 1000", f)
+	}
+	if f, _ := objects[10][0].GetFloat64(); f != 5000 {
+		t.Errorf("10 PLANE ALTITUDE = %f, want 5000", f)
+	}
+	fake.RemoveSimObject(10)
+	timeout := time.After(time.Second)
+	for len(objects) != 1 {
+		select {
+		case objects = <-cTraffic:
+		case <-timeout:
+			t.Fatal("timeout waiting the removed object")
+		}
+	}
+
+	cGround, err := esc.ConnectToSimVarByType(50000, sim.SIMCONNECT_SIMOBJECT_TYPE_GROUND, sim.SimVarPlaneAltitude())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if objects := <-cGround; len(objects) != 0 {
+		t.Errorf("got %d ground objects, want 0", len(objects))
+	}
+	if _, err := esc.ConnectToSimVarByType(300000, sim.SIMCONNECT_SIMOBJECT_TYPE_ALL, sim.SimVarPlaneAltitude()); err == nil {
+		t.Error("want error for a radius bigger than 200000")
+	}
+}
+
+func TestConnectToSimVarException(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetSimVar("PLANE ALTITUDE", 1000)
+	fake.SetUnits("PLANE ALTITUDE", "Feet")
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	_, err := esc.ConnectToSimVar(sim.SimVarPlaneAltitude(sim.UnitFeet), sim.SimVarPlaneLatitude())
+	if !errors.Is(err, sim.ErrNameUnrecognized) {
+		t.Errorf("want ErrNameUnrecognized for unknown SimVar, got %v", err)
+	}
+	var scErr *sim.SimConnectError
+	if !errors.As(err, &scErr) {
+		t.Fatalf("want *SimConnectError, got %T", err)
+	}
+	if scErr.Op != "AddToDataDefinition" || scErr.Detail != "PLANE LATITUDE" || scErr.Param != 2 {
+		t.Errorf("got %#v", scErr)
+	}
+	_, err = esc.ConnectToSimVar(sim.SimVarPlaneAltitude(sim.UnitMeters))
+	if !errors.As(err, &scErr) || scErr.Param != 3 {
+		t.Errorf("want error on the unit for bad unit, got %v", err)
+	}
+	if fake.DataDefinitions() != 0 {
+		t.Errorf("DataDefinitions = %d, want 0 after the errors", fake.DataDefinitions())
+	}
+	_, err = esc.ConnectToSimVar(sim.SimVarPlaneAltitude(sim.UnitFeet))
+	if err != nil {
+		t.Error(err)
+	}
+}
+
+func TestErrors(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	simVar := sim.SimVarPlaneAltitude()
+	simVar.SetFloat64(1000)
+	if err := esc.SetSimObjectErr(simVar); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-esc.Errors():
+		var scErr *sim.SimConnectError
+		if !errors.As(err, &scErr) || !errors.Is(err, sim.ErrNameUnrecognized) {
+			t.Fatalf("got %v", err)
+		}
+		if scErr.Op != "AddToDataDefinition" || scErr.Detail != "PLANE ALTITUDE" {
+			t.Errorf("got %#v", scErr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting the error")
+	}
+}
+
+func TestSystemEvent(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	cPause := esc.ConnectSysEventPause()
+	if <-cPause {
+		t.Error("want current state unpaused")
+	}
+	cCrashed := esc.ConnectSysEventCrashed()
+	cFlightLoaded := esc.ConnectSysEventFlightLoaded()
+	time.Sleep(50 * time.Millisecond)
+
+	fake.FireSystemEvent(sim.SystemEventPause, 1)
+	if !<-cPause {
+		t.Error("want paused")
+	}
+	fake.FireSystemEvent(sim.SystemEventCrashed, 0)
+	<-cCrashed
+	fake.FireSystemEventFilename(sim.SystemEventFlightLoaded, "flights/other/FLT.FLT")
+	if filename := <-cFlightLoaded; filename != "flights/other/FLT.FLT" {
+		t.Errorf("FlightLoaded = %q", filename)
+	}
+}
+
+func TestSystemEventTyped(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.FireSystemEvent(sim.SystemEventView, sim.SIMCONNECT_VIEW_SYSTEM_EVENT_DATA_COCKPIT_VIRTUAL)
+	fake.FireSystemEvent(sim.SystemEventSound, sim.SIMCONNECT_SOUND_SYSTEM_EVENT_DATA_MASTER)
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	cView := esc.ConnectSysEventView()
+	if view := <-cView; !view.CockpitVirtual() || view.Cockpit2D() || view.Orthogonal() {
+		t.Errorf("View = %d, want virtual cockpit", view)
+	}
+	if !<-esc.ConnectSysEventSound() {
+		t.Error("want master sound on")
+	}
+	cFrame := esc.ConnectSysEventFrame()
+	cAdded := esc.ConnectSysEventObjectAdded()
+	cWeather := esc.ConnectSysEventWeatherModeChanged()
+	cRaceEnd := esc.ConnectSysEventRaceEnd()
+	c1sec := esc.ConnectSysEvent1sec()
+	time.Sleep(50 * time.Millisecond)
+
+	fake.FireSystemEventFrame(sim.SystemEventFrame, 59.5, 2)
+	if frame := <-cFrame; frame.FrameRate != 59.5 || frame.SimSpeed != 2 {
+		t.Errorf("Frame = %#v", frame)
+	}
+	fake.FireSystemEventObject(sim.SystemEventObjectAdded, 42, sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT)
+	if object := <-cAdded; object.ObjectID != 42 || object.ObjectType != sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT {
+		t.Errorf("ObjectAdded = %#v", object)
+	}
+	fake.FireSystemEvent(sim.SystemEventWeatherModeChanged, sim.SIMCONNECT_WEATHER_MODE_CUSTOM)
+	if mode := <-cWeather; mode != sim.SIMCONNECT_WEATHER_MODE_CUSTOM {
+		t.Errorf("WeatherModeChanged = %d", mode)
+	}
+	result := sim.RaceResult{
+		NumberOfRacers: 3,
+		MissionGUID:    sim.GUID{Data1: 1, Data2: 2, Data3: 3, Data4: [8]byte{4}},
+		PlayerName:     "Orville",
+		SessionType:    "LAN",
+		Aircraft:       "Wright Flyer",
+		TotalTime:      12.5,
+		PenaltyTime:    1,
+		IsDisqualified: true,
+	}
+	fake.FireSystemEventRace(sim.SystemEventRaceEnd, 2, result)
+	if race := <-cRaceEnd; race.RacerNumber != 2 || race.RacerData != result {
+		t.Errorf("RaceEnd = %#v", race)
+	}
+	fake.FireSystemEvent(sim.SystemEvent1sec, 0)
+	<-c1sec
+}
+
+func TestPerformanceMonitor(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetResponseTimes(0.02, 0.001, 0.005, 0.015, 0.02)
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	monitor := esc.NewPerformanceMonitor(sim.PerformanceOptions{Interval: 50 * time.Millisecond, LowFPS: 25})
+	defer monitor.Close()
+	for i := 0; i < 99; i++ {
+		fake.FireSystemEventFrame(sim.SystemEventFrame, 60, 1)
+	}
+	fake.FireSystemEventFrame(sim.SystemEventFrame, 10, 2)
+	var stats sim.PerformanceStats
+	for stats = range monitor.Updates() {
+		if stats.Frames == 100 && stats.Latency > 0 {
+			break
+		}
+	}
+	if stats.FPS != 10 || stats.FPSMin != 10 || stats.FPSMax != 60 || stats.FPSMedian != 60 || stats.FPSLow1 != 10 {
+		t.Errorf("FPS = %v min %v max %v median %v 1%% %v", stats.FPS, stats.FPSMin, stats.FPSMax, stats.FPSMedian, stats.FPSLow1)
+	}
+	if stats.FPSAverage != 59.5 || stats.LowFPS {
+		t.Errorf("FPSAverage = %v LowFPS = %v", stats.FPSAverage, stats.LowFPS)
+	}
+	if stats.Stutters != 1 || stats.SimSpeed != 2 || stats.SimSpeedChanges != 1 {
+		t.Errorf("Stutters = %d SimSpeed = %v SimSpeedChanges = %d", stats.Stutters, stats.SimSpeed, stats.SimSpeedChanges)
+	}
+	if len(stats.ResponseTimes) != 5 || stats.ResponseTimes[0] != 0.02 {
+		t.Errorf("ResponseTimes = %v", stats.ResponseTimes)
+	}
+	monitor.Close()
+	if _, ok := <-monitor.Updates(); ok {
+		t.Error("want Updates closed")
+	}
+}
+
+func TestSimEvent(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	throttleSet := esc.NewSimEvent(sim.KeyThrottleSet)
+	if value := <-throttleSet.RunWithValue(8000); value != 8000 {
+		t.Errorf("echo = %d, want 8000", value)
+	}
+	events := fake.SimEvents()
+	if len(events) != 1 || events[0].Name != sim.KeyThrottleSet || events[0].Value != 8000 {
+		t.Errorf("SimEvents = %#v", events)
+	}
+
+	// each run receive his confirmation, a canceled run don't take the confirmation of another run
+	ctx, cancel := context.WithCancel(context.Background())
+	canceled := throttleSet
+	canceled.Value = 1000
+	cCanceled := canceled.RunContext(ctx)
+	cancel()
+	if value := <-throttleSet.RunWithValue(2000); value != 2000 {
+		t.Errorf("echo = %d, want 2000", value)
+	}
+	if value, ok := <-cCanceled; ok && value != 1000 {
+		t.Errorf("canceled echo = %d, want 1000 or nothing", value)
+	}
+	first, second := throttleSet.RunWithValue(3000), throttleSet.RunWithValue(4000)
+	if value := <-second; value != 4000 {
+		t.Errorf("second echo = %d, want 4000", value)
+	}
+	if value := <-first; value != 3000 {
+		t.Errorf("first echo = %d, want 3000", value)
+	}
+}
+
+func TestSimEventError(t *testing.T) {
+	fake := newFailingSimulator()
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	throttleSet := esc.NewSimEvent(sim.KeyThrottleSet)
+	fake.setFail("TransmitClientEvent", true)
+	if value, ok := <-throttleSet.RunWithValue(1000); ok {
+		t.Errorf("echo of a failed run = %d", value)
+	}
+	select {
+	case err := <-esc.Errors():
+		if !strings.Contains(err.Error(), "TransmitClientEvent") {
+			t.Errorf("err = %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("no error of TransmitClientEvent")
+	}
+	// the next run receive his confirmation
+	fake.setFail("TransmitClientEvent", false)
+	select {
+	case value := <-throttleSet.RunWithValue(2000):
+		if value != 2000 {
+			t.Errorf("echo = %d, want 2000", value)
+		}
+	case <-time.After(time.Second):
+		t.Error("no echo after a failed run")
+	}
+}
+
+func TestNotificationGroup(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	gear, err := esc.NewNotificationGroup(sim.SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := gear.AddSimEvent(sim.KeyGearToggle, true); err != nil {
+		t.Fatal(err)
+	}
+	monitor, err := esc.NewNotificationGroup(sim.SIMCONNECT_GROUP_PRIORITY_STANDARD)
+	if err != nil {
+		t.Fatal(err)
+	}
+	monitor.AddSimEvent(sim.KeyGearToggle, false)
+	monitor.AddSimEvent(sim.KeyFlapsIncr, false)
+	if err := monitor.AddSimEvent(sim.KeyFlapsIncr, false); err == nil {
+		t.Error("want error for a SimEvent added twice")
+	}
+	read := func(group *sim.NotificationGroup) sim.GroupEvent {
+		select {
+		case event := <-group.Events():
+			return event
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting the event")
+		}
+		return sim.GroupEvent{}
+	}
+
+	if fake.TriggerSimEvent(sim.KeyGearToggle, 1) {
+		t.Error("GEAR_TOGGLE must be masked by the group")
+	}
+	if event := read(gear); event != (sim.GroupEvent{Mapping: sim.KeyGearToggle, Value: 1}) {
+		t.Errorf("event = %#v", event)
+	}
+	if !fake.TriggerSimEvent(sim.KeyFlapsIncr, 0) {
+		t.Error("FLAPS_INCR must not be masked")
+	}
+	// the monitor don't receive the masked GEAR_TOGGLE
+	if event := read(monitor); event.Mapping != sim.KeyFlapsIncr {
+		t.Errorf("event = %#v, want FLAPS_INCR", event)
+	}
+	if events := fake.SimEvents(); len(events) != 1 || events[0].Name != sim.KeyFlapsIncr {
+		t.Errorf("SimEvents = %#v", events)
+	}
+
+	if err := gear.RemoveSimEvent(sim.KeyGearToggle); err != nil {
+		t.Fatal(err)
+	}
+	if !fake.TriggerSimEvent(sim.KeyGearToggle, 0) {
+		t.Error("GEAR_TOGGLE must not be masked after RemoveSimEvent")
+	}
+	if event := read(monitor); event.Mapping != sim.KeyGearToggle {
+		t.Errorf("event = %#v, want GEAR_TOGGLE", event)
+	}
+
+	monitor.Close()
+	if _, ok := <-monitor.Events(); ok {
+		t.Error("Events must be closed")
+	}
+	if !fake.TriggerSimEvent(sim.KeyFlapsIncr, 0) {
+		t.Error("FLAPS_INCR must not be masked")
+	}
+	if err := monitor.AddSimEvent(sim.KeyFlapsIncr, false); err == nil {
+		t.Error("want error after Close")
+	}
+}
+
+func TestNotificationGroupAddError(t *testing.T) {
+	fake := newFailingSimulator()
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	group, err := esc.NewNotificationGroup(sim.SIMCONNECT_GROUP_PRIORITY_HIGHEST_MASKABLE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer group.Close()
+	fake.setFail("MapClientEventToSimEvent", true)
+	if err := group.AddSimEvent(sim.KeyGearToggle, true); err == nil {
+		t.Error("want error when MapClientEventToSimEvent fail")
+	}
+	if n := fake.count("RemoveClientEvent"); n != 0 {
+		t.Errorf("RemoveClientEvent called %d times for an event not mapped", n)
+	}
+	fake.setFail("MapClientEventToSimEvent", false)
+	fake.setFail("AddClientEventToNotificationGroup", true)
+	if err := group.AddSimEvent(sim.KeyGearToggle, true); err == nil {
+		t.Error("want error when AddClientEventToNotificationGroup fail")
+	}
+	if n := fake.count("RemoveClientEvent"); n != 1 {
+		t.Errorf("RemoveClientEvent called %d times, want 1 for the mapped event", n)
+	}
+
+	// the failed SimEvent is not in the group, it can be added again
+	fake.setFail("AddClientEventToNotificationGroup", false)
+	if err := group.AddSimEvent(sim.KeyGearToggle, true); err != nil {
+		t.Fatal(err)
+	}
+	if fake.TriggerSimEvent(sim.KeyGearToggle, 1) {
+		t.Error("GEAR_TOGGLE must be masked by the group")
+	}
+	select {
+	case event := <-group.Events():
+		if event != (sim.GroupEvent{Mapping: sim.KeyGearToggle, Value: 1}) {
+			t.Errorf("event = %#v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting the event")
+	}
+}
+
+func TestWatchSimEvents(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	if _, err := esc.WatchSimEvents(); err == nil {
+		t.Error("want error without KeySimEvent")
+	}
+	cKey, err := esc.WatchSimEvents(sim.KeyApMaster, sim.KeyFlapsIncr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Now()
+	fake.TriggerSimEvent(sim.KeyApMaster, 0)
+	fake.TriggerSimEvent(sim.KeyGearToggle, 0)
+	fake.TriggerSimEvent(sim.KeyFlapsIncr, 2)
+	for _, want := range []sim.KeyEvent{{Key: sim.KeyApMaster}, {Key: sim.KeyFlapsIncr, Value: 2}} {
+		select {
+		case event := <-cKey:
+			if event.Key != want.Key || event.Value != want.Value || event.Time.Before(start) {
+				t.Errorf("event = %#v, want %s", event, want.Key)
+			}
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting the event")
+		}
+	}
+	if events := fake.SimEvents(); len(events) != 3 {
+		t.Errorf("the events must not be masked, SimEvents = %#v", events)
+	}
+
+	sub, err := esc.Subscription(cKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub.Unsubscribe()
+	if _, ok := <-cKey; ok {
+		t.Error("chan must be closed")
+	}
+}
+
+func TestInputGroup(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	inputs := esc.NewInputGroup(sim.SIMCONNECT_GROUP_PRIORITY_HIGHEST)
+	cEvent := make(chan sim.InputEvent, 2)
+	err := inputs.Bind("shift+ctrl+g", true, func(event sim.InputEvent) {
+		cEvent <- event
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := inputs.Bind("ctrl+shift+G", false, func(sim.InputEvent) {}); err == nil {
+		t.Error("want error for an input bound twice")
+	}
+	cButton, err := inputs.BindChan("joystick:0:button:3", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wait := func(c <-chan sim.InputEvent) sim.InputEvent {
+		select {
+		case event := <-c:
+			return event
+		case <-time.After(time.Second):
+			t.Fatal("timeout waiting the input")
+		}
+		return sim.InputEvent{}
+	}
+
+	if fake.TriggerInput("Shift+Ctrl+G", true) {
+		t.Error("the input must be masked")
+	}
+	fake.TriggerInput("Shift+Ctrl+G", false)
+	if event := wait(cEvent); event.Input != "Shift+Ctrl+G" || !event.Down {
+		t.Errorf("event = %#v, want down", event)
+	}
+	if event := wait(cEvent); event.Down {
+		t.Errorf("event = %#v, want up", event)
+	}
+	if !fake.TriggerInput("joystick:0:button:3", true) {
+		t.Error("the input must not be masked")
+	}
+	if event := wait(cButton); event.Input != "joystick:0:button:3" || !event.Down {
+		t.Errorf("event = %#v", event)
+	}
+
+	if err := inputs.Disable(); err != nil {
+		t.Fatal(err)
+	}
+	if !fake.TriggerInput("Shift+Ctrl+G", true) {
+		t.Error("a disabled group must not mask the input")
+	}
+	inputs.Enable()
+	if err := inputs.Unbind("shift+ctrl+G"); err != nil {
+		t.Fatal(err)
+	}
+	if !fake.TriggerInput("Shift+Ctrl+G", true) {
+		t.Error("the input must not be masked after Unbind")
+	}
+	if len(cEvent) != 0 {
+		t.Error("no event after Disable or Unbind")
+	}
+
+	inputs.Close()
+	if _, ok := <-cButton; ok {
+		t.Error("the chan of BindChan must be closed")
+	}
+	if err := inputs.Bind("A", false, func(sim.InputEvent) {}); err == nil {
+		t.Error("want error after Close")
+	}
+	select {
+	case err := <-esc.Errors():
+		t.Errorf("unexpected exception %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestReserveKey(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetUsedKeys("q")
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	key, cKey, err := esc.ReserveKey("q", "Ctrl+q", "Alt+q")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "Ctrl+q" {
+		t.Errorf("key = %q, want Ctrl+q", key)
+	}
+	if _, _, err := esc.ReserveKey("q", "Ctrl+q"); err == nil {
+		t.Error("want error when all the keys are used")
+	}
+	if _, _, err := esc.ReserveKey(); err == nil {
+		t.Error("want error without choice")
+	}
+	if fake.TriggerInput("Ctrl+q", true) {
+		t.Error("a reserved key must be masked")
+	}
+	select {
+	case event := <-cKey:
+		if event.Input != "Ctrl+q" || !event.Down {
+			t.Errorf("event = %#v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting the key")
+	}
+	sub, err := esc.Subscription(cKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sub.Unsubscribe()
+	if _, ok := <-cKey; ok {
+		t.Error("chan must be closed")
+	}
+}
+
+func TestReserveKeyReconnect(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	esc := sim.NewEasySimConnectWithTransport(fake)
+	esc.SetDelay(10 * time.Millisecond)
+	esc.SetReconnect(10*time.Millisecond, 50*time.Millisecond)
+	c, err := esc.Connect("TestApp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-c
+	defer esc.Close()
+
+	key, cKey, err := esc.ReserveKey("q", "Ctrl+q")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key != "q" {
+		t.Errorf("key = %q, want q", key)
+	}
+	// the user bind q during the disconnection
+	fake.Quit()
+	if <-c {
+		t.Fatal("want false after quit")
+	}
+	fake.SetUsedKeys("q")
+	if !<-c {
+		t.Fatal("want true after reconnection")
+	}
+	// the new key is known when the response of the simulator is read
+	if !waitUntil(time.Second, func() bool {
+		if fake.TriggerInput("Ctrl+q", true) {
+			return false
+		}
+		select {
+		case event := <-cKey:
+			return event.Input == "Ctrl+q"
+		case <-time.After(100 * time.Millisecond):
+			return false
+		}
+	}) {
+		t.Error("the events of Ctrl+q don't have the key of the new connection")
+	}
+}
+
+func TestSetSimObject(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetSimVar("PLANE ALTITUDE", 1000)
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	altitude := sim.SimVarPlaneAltitude()
+	altitude.SetFloat64(6000)
+	esc.SetSimObject(altitude)
+	if f, _ := fake.SimVar("PLANE ALTITUDE"); f != 6000 {
+		t.Errorf("PLANE ALTITUDE = %f, want 6000", f)
+	}
+}
+
+func TestSetSimObjectConcurrent(t *testing.T) {
+	fake := newFailingSimulator()
+	fake.SetSimVar("PLANE ALTITUDE", 1000)
+	fake.SetSimVar("PLANE HEADING DEGREES TRUE", 0)
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	// the SimVars use the same data definition one after the other
+	fake.setDelay("SetDataOnSimObject", 20*time.Millisecond)
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			altitude := sim.SimVarPlaneAltitude()
+			altitude.SetFloat64(6000)
+			if err := esc.SetSimObjectErr(altitude); err != nil {
+				t.Error(err)
+			}
+		}()
+		go func() {
+			defer wg.Done()
+			heading := sim.SimVarPlaneHeadingDegreesTrue()
+			heading.SetFloat64(90)
+			if err := esc.SetSimObjectErr(heading); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if f, _ := fake.SimVar("PLANE ALTITUDE"); f != 6000 {
+		t.Errorf("PLANE ALTITUDE = %f, want 6000", f)
+	}
+	if f, _ := fake.SimVar("PLANE HEADING DEGREES TRUE"); f != 90 {
+		t.Errorf("PLANE HEADING DEGREES TRUE = %f, want 90", f)
+	}
+	select {
+	case err := <-esc.Errors():
+		t.Errorf("unexpected exception %v", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
+
+func TestSimVarByID(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetSimVar("PLANE ALTITUDE", 1000)
+	fake.AddSimObject(20, sim.SIMCONNECT_SIMOBJECT_TYPE_AIRCRAFT, 5000)
+	fake.SetObjectSimVar(20, "PLANE ALTITUDE", 3000)
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	cSimVar, err := esc.ConnectToSimVarByID(20, sim.SimVarPlaneAltitude())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, _ := (<-cSimVar)[0].GetFloat64(); f != 3000 {
+		t.Errorf("PLANE ALTITUDE of 20 = %f, want 3000", f)
+	}
+	altitude := sim.SimVarPlaneAltitude()
+	altitude.SetFloat64(4000)
+	if err := esc.SetSimObjectByID(20, altitude); err != nil {
+		t.Fatal(err)
+	}
+	if f, _ := fake.ObjectSimVar(20, "PLANE ALTITUDE"); f != 4000 {
+		t.Errorf("PLANE ALTITUDE of 20 = %f, want 4000", f)
+	}
+	if f, _ := fake.SimVar("PLANE ALTITUDE"); f != 1000 {
+		t.Errorf("user PLANE ALTITUDE = %f, want 1000", f)
+	}
+	timeout := time.After(time.Second)
+	for {
+		select {
+		case result := <-cSimVar:
+			if f, _ := result[0].GetFloat64(); f != 4000 {
+				continue
+			}
+		case <-timeout:
+			t.Fatal("timeout waiting new value")
+		}
+		break
+	}
+
+	gearUp := esc.NewSimEvent(sim.KeyGearUp)
+	<-gearUp.RunOnObject(20, 0)
+	events := fake.SimEvents()
+	if len(events) != 1 || events[0].Name != sim.KeyGearUp || events[0].ObjectID != 20 {
+		t.Errorf("SimEvents = %#v", events)
+	}
+
+	// the request of an unknown object is refused by the simulator
+	if _, err := esc.ConnectToSimVarByID(99, sim.SimVarPlaneAltitude()); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-esc.Errors():
+		var scErr *sim.SimConnectError
+		if !errors.As(err, &scErr) || scErr.Code != sim.ErrUnrecognizedID || scErr.Op != "RequestDataOnSimObject" {
+			t.Errorf("err = %v, want ErrUnrecognizedID in RequestDataOnSimObject", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("timeout waiting the exception")
+	}
+}
+
+func TestAIManager(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetContainerTitles("Cessna Skyhawk", "Boat")
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	ai := esc.NewAIManager()
+	parked, err := ai.CreateParkedATCAircraft("Cessna Skyhawk", "N1234", "KSEA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	position := sim.SIMCONNECT_DATA_INITPOSITION{Latitude: 47.45, Longitude: -122.3, Altitude: 500, Heading: 90}
+	boat, err := ai.CreateSimulatedObject("Boat", position)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ai.CreateNonATCAircraft("Unknown", "N0", position); !errors.Is(err, sim.ErrCreateObjectFailed) {
+		t.Errorf("err = %v, want ErrCreateObjectFailed", err)
+	}
+	if err := ai.SetFlightPlan(parked, "flights/KSEA-KPDX"); err != nil {
+		t.Error(err)
+	}
+	if err := ai.ReleaseControl(99); !errors.Is(err, sim.ErrUnrecognizedID) {
+		t.Errorf("err = %v, want ErrUnrecognizedID", err)
+	}
+	objects := fake.AIObjects()
+	if len(objects) != 2 || objects[parked].AirportID != "KSEA" || objects[parked].FlightPlan != "flights/KSEA-KPDX" || objects[boat].Position != position {
+		t.Errorf("AIObjects = %#v", objects)
+	}
+	if ids := ai.Objects(); len(ids) != 2 || ids[0] != parked || ids[1] != boat {
+		t.Errorf("Objects = %v", ids)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if live := ai.LiveObjects(); len(live) != 2 || live[boat] != sim.SIMCONNECT_SIMOBJECT_TYPE_GROUND {
+		t.Errorf("LiveObjects = %v", live)
+	}
+
+	// an object removed by the simulator is forgotten
+	fake.RemoveSimObject(boat)
+	time.Sleep(50 * time.Millisecond)
+	if ids := ai.Objects(); len(ids) != 1 || ids[0] != parked {
+		t.Errorf("Objects = %v", ids)
+	}
+	if err := ai.Close(); err != nil {
+		t.Error(err)
+	}
+	if objects := fake.AIObjects(); len(objects) != 0 {
+		t.Errorf("AIObjects after Close = %#v", objects)
+	}
+}
+
+func TestAIManagerRemoveError(t *testing.T) {
+	fake := newFailingSimulator()
+	fake.SetContainerTitles("Boat")
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	ai := esc.NewAIManager()
+	boat, err := ai.CreateSimulatedObject("Boat", sim.SIMCONNECT_DATA_INITPOSITION{Latitude: 47.45, Longitude: -122.3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a failed remove keep the object for Close
+	fake.setFail("AIRemoveObject", true)
+	if err := ai.Remove(boat); err == nil {
+		t.Error("want error of AIRemoveObject")
+	}
+	if ids := ai.Objects(); len(ids) != 1 || ids[0] != boat {
+		t.Errorf("Objects after error = %v", ids)
+	}
+	fake.setFail("AIRemoveObject", false)
+	if err := ai.Close(); err != nil {
+		t.Error(err)
+	}
+	if objects := fake.AIObjects(); len(objects) != 0 {
+		t.Errorf("AIObjects after Close = %#v", objects)
+	}
+}
+
+func TestAIManagerCloseDuringCreate(t *testing.T) {
+	fake := newFailingSimulator()
+	fake.SetContainerTitles("Cessna")
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	ai := esc.NewAIManager()
+	fake.setDelay("AICreateNonATCAircraft", 200*time.Millisecond)
+	cCreated := make(chan error, 1)
+	go func() {
+		_, err := ai.CreateNonATCAircraft("Cessna", "N1234", sim.SIMCONNECT_DATA_INITPOSITION{Latitude: 47.45, Longitude: -122.3})
+		cCreated <- err
+	}()
+	if !waitUntil(time.Second, func() bool { return fake.count("AICreateNonATCAircraft") == 1 }) {
+		t.Fatal("no AICreateNonATCAircraft")
+	}
+	// Close wait the creation in progress and remove his object
+	if err := ai.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := <-cCreated; err != nil {
+		t.Error(err)
+	}
+	if objects := fake.AIObjects(); len(objects) != 0 {
+		t.Errorf("AIObjects after Close = %#v", objects)
+	}
+	if _, err := ai.CreateNonATCAircraft("Cessna", "N1234", sim.SIMCONNECT_DATA_INITPOSITION{}); err == nil {
+		t.Error("want error after Close")
+	}
+}
+
+func TestTrafficInjector(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetContainerTitles("Traffic")
+	for _, name := range []string{"PLANE LATITUDE", "PLANE LONGITUDE", "PLANE ALTITUDE", "PLANE HEADING DEGREES TRUE"} {
+		fake.SetSimVar(name, 0)
+	}
+	esc := connect(t, fake)
+	defer esc.Close()
+	file, err := os.Open("../testdata/traffic.sbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	injector := esc.NewTrafficInjector(sim.TrafficOptions{Title: "Traffic", Interval: 20 * time.Millisecond, StaleAfter: 2500 * time.Millisecond})
+	defer injector.Close()
+	// the messages of the file are 1.9s from the first position to the last
+	start := time.Now()
+	if err := injector.Replay(context.Background(), sim.NewSBSTrafficFeed(file)); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 1800*time.Millisecond {
+		t.Errorf("Replay in %v, want the time of the file", elapsed)
+	}
+	contacts := injector.Contacts()
+	if len(contacts) != 2 {
+		t.Fatalf("Contacts = %v", contacts)
+	}
+	ryr := contacts["4CA2D6"]
+	if object := fake.AIObjects()[ryr]; object.Title != "Traffic" || object.TailNumber != "RYR1234" || object.Position.Altitude != 35000 {
+		t.Errorf("AIObject = %#v", object)
+	}
+	// 3C6586 is created at his first position and land at the last
+	if object := fake.AIObjects()[contacts["3C6586"]]; object.TailNumber != "3C6586" || object.Position.Altitude != 2500 {
+		t.Errorf("AIObject = %#v", object)
+	}
+
+	// the aircraft move to the east from the last position of the feed
+	if !waitUntil(time.Second, func() bool {
+		longitude, _ := fake.ObjectSimVar(ryr, "PLANE LONGITUDE")
+		return longitude > -6.2603
+	}) {
+		t.Error("the aircraft don't move")
+	}
+	if longitude, _ := fake.ObjectSimVar(ryr, "PLANE LONGITUDE"); longitude > -6.25 {
+		t.Errorf("longitude = %v", longitude)
+	}
+	if latitude, _ := fake.ObjectSimVar(ryr, "PLANE LATITUDE"); math.Abs(latitude-53.3498) > 1e-6 {
+		t.Errorf("latitude = %v", latitude)
+	}
+	if heading, _ := fake.ObjectSimVar(ryr, "PLANE HEADING DEGREES TRUE"); heading != 90 {
+		t.Errorf("heading = %v", heading)
+	}
+	if altitude, _ := fake.ObjectSimVar(ryr, "PLANE ALTITUDE"); altitude >= 35000 || altitude < 34900 {
+		t.Errorf("altitude = %v", altitude)
+	}
+	// 3C6586 reach the ground during the interval of his last positions
+	if !waitUntil(1500*time.Millisecond, func() bool {
+		altitude, _ := fake.ObjectSimVar(contacts["3C6586"], "PLANE ALTITUDE")
+		return altitude == 0
+	}) {
+		t.Error("3C6586 is not on the ground")
+	}
+
+	// the contacts without position during StaleAfter of the feed are removed, 4CA2D6 before 3C6586
+	if !waitUntil(2*time.Second, func() bool { return len(injector.Contacts()) == 1 }) {
+		t.Errorf("Contacts = %v", injector.Contacts())
+	}
+	if _, found := injector.Contacts()["3C6586"]; !found {
+		t.Errorf("Contacts = %v", injector.Contacts())
+	}
+	if objects := fake.AIObjects(); len(objects) != 1 {
+		t.Errorf("AIObjects = %#v", objects)
+	}
+	injector.Close()
+	if objects := fake.AIObjects(); len(objects) != 0 {
+		t.Errorf("AIObjects after Close = %#v", objects)
+	}
+}
+
+func TestTrafficInjectorClose(t *testing.T) {
+	fake := newFailingSimulator()
+	fake.SetContainerTitles("Traffic")
+	for _, name := range []string{"PLANE LATITUDE", "PLANE LONGITUDE", "PLANE ALTITUDE", "PLANE HEADING DEGREES TRUE"} {
+		fake.SetSimVar(name, 0)
+	}
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	injector := esc.NewTrafficInjector(sim.TrafficOptions{Title: "Traffic"})
+	fake.setDelay("AICreateNonATCAircraft", 200*time.Millisecond)
+	cUpdated := make(chan error, 1)
+	go func() {
+		cUpdated <- injector.Update(sim.TrafficContact{ICAO: "4CA2D6", Latitude: 53.35, Longitude: -6.26, Altitude: 35000})
+	}()
+	if !waitUntil(time.Second, func() bool { return fake.count("AICreateNonATCAircraft") == 1 }) {
+		t.Fatal("no AICreateNonATCAircraft")
+	}
+	// Close wait the aircraft in creation and remove it
+	if err := injector.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := <-cUpdated; err != nil {
+		t.Error(err)
+	}
+	if objects := fake.AIObjects(); len(objects) != 0 {
+		t.Errorf("AIObjects after Close = %#v", objects)
+	}
+	feed := sim.NewJSONTrafficFeed(strings.NewReader(`{"hex":"3c6586","lat":53.42,"lon":-6.27,"altitude":0,"ground":true}`))
+	if err := injector.Run(context.Background(), feed); err == nil {
+		t.Error("want error of Run after Close")
+	}
+	if contacts := injector.Contacts(); len(contacts) != 0 {
+		t.Errorf("Contacts after Close = %v", contacts)
+	}
+}
+
+func TestWeatherService(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetWeatherStation("KSEA", 47.45, -122.31, "KSEA 121200Z 18005KT 10SM BKN020 12/08 A3001")
+	fake.SetWeatherStation("KPDX", 45.59, -122.6, "KPDX 121200Z 27010KT 10SM FEW050 15/05 A3002")
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	weather := esc.NewWeatherService()
+	defer weather.Close()
+	if metar, err := weather.ObservationAtStation("KSEA"); err != nil || metar != "KSEA 121200Z 18005KT 10SM BKN020 12/08 A3001" {
+		t.Errorf("ObservationAtStation = %q, %v", metar, err)
+	}
+	if metar, err := weather.ObservationAtNearestStation(45.5, -122.5); err != nil || !strings.HasPrefix(metar, "KPDX ") {
+		t.Errorf("ObservationAtNearestStation = %q, %v", metar, err)
+	}
+	if _, err := weather.ObservationAtStation("LFPG"); !errors.Is(err, sim.ErrWeatherUnableToGetObservation) {
+		t.Errorf("err = %v, want ErrWeatherUnableToGetObservation", err)
+	}
+
+	// a station created use the global weather
+	if err := weather.SetObservation(0, "GLOB 121200Z 09015KT 9999 OVC010 05/04 Q1020"); err != nil {
+		t.Fatal(err)
+	}
+	if err := weather.SetObservation(0, "bad"); !errors.Is(err, sim.ErrWeatherInvalidMetar) {
+		t.Errorf("err = %v, want ErrWeatherInvalidMetar", err)
+	}
+	if err := weather.CreateStation("xtst", "Test", 46, -120, 300); err != nil {
+		t.Fatal(err)
+	}
+	if err := weather.CreateStation("KSEA", "Seattle", 47.45, -122.31, 0); !errors.Is(err, sim.ErrWeatherUnableToCreateStation) {
+		t.Errorf("err = %v, want ErrWeatherUnableToCreateStation", err)
+	}
+	if metar, err := weather.Observation(46.1, -120.1, 300); err != nil || metar != "XTST 121200Z 09015KT 9999 OVC010 05/04 Q1020" {
+		t.Errorf("Observation = %q, %v", metar, err)
+	}
+	if stations := weather.Stations(); len(stations) != 1 || stations[0] != "XTST" {
+		t.Errorf("Stations = %v", stations)
+	}
+	if err := weather.RemoveStation("KSEA"); !errors.Is(err, sim.ErrWeatherUnableToRemoveStation) {
+		t.Errorf("err = %v, want ErrWeatherUnableToRemoveStation", err)
+	}
+
+	modes := weather.ModeChanges()
+	time.Sleep(50 * time.Millisecond)
+	if err := weather.SetModeTheme("Stormy"); err != nil {
+		t.Fatal(err)
+	}
+	if mode := <-modes; mode != sim.SIMCONNECT_WEATHER_MODE_THEME {
+		t.Errorf("mode = %d, want THEME", mode)
+	}
+	if err := weather.SetModeCustom(); err != nil {
+		t.Fatal(err)
+	}
+	if mode := <-modes; mode != sim.SIMCONNECT_WEATHER_MODE_CUSTOM {
+		t.Errorf("mode = %d, want CUSTOM", mode)
+	}
+	if err := weather.SetModeServer(0, 60); !errors.Is(err, sim.ErrWeatherInvalidPort) {
+		t.Errorf("err = %v, want ErrWeatherInvalidPort", err)
+	}
+	if err := weather.SetDynamicUpdateRate(3); err != nil {
+		t.Error(err)
+	}
+	if mode, theme, _, rate := fake.WeatherMode(); mode != sim.SIMCONNECT_WEATHER_MODE_CUSTOM || theme != "Stormy" || rate != 3 {
+		t.Errorf("WeatherMode = %d, %q, %d", mode, theme, rate)
+	}
+
+	if err := weather.Close(); err != nil {
+		t.Error(err)
+	}
+	if _, ok := fake.WeatherStation("XTST"); ok {
+		t.Error("station XTST not removed by Close")
+	}
+	if _, ok := <-modes; ok {
+		t.Error("ModeChanges not closed by Close")
+	}
+}
+
+func TestWeatherServiceRemoveError(t *testing.T) {
+	fake := newFailingSimulator()
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	weather := esc.NewWeatherService()
+	if err := weather.CreateStation("XTST", "Test", 45, 5, 300); err != nil {
+		t.Fatal(err)
+	}
+	// a failed remove keep the station for Close
+	fake.setFail("WeatherRemoveStation", true)
+	if err := weather.RemoveStation("XTST"); err == nil {
+		t.Error("want error of WeatherRemoveStation")
+	}
+	if stations := weather.Stations(); len(stations) != 1 || stations[0] != "XTST" {
+		t.Errorf("Stations after error = %v", stations)
+	}
+	// a failed Close is retried by the next Close
+	if err := weather.Close(); err == nil {
+		t.Error("want error of WeatherRemoveStation in Close")
+	}
+	fake.setFail("WeatherRemoveStation", false)
+	if err := weather.Close(); err != nil {
+		t.Error(err)
+	}
+	if _, found := fake.WeatherStation("XTST"); found {
+		t.Error("station XTST after Close")
+	}
+	if stations := weather.Stations(); len(stations) != 0 {
+		t.Errorf("Stations after Close = %v", stations)
+	}
+}
+
+func TestWeatherServiceCloseDuringCreate(t *testing.T) {
+	fake := newFailingSimulator()
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	weather := esc.NewWeatherService()
+	fake.setDelay("WeatherCreateStation", 200*time.Millisecond)
+	cCreated := make(chan error, 1)
+	go func() {
+		cCreated <- weather.CreateStation("XTST", "Test", 45, 5, 300)
+	}()
+	if !waitUntil(time.Second, func() bool { return fake.count("WeatherCreateStation") == 1 }) {
+		t.Fatal("no WeatherCreateStation")
+	}
+	// Close wait the creation in progress and remove the station
+	if err := weather.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := <-cCreated; err != nil {
+		t.Error(err)
+	}
+	if _, found := fake.WeatherStation("XTST"); found {
+		t.Error("station XTST after Close")
+	}
+	if err := weather.CreateStation("XTST", "Test", 45, 5, 300); err == nil {
+		t.Error("want error after Close")
+	}
+}
+
+func TestCloudState(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	// a cloud in the north of the zone
+	fake.SetCloudDensity(func(latitude float64, longitude float64) byte {
+		if latitude > 47.5 {
+			return 200
+		}
+		return 0
+	})
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	weather := esc.NewWeatherService()
+	defer weather.Close()
+	grid, err := weather.CloudState(47, -123, 0, 48, -122, 20000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if density, ok := grid.At(47.9, -122.5); !ok || density != 200 {
+		t.Errorf("At north = %d, %v", density, ok)
+	}
+	if density, ok := grid.At(47.1, -122.5); !ok || density != 0 {
+		t.Errorf("At south = %d, %v", density, ok)
+	}
+	if _, err := weather.CloudState(48, -123, 0, 47, -122, 20000); !errors.Is(err, sim.ErrOutOfBounds) {
+		t.Errorf("err = %v, want ErrOutOfBounds", err)
+	}
+}
+
+func TestThermalField(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	config := sim.ThermalFieldConfig{Thermals: []sim.ThermalConfig{
+		{North: 0, East: 0, Thermal: sim.Thermal{Altitude: 1500, Radius: 800, Height: 1500, CoreRate: sim.Float32(2.5)}},
+		{North: 1000, East: 0, Thermal: sim.Thermal{Altitude: 1500, Radius: 500, Height: 1200}},
+		{North: 0, East: 1000, Thermal: sim.Thermal{Altitude: 1500, Radius: 600, Height: 1200, SinkRate: sim.Float32(0)}},
+	}}
+	field, err := esc.NewThermalField(config, 47, 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	thermals := fake.Thermals()
+	if len(thermals) != 3 {
+		t.Fatalf("Thermals = %#v", thermals)
+	}
+	for objectID, thermal := range field.Thermals() {
+		created := thermals[objectID]
+		if !reflect.DeepEqual(created, thermal) {
+			t.Errorf("thermal %d = %#v, want %#v", objectID, created, thermal)
+		}
+		if thermal.Radius == 500 && (thermal.Latitude <= 47 || *thermal.CoreRate != 3 || *thermal.SinkTurbulence != 0.2) {
+			t.Errorf("thermal north = %#v", thermal)
+		}
+		// a 0 is not replaced by the default
+		if thermal.Radius == 600 && (*thermal.SinkRate != 0 || *thermal.CoreRate != 3) {
+			t.Errorf("thermal east = %#v", thermal)
+		}
+	}
+	if err := field.Close(); err != nil {
+		t.Error(err)
+	}
+	if thermals := fake.Thermals(); len(thermals) != 0 {
+		t.Errorf("Thermals after Close = %#v", thermals)
+	}
+	field.Close()
+
+	// a thermal failing remove the others
+	config.Thermals = append(config.Thermals, sim.ThermalConfig{Thermal: sim.Thermal{Radius: 500, Height: 100, CoreRate: sim.Float32(5000)}})
+	if _, err := esc.NewThermalField(config, 47, 11); !errors.Is(err, sim.ErrOutOfBounds) {
+		t.Errorf("err = %v, want ErrOutOfBounds", err)
+	}
+	if thermals := fake.Thermals(); len(thermals) != 0 {
+		t.Errorf("Thermals after error = %#v", thermals)
+	}
+}
+
+func TestRemoveThermalError(t *testing.T) {
+	fake := newFailingSimulator()
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	weather := esc.NewWeatherService()
+	objectID, err := weather.CreateThermal(sim.Thermal{Latitude: 47, Longitude: 11, Altitude: 1500, Radius: 500, Height: 1200})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a failed remove keep the thermal for Close
+	fake.setFail("WeatherRemoveThermal", true)
+	if err := weather.RemoveThermal(objectID); err == nil {
+		t.Error("want error of WeatherRemoveThermal")
+	}
+	if objectIDs := weather.Thermals(); len(objectIDs) != 1 || objectIDs[0] != objectID {
+		t.Errorf("Thermals after error = %v", objectIDs)
+	}
+	fake.setFail("WeatherRemoveThermal", false)
+	if err := weather.Close(); err != nil {
+		t.Error(err)
+	}
+	if thermals := fake.Thermals(); len(thermals) != 0 {
+		t.Errorf("Thermals after Close = %#v", thermals)
+	}
+
+	// a failed Close of a ThermalField is retried by the next Close
+	config := sim.ThermalFieldConfig{Thermals: []sim.ThermalConfig{{Thermal: sim.Thermal{Altitude: 1500, Radius: 500, Height: 1200}}}}
+	field, err := esc.NewThermalField(config, 47, 11)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fake.setFail("WeatherRemoveThermal", true)
+	if err := field.Close(); err == nil {
+		t.Error("want error of WeatherRemoveThermal in Close")
+	}
+	if thermals := field.Thermals(); len(thermals) != 1 {
+		t.Errorf("ThermalField after error = %#v", thermals)
+	}
+	fake.setFail("WeatherRemoveThermal", false)
+	if err := field.Close(); err != nil {
+		t.Error(err)
+	}
+	if thermals := fake.Thermals(); len(thermals) != 0 {
+		t.Errorf("Thermals after Close = %#v", thermals)
+	}
+	if thermals := field.Thermals(); len(thermals) != 0 {
+		t.Errorf("ThermalField after Close = %#v", thermals)
+	}
+}
+
+func TestWeatherServiceCloseDuringCreateThermal(t *testing.T) {
+	fake := newFailingSimulator()
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	weather := esc.NewWeatherService()
+	fake.setDelay("WeatherCreateThermal", 200*time.Millisecond)
+	cCreated := make(chan error, 1)
+	go func() {
+		_, err := weather.CreateThermal(sim.Thermal{Latitude: 47, Longitude: 11, Altitude: 1500, Radius: 500, Height: 1200})
+		cCreated <- err
+	}()
+	if !waitUntil(time.Second, func() bool { return fake.count("WeatherCreateThermal") == 1 }) {
+		t.Fatal("no WeatherCreateThermal")
+	}
+	// Close wait the creation in progress and remove the thermal
+	if err := weather.Close(); err != nil {
+		t.Error(err)
+	}
+	if err := <-cCreated; err != nil {
+		t.Error(err)
+	}
+	if thermals := fake.Thermals(); len(thermals) != 0 {
+		t.Errorf("Thermals after Close = %#v", thermals)
+	}
+	if _, err := weather.CreateThermal(sim.Thermal{Latitude: 47, Longitude: 11, Altitude: 1500, Radius: 500, Height: 1200}); err == nil {
+		t.Error("want error after Close")
+	}
+}
+
+func TestFacilities(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	var airports []sim.FacilityAirport
+	for _, icao := range []string{"LFPO", "LFPG", "LFOB", "LFPB", "LFPN", "LFPV"} {
+		airports = append(airports, sim.FacilityAirport{ICAO: icao, Latitude: 48.7, Longitude: 2.4, Altitude: 89})
+	}
+	fake.SetAirports(airports...)
+	vorDME := sim.FacilityVOR{Flags: sim.SIMCONNECT_RECV_ID_VOR_LIST_HAS_NAV_SIGNAL | sim.SIMCONNECT_RECV_ID_VOR_LIST_HAS_DME}
+	vorDME.ICAO = "PGS"
+	vorDME.Frequency = 117150000
+	ils := sim.FacilityVOR{
+		Flags:           sim.SIMCONNECT_RECV_ID_VOR_LIST_HAS_LOCALIZER | sim.SIMCONNECT_RECV_ID_VOR_LIST_HAS_GLIDE_SLOPE,
+		Localizer:       265,
+		GlideSlopeAngle: 3,
+	}
+	ils.ICAO = "GLE"
+	fake.SetVORs(vorDME, ils)
+	esc := connect(t, fake)
+	defer esc.Close()
+	facilities := esc.NewFacilities(sim.FacilitiesOptions{Refresh: 50 * time.Millisecond})
+	defer facilities.Close()
+
+	// 6 airports are sent in 2 messages
+	list, err := facilities.RequestAirports()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 6 || list[0].ICAO != "LFOB" || list[5].ICAO != "LFPV" || list[1].Altitude != 89 {
+		t.Errorf("RequestAirports = %#v", list)
+	}
+	if ndbs, err := facilities.RequestNDBs(); err != nil || len(ndbs) != 0 {
+		t.Errorf("RequestNDBs = %#v, %v", ndbs, err)
+	}
+	vors, err := facilities.RequestVORs()
+	if err != nil || len(vors) != 2 {
+		t.Fatalf("RequestVORs = %#v, %v", vors, err)
+	}
+	if vor := vors[1]; vor.ICAO != "PGS" || !vor.HasNavSignal() || !vor.HasDME() || vor.HasLocalizer() || vor.Frequency != 117150000 {
+		t.Errorf("VOR = %#v", vor)
+	}
+	if vor := vors[0]; !vor.HasLocalizer() || !vor.HasGlideSlope() || vor.HasDME() || vor.Localizer != 265 {
+		t.Errorf("ILS = %#v", vor)
+	}
+
+	if err := facilities.Subscribe(sim.SIMCONNECT_FACILITY_LIST_TYPE_VOR); err != nil {
+		t.Fatal(err)
+	}
+	if vors := facilities.VORs(); len(vors) != 2 {
+		t.Errorf("VORs = %#v", vors)
+	}
+	if airports := facilities.Airports(); len(airports) != 0 {
+		t.Errorf("Airports not subscribed = %#v", airports)
+	}
+	// a VOR entering the bubble is sent by the subscription
+	vor := sim.FacilityVOR{Flags: sim.SIMCONNECT_RECV_ID_VOR_LIST_HAS_NAV_SIGNAL}
+	vor.ICAO = "RSY"
+	fake.SetVORs(vorDME, ils, vor)
+	time.Sleep(20 * time.Millisecond)
+	if vors := facilities.VOR("RSY"); len(vors) != 1 {
+		t.Errorf("VOR RSY not in the cache : %#v", facilities.VORs())
+	}
+	// a VOR leaving the bubble is removed by the refresh
+	fake.SetVORs(vorDME, vor)
+	time.Sleep(150 * time.Millisecond)
+	if vors := facilities.VOR("GLE"); len(vors) != 0 {
+		t.Errorf("VOR GLE in the cache after the refresh : %#v", facilities.VORs())
+	}
+	if vors := facilities.VORs(); len(vors) != 2 {
+		t.Errorf("VORs = %#v", vors)
+	}
+
+	if err := facilities.Subscribe(99); !errors.Is(err, sim.ErrInvalidEnum) {
+		t.Errorf("err = %v, want ErrInvalidEnum", err)
+	}
+	if err := facilities.Close(); err != nil {
+		t.Error(err)
+	}
+	if vors := facilities.VORs(); len(vors) != 0 {
+		t.Errorf("VORs after Close = %#v", vors)
+	}
+	facilities.Close()
+}
+
+func TestFacilitiesSameICAO(t *testing.T) {
+	fake := newFailingSimulator()
+	north := sim.FacilityWaypoint{FacilityAirport: sim.FacilityAirport{ICAO: "ABBEY", Latitude: 52, Longitude: -1}}
+	south := sim.FacilityWaypoint{FacilityAirport: sim.FacilityAirport{ICAO: "ABBEY", Latitude: 45, Longitude: 3}}
+	fake.SetWaypoints(north, south)
+	esc := connect(t, fake)
+	defer esc.Close()
+	facilities := esc.NewFacilities(sim.FacilitiesOptions{})
+	defer facilities.Close()
+
+	// the concurrent subscriptions of a type make one subscription
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := facilities.Subscribe(sim.SIMCONNECT_FACILITY_LIST_TYPE_WAYPOINT); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if n := fake.count("SubscribeToFacilities"); n != 1 {
+		t.Errorf("SubscribeToFacilities called %d times", n)
+	}
+	waitUntil(time.Second, func() bool { return len(facilities.Waypoint("ABBEY")) == 2 })
+	// the waypoints with the same ICAO are kept, sorted by position
+	waypoints := facilities.Waypoint("ABBEY")
+	if len(waypoints) != 2 || waypoints[0] != south || waypoints[1] != north {
+		t.Errorf("Waypoint = %#v", waypoints)
+	}
+	if waypoints := facilities.Waypoints(); len(waypoints) != 2 {
+		t.Errorf("Waypoints = %#v", waypoints)
+	}
+}
+
+func TestShowText(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	c, err := esc.ShowText("Hello", 1, sim.SIMCONNECT_TEXT_TYPE_PRINT_WHITE)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result := <-c; result != sim.SIMCONNECT_TEXT_RESULT_DISPLAYED {
+		t.Errorf("result = %d", result)
+	}
+	if texts := fake.Texts(); len(texts) != 1 || texts[0] != "Hello" {
+		t.Errorf("Texts = %#v", texts)
+	}
+
+	// the confirmations not read don't block the dispatch
+	for i := 0; i < 3; i++ {
+		if _, err := esc.ShowText("Not read", 1, sim.SIMCONNECT_TEXT_TYPE_PRINT_WHITE); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case value := <-esc.NewSimEvent(sim.KeyThrottleSet).RunWithValue(1000):
+		if value != 1000 {
+			t.Errorf("echo = %d, want 1000", value)
+		}
+	case <-time.After(time.Second):
+		t.Error("the dispatch is blocked by ShowText")
+	}
+}
+
+func TestQuit(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	esc := sim.NewEasySimConnectWithTransport(fake)
+	c, err := esc.Connect("TestApp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-c
+	fake.Quit()
+	if <-c {
+		t.Error("want false after quit")
+	}
+	if fake.IsOpen() {
+		t.Error("want closed")
+	}
+}
+
+func TestConnectToSimVarWithOptions(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetSimVar("PLANE ALTITUDE", 1000)
+	fake.SetSimVar("AIRSPEED INDICATED", 90)
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	cChanged, err := esc.ConnectToSimVarWithOptions(
+		sim.SimVarOptions{Period: sim.SIMCONNECT_PERIOD_SIM_FRAME, Changed: true},
+		sim.SimVarPlaneAltitude(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	changed := make([]float64, 0)
+	timeout := time.After(300 * time.Millisecond)
+	for waiting := true; waiting; {
+		select {
+		case result := <-cChanged:
+			f, _ := result[0].GetFloat64()
+			changed = append(changed, f)
+			if len(changed) == 1 {
+				fake.SetSimVar("PLANE ALTITUDE", 2000)
+			}
+		case <-timeout:
+			waiting = false
+		}
+	}
+	if len(changed) != 2 || changed[0] != 1000 || changed[1] != 2000 {
+		t.Errorf("changed values = %v, want [1000 2000]", changed)
+	}
+
+	cLimit, err := esc.ConnectToSimVarWithOptions(
+		sim.SimVarOptions{Period: sim.SIMCONNECT_PERIOD_SIM_FRAME, Limit: 2},
+		sim.SimVarAirspeedIndicated(),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := 0
+	timeout = time.After(300 * time.Millisecond)
+	for waiting := true; waiting; {
+		select {
+		case <-cLimit:
+			received++
+		case <-timeout:
+			waiting = false
+		}
+	}
+	if received != 2 {
+		t.Errorf("received %d values, want 2 with Limit", received)
+	}
+}
+
+func TestUnsubscribe(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetSimVar("PLANE ALTITUDE", 1000)
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	cSimVar, err := esc.ConnectToSimVar(sim.SimVarPlaneAltitude())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cPeriod, err := esc.ConnectToSimVarWithOptions(sim.SimVarOptions{Period: sim.SIMCONNECT_PERIOD_SIM_FRAME}, sim.SimVarPlaneAltitude())
+	if err != nil {
+		t.Fatal(err)
+	}
+	cCrashed := esc.ConnectSysEventCrashed()
+	if fake.DataDefinitions() != 2 || !fake.IsSubscribed(sim.SystemEventCrashed) {
+		t.Fatal("want 2 data definitions and Crashed subscribed")
+	}
+	for _, c := range []interface{}{cSimVar, cPeriod, cCrashed} {
+		sub, err := esc.Subscription(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sub.Unsubscribe(); err != nil {
+			t.Error(err)
+		}
+		if err := sub.Unsubscribe(); err != nil {
+			t.Error(err)
+		}
+	}
+	for range cSimVar {
+	}
+	for range cPeriod {
+	}
+	for range cCrashed {
+	}
+	if fake.DataDefinitions() != 0 {
+		t.Errorf("DataDefinitions = %d, want 0", fake.DataDefinitions())
+	}
+	if fake.IsSubscribed(sim.SystemEventCrashed) {
+		t.Error("want Crashed unsubscribed")
+	}
+	if _, err := esc.Subscription(cSimVar); err == nil {
+		t.Error("want error for removed Subscription")
+	}
+}
+
+func TestUnsubscribeInterface(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetSimVar("PLANE ALTITUDE", 1000)
+	esc := connect(t, fake)
+	defer esc.Close()
+
+	type plane struct {
+		Altitude float64 `sim:"PLANE ALTITUDE" simUnit:"Feet"`
+	}
+	cInterface, err := esc.ConnectInterfaceToSimVar(plane{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p := (<-cInterface).(plane); p.Altitude != 1000 {
+		t.Errorf("Altitude = %f, want 1000", p.Altitude)
+	}
+	sub, err := esc.Subscription(cInterface)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sub.Unsubscribe(); err != nil {
+		t.Error(err)
+	}
+	for range cInterface {
+	}
+	if fake.DataDefinitions() != 0 {
+		t.Errorf("DataDefinitions = %d, want 0", fake.DataDefinitions())
+	}
+}
+
+func TestContext(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetSimVar("PLANE ALTITUDE", 1000)
+	esc := sim.NewEasySimConnectWithTransport(fake)
+	esc.SetDelay(10 * time.Millisecond)
+	ctx, cancel := context.WithCancel(context.Background())
+	c, err := esc.ConnectContext(ctx, "TestApp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-c
+
+	ctxSimVar, cancelSimVar := context.WithCancel(ctx)
+	cSimVar, err := esc.ConnectToSimVarContext(ctxSimVar, sim.SimVarPlaneAltitude())
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-cSimVar
+	cancelSimVar()
+	for range cSimVar {
+	}
+	if fake.DataDefinitions() != 0 {
+		t.Errorf("DataDefinitions = %d, want 0", fake.DataDefinitions())
+	}
+
+	throttleSet := esc.NewSimEvent(sim.KeyThrottleSet)
+	if value, ok := <-throttleSet.RunContext(ctx); !ok || value != 0 {
+		t.Errorf("RunContext = %d %v", value, ok)
+	}
+	ctxText, cancelText := context.WithCancel(ctx)
+	cancelText()
+	if _, err := esc.ShowTextContext(ctxText, "Hello", 1, sim.SIMCONNECT_TEXT_TYPE_PRINT_WHITE); err != context.Canceled {
+		t.Errorf("ShowTextContext error = %v, want context.Canceled", err)
+	}
+
+	cancel()
+	select {
+	case open := <-c:
+		if open {
+			t.Error("want false after cancel")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting close")
+	}
+	if esc.IsAlive() || fake.IsOpen() {
+		t.Error("want closed after cancel")
+	}
+}
+
+func TestReconnect(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	fake.SetSimVar("PLANE ALTITUDE", 1000)
+	esc := sim.NewEasySimConnectWithTransport(fake)
+	esc.SetDelay(10 * time.Millisecond)
+	esc.SetReconnect(10*time.Millisecond, 50*time.Millisecond)
+	c, err := esc.Connect("TestApp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-c
+	defer esc.Close()
+
+	cSimVar, err := esc.ConnectToSimVarWithOptions(sim.SimVarOptions{Period: sim.SIMCONNECT_PERIOD_SIM_FRAME, Changed: true}, sim.SimVarPlaneAltitude())
+	if err != nil {
+		t.Fatal(err)
+	}
+	<-cSimVar
+	cCrashed := esc.ConnectSysEventCrashed()
+	throttleSet := esc.NewSimEvent(sim.KeyThrottleSet)
+
+	fake.Quit()
+	if <-c {
+		t.Fatal("want false after quit")
+	}
+	if !<-c {
+		t.Fatal("want true after reconnection")
+	}
+	fake.SetSimVar("PLANE ALTITUDE", 2000)
+	timeout := time.After(time.Second)
+	for waiting := true; waiting; {
+		select {
+		case result := <-cSimVar:
+			f, _ := result[0].GetFloat64()
+			waiting = f != 2000
+		case <-timeout:
+			t.Fatal("timeout waiting SimVar after reconnection")
+		}
+	}
+	fake.FireSystemEvent(sim.SystemEventCrashed, 0)
+	select {
+	case <-cCrashed:
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting Crashed after reconnection")
+	}
+	if value := <-throttleSet.RunWithValue(100); value != 100 {
+		t.Errorf("echo = %d, want 100", value)
+	}
+}
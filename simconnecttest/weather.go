@@ -0,0 +1,160 @@
+package simconnecttest
+
+import (
+	"math"
+	"strings"
+
+	sim "github.com/micmonay/simconnect"
+)
+
+// globalStation is the ICAO of the METAR setting the global weather
+const globalStation = "GLOB"
+
+// WeatherStation is a weather station of the Simulator
+type WeatherStation struct {
+	ICAO      string
+	Name      string
+	Latitude  float64
+	Longitude float64
+	Altitude  float64
+	METAR     string // empty give the global METAR with the ICAO of the station
+	Created   bool   // created by WeatherCreateStation
+	Located   bool   // the position is known, false for a station known only by WeatherSetObservation
+}
+
+// SetWeatherStation add a station of the simulator with his METAR
+func (s *Simulator) SetWeatherStation(icao string, latitude float64, longitude float64, metar string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	icao = strings.ToUpper(icao)
+	s.stations[icao] = &WeatherStation{ICAO: icao, Latitude: latitude, Longitude: longitude, METAR: metar, Located: true}
+}
+
+// WeatherStation return a weather station, ok is false if the station is unknown
+func (s *Simulator) WeatherStation(icao string) (station WeatherStation, ok bool) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	st, found := s.stations[strings.ToUpper(icao)]
+	if !found {
+		return WeatherStation{}, false
+	}
+	return *st, true
+}
+
+// GlobalMETAR return the last METAR set for GLOB
+func (s *Simulator) GlobalMETAR() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.globalMETAR
+}
+
+// WeatherMode return the weather mode (SIMCONNECT_WEATHER_MODE_*), the theme of WeatherSetModeTheme, the port of
+// WeatherSetModeServer and the rate of WeatherSetDynamicUpdateRate. The mode is SIMCONNECT_WEATHER_MODE_GLOBAL at the start.
+func (s *Simulator) WeatherMode() (mode uint32, theme string, port uint32, rate uint32) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.weatherMode, s.weatherTheme, s.weatherPort, s.weatherRate
+}
+
+// setWeatherMode change the mode and send WeatherModeChanged, must be called with the lock
+func (s *Simulator) setWeatherMode(mode uint32) {
+	s.weatherMode = mode
+	for _, eventID := range s.systemEvents[sim.SystemEventWeatherModeChanged] {
+		s.push(recvEventWeatherMode(0, eventID, mode))
+	}
+}
+
+// nearestStation return the nearest located station with a METAR, nil without station. With interpolated a station
+// without METAR is accepted for the global METAR. Must be called with the lock.
+func (s *Simulator) nearestStation(lat float32, lon float32, interpolated bool) *WeatherStation {
+	var nearest *WeatherStation
+	distance := math.Inf(1)
+	for _, station := range s.stations {
+		if !station.Located || (station.METAR == "" && !interpolated) {
+			continue
+		}
+		d := math.Hypot(station.Latitude-float64(lat), station.Longitude-float64(lon))
+		if d < distance {
+			nearest, distance = station, d
+		}
+	}
+	if nearest == nil && interpolated && s.globalMETAR != "" {
+		return &WeatherStation{ICAO: globalStation}
+	}
+	return nearest
+}
+
+// sendObservation send the METAR of the station or raise SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_GET_OBSERVATION,
+// must be called with the lock
+func (s *Simulator) sendObservation(sendID uint32, requestID uint32, station *WeatherStation) {
+	metar := ""
+	if station != nil {
+		metar = station.METAR
+		if metar == "" && s.globalMETAR != "" {
+			// the global METAR with the ICAO of the station
+			metar = station.ICAO + strings.TrimPrefix(s.globalMETAR, globalStation)
+		}
+	}
+	if metar == "" {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_WEATHER_UNABLE_TO_GET_OBSERVATION, sendID, 1))
+		return
+	}
+	s.push(recvWeatherObservation(requestID, metar))
+}
+
+// setObservation set the METAR of his station or of GLOB, must be called with the lock
+func (s *Simulator) setObservation(sendID uint32, metar string) {
+	fields := strings.Fields(metar)
+	if len(fields) < 2 || len(fields[0]) != 4 {
+		s.push(recvException(sim.SIMCONNECT_EXCEPTION_WEATHER_INVALID_METAR, sendID, 2))
+		return
+	}
+	icao := strings.ToUpper(fields[0])
+	if icao == globalStation {
+		s.globalMETAR = metar
+		return
+	}
+	station, found := s.stations[icao]
+	if !found {
+		station = &WeatherStation{ICAO: icao}
+		s.stations[icao] = station
+	}
+	station.METAR = metar
+}
+
+// SetCloudDensity set the function giving the cloud density (0 to 255) at a position for WeatherRequestCloudState,
+// without function there is no cloud
+func (s *Simulator) SetCloudDensity(density func(latitude float64, longitude float64) byte) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.cloudDensity = density
+}
+
+// cloudState return the densities at the center of the cells of the zone, the first row is the minimum latitude.
+// Must be called with the lock.
+func (s *Simulator) cloudState(minLat float32, minLon float32, maxLat float32, maxLon float32) []byte {
+	data := make([]byte, sim.SIMCONNECT_CLOUD_STATE_ARRAY_SIZE)
+	if s.cloudDensity == nil {
+		return data
+	}
+	const width = sim.SIMCONNECT_CLOUD_STATE_ARRAY_WIDTH
+	for row := 0; row < width; row++ {
+		latitude := float64(minLat) + (float64(row)+0.5)*float64(maxLat-minLat)/width
+		for col := 0; col < width; col++ {
+			longitude := float64(minLon) + (float64(col)+0.5)*float64(maxLon-minLon)/width
+			data[row*width+col] = s.cloudDensity(latitude, longitude)
+		}
+	}
+	return data
+}
+
+// Thermals return the thermals created by the client and not removed, by object ID
+func (s *Simulator) Thermals() map[uint32]sim.Thermal {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	thermals := make(map[uint32]sim.Thermal, len(s.thermals))
+	for objectID, thermal := range s.thermals {
+		thermals[objectID] = thermal
+	}
+	return thermals
+}
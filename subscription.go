@@ -0,0 +1,90 @@
+package simconnect
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Subscription is the link between a chan returned by EasySimConnect and the simulator.
+// Unsubscribe stop the update in the simulator and close the chan.
+//
+//	cSimVar, _ := esc.ConnectToSimVar(SimVarPlaneAltitude())
+//	sub, _ := esc.Subscription(cSimVar)
+//	sub.Unsubscribe()
+type Subscription struct {
+	esc    *EasySimConnect
+	c      reflect.Value
+	mutex  sync.Mutex
+	once   sync.Once
+	done   chan struct{}
+	closed bool
+	stop   func() error
+}
+
+// newSubscription register the chan c, stop is called by Unsubscribe for removing the subscription in the simulator
+func (esc *EasySimConnect) newSubscription(c interface{}, stop func() error) *Subscription {
+	sub := &Subscription{
+		esc:  esc,
+		c:    reflect.ValueOf(c),
+		done: make(chan struct{}),
+		stop: stop,
+	}
+	esc.mutex.Lock()
+	esc.listSubscription[sub.c.Pointer()] = sub
+	esc.mutex.Unlock()
+	return sub
+}
+
+// Subscription return the Subscription of a chan returned by ConnectToSimVar, ConnectToSimVarByType, ConnectInterfaceToSimVar,
+// ConnectSysEvent*, WatchSimEvents or ReserveKey
+func (esc *EasySimConnect) Subscription(c interface{}) (*Subscription, error) {
+	value := reflect.ValueOf(c)
+	if value.Kind() != reflect.Chan {
+		return nil, errors.New("Subscription need a chan")
+	}
+	esc.mutex.Lock()
+	defer esc.mutex.Unlock()
+	sub, found := esc.listSubscription[value.Pointer()]
+	if !found {
+		return nil, errors.New("Subscription not found")
+	}
+	return sub, nil
+}
+
+// send the value in the chan and return false if the value is not sent. timeout is 0 for waiting the reader.
+func (s *Subscription) send(value interface{}, timeout time.Duration) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if s.closed {
+		return false
+	}
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectSend, Chan: s.c, Send: reflect.ValueOf(value)},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.done)},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(s.esc.ctx.Done())},
+	}
+	if timeout > 0 {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(time.After(timeout))})
+	}
+	chosen, _, _ := reflect.Select(cases)
+	return chosen == 0
+}
+
+// Unsubscribe stop the update in the simulator and close the chan. Call Unsubscribe more than once do nothing.
+func (s *Subscription) Unsubscribe() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		s.mutex.Lock()
+		s.closed = true
+		s.c.Close()
+		s.mutex.Unlock()
+		s.esc.mutex.Lock()
+		delete(s.esc.listSubscription, s.c.Pointer())
+		s.esc.mutex.Unlock()
+		err = s.stop()
+	})
+	return err
+}
@@ -0,0 +1,757 @@
+package simconnect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// ProtocolVersion describe the version sent to the server when opening the connection
+type ProtocolVersion struct {
+	Protocol   uint32
+	Major      uint32
+	Minor      uint32
+	BuildMajor uint32
+	BuildMinor uint32
+}
+
+// ProtocolFSXSP2 is the protocol of FSX SP2 / Acceleration. It is accepted by FSX, Prepar3D and FS2020.
+var ProtocolFSXSP2 = ProtocolVersion{0x4, 10, 0, 61259, 0}
+
+// ID of the packets sent by the client. The server receive 0xF0000000 | ID.
+const (
+	packetOpen                                      = 0x01
+	packetRequestResponseTimes                      = 0x03
+	packetMapClientEventToSimEvent                  = 0x04
+	packetTransmitClientEvent                       = 0x05
+	packetSetSystemEventState                       = 0x06
+	packetAddClientEventToNotificationGroup         = 0x07
+	packetRemoveClientEvent                         = 0x08
+	packetSetNotificationGroupPriority              = 0x09
+	packetClearNotificationGroup                    = 0x0A
+	packetRequestNotificationGroup                  = 0x0B
+	packetAddToDataDefinition                       = 0x0C
+	packetClearDataDefinition                       = 0x0D
+	packetRequestDataOnSimObject                    = 0x0E
+	packetRequestDataOnSimObjectType                = 0x0F
+	packetSetDataOnSimObject                        = 0x10
+	packetMapInputEventToClientEvent                = 0x11
+	packetSetInputGroupPriority                     = 0x12
+	packetRemoveInputEvent                          = 0x13
+	packetClearInputGroup                           = 0x14
+	packetSetInputGroupState                        = 0x15
+	packetRequestReservedKey                        = 0x16
+	packetSubscribeToSystemEvent                    = 0x17
+	packetUnsubscribeFromSystemEvent                = 0x18
+	packetWeatherRequestInterpolatedObservation     = 0x19
+	packetWeatherRequestObservationAtStation        = 0x1A
+	packetWeatherRequestObservationAtNearestStation = 0x1B
+	packetWeatherCreateStation                      = 0x1C
+	packetWeatherRemoveStation                      = 0x1D
+	packetWeatherSetObservation                     = 0x1E
+	packetWeatherSetModeServer                      = 0x1F
+	packetWeatherSetModeTheme                       = 0x20
+	packetWeatherSetModeGlobal                      = 0x21
+	packetWeatherSetModeCustom                      = 0x22
+	packetWeatherSetDynamicUpdateRate               = 0x23
+	packetWeatherRequestCloudState                  = 0x24
+	packetWeatherCreateThermal                      = 0x25
+	packetWeatherRemoveThermal                      = 0x26
+	packetAICreateParkedATCAircraft                 = 0x27
+	packetAICreateEnrouteATCAircraft                = 0x28
+	packetAICreateNonATCAircraft                    = 0x29
+	packetAICreateSimulatedObject                   = 0x2A
+	packetAIReleaseControl                          = 0x2B
+	packetAIRemoveObject                            = 0x2C
+	packetAISetAircraftFlightPlan                   = 0x2D
+	packetExecuteMissionAction                      = 0x2E
+	packetCompleteCustomMissionAction               = 0x2F
+	packetCameraSetRelative6DOF                     = 0x30
+	packetMenuAddItem                               = 0x31
+	packetMenuDeleteItem                            = 0x32
+	packetMenuAddSubItem                            = 0x33
+	packetMenuDeleteSubItem                         = 0x34
+	packetRequestSystemState                        = 0x35
+	packetSetSystemState                            = 0x36
+	packetMapClientDataNameToID                     = 0x37
+	packetCreateClientData                          = 0x38
+	packetAddToClientDataDefinition                 = 0x39
+	packetClearClientDataDefinition                 = 0x3A
+	packetRequestClientData                         = 0x3B
+	packetSetClientData                             = 0x3C
+	packetFlightLoad                                = 0x3D
+	packetFlightSave                                = 0x3E
+	packetFlightPlanLoad                            = 0x3F
+	packetText                                      = 0x40
+	packetSubscribeToFacilities                     = 0x41
+	packetUnsubscribeToFacilities                   = 0x42
+	packetRequestFacilitiesList                     = 0x43
+)
+
+// size of the header of a packet sent by the client (size, protocol, type, send ID)
+const packetHeaderSize = 16
+
+// packetWriter build the parameters of a packet in little endian like the SimConnect structures
+type packetWriter struct {
+	bytes.Buffer
+}
+
+func (p *packetWriter) putUint32(v uint32) *packetWriter {
+	binary.Write(&p.Buffer, binary.LittleEndian, v)
+	return p
+}
+
+func (p *packetWriter) putInt32(v int32) *packetWriter {
+	binary.Write(&p.Buffer, binary.LittleEndian, v)
+	return p
+}
+
+func (p *packetWriter) putFloat32(v float32) *packetWriter {
+	binary.Write(&p.Buffer, binary.LittleEndian, v)
+	return p
+}
+
+func (p *packetWriter) putFloat64(v float64) *packetWriter {
+	binary.Write(&p.Buffer, binary.LittleEndian, v)
+	return p
+}
+
+func (p *packetWriter) putBool(b bool) *packetWriter {
+	if b {
+		return p.putUint32(1)
+	}
+	return p.putUint32(0)
+}
+
+// putString write a fixed size string padded with 0, the string is truncated if too long
+func (p *packetWriter) putString(str string, size int) *packetWriter {
+	buf := make([]byte, size)
+	copy(buf[:size-1], str)
+	p.Write(buf)
+	return p
+}
+
+func (p *packetWriter) putBytes(b []byte) *packetWriter {
+	p.Write(b)
+	return p
+}
+
+var (
+	_ Transport                  = (*TCPTransport)(nil)
+	_ DataTransport              = (*TCPTransport)(nil)
+	_ SystemTransport            = (*TCPTransport)(nil)
+	_ NotificationGroupTransport = (*TCPTransport)(nil)
+	_ InputTransport             = (*TCPTransport)(nil)
+	_ AITransport                = (*TCPTransport)(nil)
+	_ WeatherTransport           = (*TCPTransport)(nil)
+	_ FacilitiesTransport        = (*TCPTransport)(nil)
+)
+
+// TCPTransport is a pure Go Transport speaking the SimConnect protocol over TCP.
+// The server must be enabled in SimConnect.xml of the simulator (Protocol IPv4 and Port).
+type TCPTransport struct {
+	address  string
+	protocol ProtocolVersion
+	timeout  time.Duration
+
+	sendMutex sync.Mutex
+	conn      net.Conn
+	sendID    uint32
+
+	queueMutex sync.Mutex
+	queue      [][]byte
+}
+
+// NewTCPTransport return a Transport connecting to address (ex: "192.168.1.10:500") on Open
+func NewTCPTransport(address string) *TCPTransport {
+	return &TCPTransport{
+		address:  address,
+		protocol: ProtocolFSXSP2,
+		timeout:  5 * time.Second,
+	}
+}
+
+// SetProtocol change the protocol version sent on Open
+func (t *TCPTransport) SetProtocol(protocol ProtocolVersion) {
+	t.protocol = protocol
+}
+
+// SetTimeout change the timeout used for dialing the server
+func (t *TCPTransport) SetTimeout(timeout time.Duration) {
+	t.timeout = timeout
+}
+
+func (t *TCPTransport) send(packetType uint32, p *packetWriter) error {
+	t.sendMutex.Lock()
+	defer t.sendMutex.Unlock()
+	if t.conn == nil {
+		return errors.New("No connected")
+	}
+	t.sendID++
+	header := new(packetWriter).
+		putUint32(uint32(packetHeaderSize + p.Len())).
+		putUint32(t.protocol.Protocol).
+		putUint32(0xF0000000 | packetType).
+		putUint32(t.sendID)
+	_, err := t.conn.Write(append(header.Bytes(), p.Bytes()...))
+	return err
+}
+
+// push add a packet of conn in the queue, return false if conn is not the connection of the transport (closed or
+// replaced by Open). The lock of send prevent Open to change the connection and clear the queue during the push.
+func (t *TCPTransport) push(conn net.Conn, buf []byte) bool {
+	t.sendMutex.Lock()
+	defer t.sendMutex.Unlock()
+	if conn != t.conn {
+		return false
+	}
+	t.queueMutex.Lock()
+	t.queue = append(t.queue, buf)
+	t.queueMutex.Unlock()
+	return true
+}
+
+// read all the packets of the server, a SIMCONNECT_RECV_QUIT is added when the connection is lost.
+// The packets of an old connection are ignored.
+func (t *TCPTransport) read(conn net.Conn) {
+	for {
+		buf, err := readPacket(conn)
+		if err != nil {
+			quit := new(packetWriter).
+				putUint32(uint32(binary.Size(SIMCONNECT_RECV{}))).
+				putUint32(t.protocol.Protocol).
+				putUint32(SIMCONNECT_RECV_ID_QUIT)
+			t.push(conn, quit.Bytes())
+			return
+		}
+		if !t.push(conn, buf) {
+			return
+		}
+	}
+}
+
+// maxPacketSize is the largest packet accepted from the server or from a record
+const maxPacketSize = 1 << 24
+
+// readPacket read one SIMCONNECT_RECV with his size
+func readPacket(r io.Reader) ([]byte, error) {
+	var size uint32
+	err := binary.Read(r, binary.LittleEndian, &size)
+	if err != nil {
+		return nil, err
+	}
+	if size < uint32(binary.Size(SIMCONNECT_RECV{})) || size > maxPacketSize {
+		return nil, errors.New("Invalid packet size")
+	}
+	buf := make([]byte, size)
+	binary.LittleEndian.PutUint32(buf, size)
+	_, err = io.ReadFull(r, buf[4:])
+	if err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// Open connect to the server and send the SimConnect_Open packet
+func (t *TCPTransport) Open(appTitle string) error {
+	conn, err := net.DialTimeout("tcp", t.address, t.timeout)
+	if err != nil {
+		return err
+	}
+	t.sendMutex.Lock()
+	if t.conn != nil {
+		t.conn.Close()
+	}
+	t.conn = conn
+	t.sendID = 0
+	t.queueMutex.Lock()
+	t.queue = nil
+	t.queueMutex.Unlock()
+	t.sendMutex.Unlock()
+	go t.read(conn)
+	p := new(packetWriter).
+		putString(appTitle, 256).
+		putUint32(0).
+		putBytes([]byte{0x00, 'X', 'S', 'F'}).
+		putUint32(t.protocol.Major).
+		putUint32(t.protocol.Minor).
+		putUint32(t.protocol.BuildMajor).
+		putUint32(t.protocol.BuildMinor)
+	return t.send(packetOpen, p)
+}
+
+// Close the connection
+func (t *TCPTransport) Close() error {
+	t.sendMutex.Lock()
+	defer t.sendMutex.Unlock()
+	if t.conn == nil {
+		return nil
+	}
+	err := t.conn.Close()
+	t.conn = nil
+	return err
+}
+
+// GetLastSentPacketID return the send ID of the last packet
+func (t *TCPTransport) GetLastSentPacketID() (uint32, error) {
+	t.sendMutex.Lock()
+	defer t.sendMutex.Unlock()
+	return t.sendID, nil
+}
+
+// GetNextDispatch return the next packet received or an error if the queue is empty
+func (t *TCPTransport) GetNextDispatch() ([]byte, error) {
+	t.queueMutex.Lock()
+	defer t.queueMutex.Unlock()
+	if len(t.queue) == 0 {
+		return nil, errors.New("No dispatch")
+	}
+	buf := t.queue[0]
+	t.queue = t.queue[1:]
+	return buf, nil
+}
+
+// RequestResponseTimes return an error, the times are measured by SimConnect.dll and the server don't send them
+func (t *TCPTransport) RequestResponseTimes(nCount uint32, fElapsedSeconds []float32) error {
+	return errors.New("RequestResponseTimes is not available over TCP")
+}
+
+// MapClientEventToSimEvent SimConnect_MapClientEventToSimEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * EventName = "")
+func (t *TCPTransport) MapClientEventToSimEvent(EventID uint32, EventName string) error {
+	p := new(packetWriter).
+		putUint32(EventID).
+		putString(EventName, 256)
+	return t.send(packetMapClientEventToSimEvent, p)
+}
+
+// TransmitClientEvent SimConnect_TransmitClientEvent(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_CLIENT_EVENT_ID EventID, DWORD dwData, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_EVENT_FLAG Flags);
+func (t *TCPTransport) TransmitClientEvent(ObjectID uint32, EventID uint32, dwData int, GroupID GroupPriority, Flags EventFlag) error {
+	p := new(packetWriter).
+		putUint32(ObjectID).
+		putUint32(EventID).
+		putUint32(uint32(dwData)).
+		putUint32(uint32(GroupID)).
+		putUint32(uint32(Flags))
+	return t.send(packetTransmitClientEvent, p)
+}
+
+// AddClientEventToNotificationGroup SimConnect_AddClientEventToNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_CLIENT_EVENT_ID EventID, BOOL bMaskable = FALSE);
+func (t *TCPTransport) AddClientEventToNotificationGroup(GroupID uint32, EventID uint32, bMaskable bool) error {
+	p := new(packetWriter).
+		putUint32(GroupID).
+		putUint32(EventID).
+		putBool(bMaskable)
+	return t.send(packetAddClientEventToNotificationGroup, p)
+}
+
+// RemoveClientEvent SimConnect_RemoveClientEvent(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_CLIENT_EVENT_ID EventID);
+func (t *TCPTransport) RemoveClientEvent(GroupID uint32, EventID uint32) error {
+	p := new(packetWriter).
+		putUint32(GroupID).
+		putUint32(EventID)
+	return t.send(packetRemoveClientEvent, p)
+}
+
+// SetNotificationGroupPriority SimConnect_SetNotificationGroupPriority(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, DWORD uPriority);
+func (t *TCPTransport) SetNotificationGroupPriority(GroupID uint32, uPriority GroupPriority) error {
+	p := new(packetWriter).
+		putUint32(GroupID).
+		putUint32(uint32(uPriority))
+	return t.send(packetSetNotificationGroupPriority, p)
+}
+
+// ClearNotificationGroup SimConnect_ClearNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID);
+func (t *TCPTransport) ClearNotificationGroup(GroupID uint32) error {
+	p := new(packetWriter).
+		putUint32(GroupID)
+	return t.send(packetClearNotificationGroup, p)
+}
+
+// RequestNotificationGroup SimConnect_RequestNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, DWORD dwReserved = 0, DWORD Flags = 0);
+func (t *TCPTransport) RequestNotificationGroup(GroupID uint32, dwReserved uint32, Flags uint32) error {
+	p := new(packetWriter).
+		putUint32(GroupID).
+		putUint32(dwReserved).
+		putUint32(Flags)
+	return t.send(packetRequestNotificationGroup, p)
+}
+
+// AddToDataDefinition SimConnect_AddToDataDefinition(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID, const char * DatumName, const char * UnitsName, SIMCONNECT_DATATYPE DatumType = SIMCONNECT_DATATYPE_FLOAT64, float fEpsilon = 0, DWORD DatumID = SIMCONNECT_UNUSED);
+func (t *TCPTransport) AddToDataDefinition(DefineID uint32, DatumName string, UnitsName string, DatumType uint32, fEpsilon float32, DatumID uint32) error {
+	p := new(packetWriter).
+		putUint32(DefineID).
+		putString(DatumName, 256).
+		putString(UnitsName, 256).
+		putUint32(DatumType).
+		putFloat32(fEpsilon).
+		putUint32(DatumID)
+	return t.send(packetAddToDataDefinition, p)
+}
+
+// ClearDataDefinition SimConnect_ClearDataDefinition(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID);
+func (t *TCPTransport) ClearDataDefinition(DefineID uint32) error {
+	p := new(packetWriter).
+		putUint32(DefineID)
+	return t.send(packetClearDataDefinition, p)
+}
+
+// RequestDataOnSimObject SimConnect_RequestDataOnSimObject(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, SIMCONNECT_DATA_DEFINITION_ID DefineID, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_PERIOD Period, SIMCONNECT_DATA_REQUEST_FLAG Flags = 0, DWORD origin = 0, DWORD interval = 0, DWORD limit = 0);
+func (t *TCPTransport) RequestDataOnSimObject(RequestID uint32, DefineID uint32, ObjectID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) error {
+	p := new(packetWriter).
+		putUint32(RequestID).
+		putUint32(DefineID).
+		putUint32(ObjectID).
+		putUint32(Period).
+		putUint32(Flags).
+		putUint32(origin).
+		putUint32(interval).
+		putUint32(limit)
+	return t.send(packetRequestDataOnSimObject, p)
+}
+
+// RequestDataOnSimObjectType SimConnect_RequestDataOnSimObjectType(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, SIMCONNECT_DATA_DEFINITION_ID DefineID, DWORD dwRadiusMeters, SIMCONNECT_SIMOBJECT_TYPE type);
+func (t *TCPTransport) RequestDataOnSimObjectType(RequestID uint32, DefineID uint32, dwRadiusMeters uint32, ty uint32) error {
+	p := new(packetWriter).
+		putUint32(RequestID).
+		putUint32(DefineID).
+		putUint32(dwRadiusMeters).
+		putUint32(ty)
+	return t.send(packetRequestDataOnSimObjectType, p)
+}
+
+// SetDataOnSimObject SimConnect_SetDataOnSimObject(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_SET_FLAG Flags, DWORD ArrayCount, DWORD cbUnitSize, void * pDataSet);
+func (t *TCPTransport) SetDataOnSimObject(DefineID uint32, ObjectID uint32, Flags uint32, ArrayCount uint32, cbUnitSize uint32, pDataSet []byte) error {
+	p := new(packetWriter).
+		putUint32(DefineID).
+		putUint32(ObjectID).
+		putUint32(Flags).
+		putUint32(ArrayCount).
+		putUint32(cbUnitSize).
+		putBytes(pDataSet)
+	return t.send(packetSetDataOnSimObject, p)
+}
+
+// MapInputEventToClientEvent SimConnect_MapInputEventToClientEvent(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, const char * szInputDefinition, SIMCONNECT_CLIENT_EVENT_ID DownEventID, DWORD DownValue = 0, SIMCONNECT_CLIENT_EVENT_ID UpEventID = (SIMCONNECT_CLIENT_EVENT_ID)SIMCONNECT_UNUSED, DWORD UpValue = 0, BOOL bMaskable = FALSE);
+func (t *TCPTransport) MapInputEventToClientEvent(GroupID uint32, szInputDefinition string, DownEventID uint32, DownValue uint32, UpEventID uint32, UpValue uint32, bMaskable bool) error {
+	p := new(packetWriter).
+		putUint32(GroupID).
+		putString(szInputDefinition, 256).
+		putUint32(DownEventID).
+		putUint32(DownValue).
+		putUint32(UpEventID).
+		putUint32(UpValue).
+		putBool(bMaskable)
+	return t.send(packetMapInputEventToClientEvent, p)
+}
+
+// SetInputGroupPriority SimConnect_SetInputGroupPriority(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, DWORD uPriority);
+func (t *TCPTransport) SetInputGroupPriority(GroupID uint32, uPriority uint32) error {
+	p := new(packetWriter).
+		putUint32(GroupID).
+		putUint32(uPriority)
+	return t.send(packetSetInputGroupPriority, p)
+}
+
+// RemoveInputEvent SimConnect_RemoveInputEvent(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, const char * szInputDefinition);
+func (t *TCPTransport) RemoveInputEvent(GroupID uint32, szInputDefinition string) error {
+	p := new(packetWriter).
+		putUint32(GroupID).
+		putString(szInputDefinition, 256)
+	return t.send(packetRemoveInputEvent, p)
+}
+
+// ClearInputGroup SimConnect_ClearInputGroup(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID);
+func (t *TCPTransport) ClearInputGroup(GroupID uint32) error {
+	p := new(packetWriter).
+		putUint32(GroupID)
+	return t.send(packetClearInputGroup, p)
+}
+
+// SetInputGroupState SimConnect_SetInputGroupState(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, DWORD dwState);
+func (t *TCPTransport) SetInputGroupState(GroupID uint32, dwState SimConnectStat) error {
+	p := new(packetWriter).
+		putUint32(GroupID).
+		putUint32(uint32(dwState))
+	return t.send(packetSetInputGroupState, p)
+}
+
+// RequestReservedKey SimConnect_RequestReservedKey(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * szKeyChoice1 = "", const char * szKeyChoice2 = "", const char * szKeyChoice3 = "");
+func (t *TCPTransport) RequestReservedKey(EventID uint32, szKeyChoice1 string, szKeyChoice2 string, szKeyChoice3 string) error {
+	p := new(packetWriter).
+		putUint32(EventID).
+		putString(szKeyChoice1, 30).
+		putString(szKeyChoice2, 30).
+		putString(szKeyChoice3, 30)
+	return t.send(packetRequestReservedKey, p)
+}
+
+// SubscribeToSystemEvent SimConnect_SubscribeToSystemEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * SystemEventName);
+func (t *TCPTransport) SubscribeToSystemEvent(EventID uint32, SystemEventName SystemEvent) error {
+	p := new(packetWriter).
+		putUint32(EventID).
+		putString(string(SystemEventName), 256)
+	return t.send(packetSubscribeToSystemEvent, p)
+}
+
+// UnsubscribeFromSystemEvent SimConnect_UnsubscribeFromSystemEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID);
+func (t *TCPTransport) UnsubscribeFromSystemEvent(EventID uint32) error {
+	p := new(packetWriter).
+		putUint32(EventID)
+	return t.send(packetUnsubscribeFromSystemEvent, p)
+}
+
+// WeatherRequestInterpolatedObservation SimConnect_WeatherRequestInterpolatedObservation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float lat, float lon, float alt);
+func (t *TCPTransport) WeatherRequestInterpolatedObservation(RequestID uint32, lat float32, lon float32, alt float32) error {
+	p := new(packetWriter).
+		putUint32(RequestID).
+		putFloat32(lat).
+		putFloat32(lon).
+		putFloat32(alt)
+	return t.send(packetWeatherRequestInterpolatedObservation, p)
+}
+
+// WeatherRequestObservationAtStation SimConnect_WeatherRequestObservationAtStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szICAO);
+func (t *TCPTransport) WeatherRequestObservationAtStation(RequestID uint32, szICAO string) error {
+	p := new(packetWriter).
+		putUint32(RequestID).
+		putString(szICAO, 5)
+	return t.send(packetWeatherRequestObservationAtStation, p)
+}
+
+// WeatherRequestObservationAtNearestStation SimConnect_WeatherRequestObservationAtNearestStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float lat, float lon);
+func (t *TCPTransport) WeatherRequestObservationAtNearestStation(RequestID uint32, lat float32, lon float32) error {
+	p := new(packetWriter).
+		putUint32(RequestID).
+		putFloat32(lat).
+		putFloat32(lon)
+	return t.send(packetWeatherRequestObservationAtNearestStation, p)
+}
+
+// WeatherCreateStation SimConnect_WeatherCreateStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szICAO, const char * szName, float lat, float lon, float alt);
+func (t *TCPTransport) WeatherCreateStation(RequestID uint32, szICAO string, szName string, lat float32, lon float32, alt float32) error {
+	p := new(packetWriter).
+		putUint32(RequestID).
+		putString(szICAO, 5).
+		putString(szName, 256).
+		putFloat32(lat).
+		putFloat32(lon).
+		putFloat32(alt)
+	return t.send(packetWeatherCreateStation, p)
+}
+
+// WeatherRemoveStation SimConnect_WeatherRemoveStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szICAO);
+func (t *TCPTransport) WeatherRemoveStation(RequestID uint32, szICAO string) error {
+	p := new(packetWriter).
+		putUint32(RequestID).
+		putString(szICAO, 5)
+	return t.send(packetWeatherRemoveStation, p)
+}
+
+// WeatherSetObservation SimConnect_WeatherSetObservation(HANDLE hSimConnect, DWORD Seconds, const char * szMETAR);
+func (t *TCPTransport) WeatherSetObservation(Seconds uint32, szMETAR string) error {
+	p := new(packetWriter).
+		putUint32(Seconds).
+		putBytes(convGoStringtoBytes(szMETAR))
+	return t.send(packetWeatherSetObservation, p)
+}
+
+// WeatherSetModeServer SimConnect_WeatherSetModeServer(HANDLE hSimConnect, DWORD dwPort, DWORD dwSeconds);
+func (t *TCPTransport) WeatherSetModeServer(dwPort uint32, dwSeconds uint32) error {
+	p := new(packetWriter).
+		putUint32(dwPort).
+		putUint32(dwSeconds)
+	return t.send(packetWeatherSetModeServer, p)
+}
+
+// WeatherSetModeTheme SimConnect_WeatherSetModeTheme(HANDLE hSimConnect, const char * szThemeName);
+func (t *TCPTransport) WeatherSetModeTheme(szThemeName string) error {
+	p := new(packetWriter).
+		putString(szThemeName, 256)
+	return t.send(packetWeatherSetModeTheme, p)
+}
+
+// WeatherSetModeGlobal SimConnect_WeatherSetModeGlobal(HANDLE hSimConnect);
+func (t *TCPTransport) WeatherSetModeGlobal() error {
+	return t.send(packetWeatherSetModeGlobal, new(packetWriter))
+}
+
+// WeatherSetModeCustom SimConnect_WeatherSetModeCustom(HANDLE hSimConnect);
+func (t *TCPTransport) WeatherSetModeCustom() error {
+	return t.send(packetWeatherSetModeCustom, new(packetWriter))
+}
+
+// WeatherSetDynamicUpdateRate SimConnect_WeatherSetDynamicUpdateRate(HANDLE hSimConnect, DWORD dwRate);
+func (t *TCPTransport) WeatherSetDynamicUpdateRate(dwRate uint32) error {
+	p := new(packetWriter).
+		putUint32(dwRate)
+	return t.send(packetWeatherSetDynamicUpdateRate, p)
+}
+
+// WeatherRequestCloudState SimConnect_WeatherRequestCloudState(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float minLat, float minLon, float minAlt, float maxLat, float maxLon, float maxAlt, DWORD dwFlags = 0);
+func (t *TCPTransport) WeatherRequestCloudState(RequestID uint32, minLat float32, minLon float32, minAlt float32, maxLat float32, maxLon float32, maxAlt float32, dwFlags uint32) error {
+	p := new(packetWriter).
+		putUint32(RequestID).
+		putFloat32(minLat).
+		putFloat32(minLon).
+		putFloat32(minAlt).
+		putFloat32(maxLat).
+		putFloat32(maxLon).
+		putFloat32(maxAlt).
+		putUint32(dwFlags)
+	return t.send(packetWeatherRequestCloudState, p)
+}
+
+// WeatherCreateThermal SimConnect_WeatherCreateThermal(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float lat, float lon, float alt, float radius, float height, float coreRate = 3.0f, float coreTurbulence = 0.05f, float sinkRate = 3.0f, float sinkTurbulence = 0.2f, float coreSize = 0.4f, float coreTransitionSize = 0.1f, float sinkLayerSize = 0.4f, float sinkTransitionSize = 0.1f);
+func (t *TCPTransport) WeatherCreateThermal(RequestID uint32, lat float32, lon float32, alt float32, radius float32, height float32, coreRate float32, coreTurbulence float32, sinkRate float32, sinkTurbulence float32, coreSize float32, coreTransitionSize float32, sinkLayerSize float32, sinkTransitionSize float32) error {
+	p := new(packetWriter).
+		putUint32(RequestID).
+		putFloat32(lat).
+		putFloat32(lon).
+		putFloat32(alt).
+		putFloat32(radius).
+		putFloat32(height).
+		putFloat32(coreRate).
+		putFloat32(coreTurbulence).
+		putFloat32(sinkRate).
+		putFloat32(sinkTurbulence).
+		putFloat32(coreSize).
+		putFloat32(coreTransitionSize).
+		putFloat32(sinkLayerSize).
+		putFloat32(sinkTransitionSize)
+	return t.send(packetWeatherCreateThermal, p)
+}
+
+// WeatherRemoveThermal SimConnect_WeatherRemoveThermal(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID);
+func (t *TCPTransport) WeatherRemoveThermal(ObjectID uint32) error {
+	p := new(packetWriter).
+		putUint32(ObjectID)
+	return t.send(packetWeatherRemoveThermal, p)
+}
+
+// RequestSystemState SimConnect_RequestSystemState(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szState);
+func (t *TCPTransport) RequestSystemState(RequestID uint32, szState string) error {
+	p := new(packetWriter).
+		putUint32(RequestID).
+		putString(szState, 256)
+	return t.send(packetRequestSystemState, p)
+}
+
+// Text SimConnect_Text(HANDLE hSimConnect, SIMCONNECT_TEXT_TYPE type, float fTimeSeconds, SIMCONNECT_CLIENT_EVENT_ID EventID, DWORD cbUnitSize, void * pDataSet);
+func (t *TCPTransport) Text(ty uint32, fTimeSeconds float32, EventID uint32, pDataSet string) error {
+	str := convGoStringtoBytes(pDataSet)
+	p := new(packetWriter).
+		putUint32(ty).
+		putFloat32(fTimeSeconds).
+		putUint32(EventID).
+		putUint32(uint32(len(str))).
+		putBytes(str)
+	return t.send(packetText, p)
+}
+
+// AICreateParkedATCAircraft SimConnect_AICreateParkedATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, const char * szAirportID, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *TCPTransport) AICreateParkedATCAircraft(szContainerTitle string, szTailNumber string, szAirportID string, RequestID uint32) error {
+	p := new(packetWriter).
+		putString(szContainerTitle, 256).
+		putString(szTailNumber, 12).
+		putString(szAirportID, 5).
+		putUint32(RequestID)
+	return t.send(packetAICreateParkedATCAircraft, p)
+}
+
+// AICreateEnrouteATCAircraft SimConnect_AICreateEnrouteATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, int iFlightNumber, const char * szFlightPlanPath, double dFlightPlanPosition, BOOL bTouchAndGo, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *TCPTransport) AICreateEnrouteATCAircraft(szContainerTitle string, szTailNumber string, iFlightNumber int, szFlightPlanPath string, dFlightPlanPosition float64, bTouchAndGo uint32, RequestID uint32) error {
+	p := new(packetWriter).
+		putString(szContainerTitle, 256).
+		putString(szTailNumber, 12).
+		putInt32(int32(iFlightNumber)).
+		putString(szFlightPlanPath, 260).
+		putFloat64(dFlightPlanPosition).
+		putUint32(bTouchAndGo).
+		putUint32(RequestID)
+	return t.send(packetAICreateEnrouteATCAircraft, p)
+}
+
+// AICreateNonATCAircraft SimConnect_AICreateNonATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, SIMCONNECT_DATA_INITPOSITION InitPos, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *TCPTransport) AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) error {
+	p := new(packetWriter).
+		putString(szContainerTitle, 256).
+		putString(szTailNumber, 12).
+		putFloat64(InitPos.Latitude).
+		putFloat64(InitPos.Longitude).
+		putFloat64(InitPos.Altitude).
+		putFloat64(InitPos.Pitch).
+		putFloat64(InitPos.Bank).
+		putFloat64(InitPos.Heading).
+		putUint32(InitPos.OnGround).
+		putUint32(InitPos.Airspeed).
+		putUint32(RequestID)
+	return t.send(packetAICreateNonATCAircraft, p)
+}
+
+// AICreateSimulatedObject SimConnect_AICreateSimulatedObject(HANDLE hSimConnect, const char * szContainerTitle, SIMCONNECT_DATA_INITPOSITION InitPos, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *TCPTransport) AICreateSimulatedObject(szContainerTitle string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) error {
+	p := new(packetWriter).
+		putString(szContainerTitle, 256).
+		putFloat64(InitPos.Latitude).
+		putFloat64(InitPos.Longitude).
+		putFloat64(InitPos.Altitude).
+		putFloat64(InitPos.Pitch).
+		putFloat64(InitPos.Bank).
+		putFloat64(InitPos.Heading).
+		putUint32(InitPos.OnGround).
+		putUint32(InitPos.Airspeed).
+		putUint32(RequestID)
+	return t.send(packetAICreateSimulatedObject, p)
+}
+
+// AIReleaseControl SimConnect_AIReleaseControl(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *TCPTransport) AIReleaseControl(ObjectID uint32, RequestID uint32) error {
+	p := new(packetWriter).
+		putUint32(ObjectID).
+		putUint32(RequestID)
+	return t.send(packetAIReleaseControl, p)
+}
+
+// AIRemoveObject SimConnect_AIRemoveObject(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *TCPTransport) AIRemoveObject(ObjectID uint32, RequestID uint32) error {
+	p := new(packetWriter).
+		putUint32(ObjectID).
+		putUint32(RequestID)
+	return t.send(packetAIRemoveObject, p)
+}
+
+// AISetAircraftFlightPlan SimConnect_AISetAircraftFlightPlan(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, const char * szFlightPlanPath, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *TCPTransport) AISetAircraftFlightPlan(ObjectID uint32, szFlightPlanPath string, RequestID uint32) error {
+	p := new(packetWriter).
+		putUint32(ObjectID).
+		putString(szFlightPlanPath, 260).
+		putUint32(RequestID)
+	return t.send(packetAISetAircraftFlightPlan, p)
+}
+
+// SubscribeToFacilities SimConnect_SubscribeToFacilities(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *TCPTransport) SubscribeToFacilities(listType uint32, RequestID uint32) error {
+	p := new(packetWriter).
+		putUint32(listType).
+		putUint32(RequestID)
+	return t.send(packetSubscribeToFacilities, p)
+}
+
+// UnsubscribeToFacilities SimConnect_UnsubscribeToFacilities(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type);
+func (t *TCPTransport) UnsubscribeToFacilities(listType uint32) error {
+	p := new(packetWriter).
+		putUint32(listType)
+	return t.send(packetUnsubscribeToFacilities, p)
+}
+
+// RequestFacilitiesList SimConnect_RequestFacilitiesList(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *TCPTransport) RequestFacilitiesList(listType uint32, RequestID uint32) error {
+	p := new(packetWriter).
+		putUint32(listType).
+		putUint32(RequestID)
+	return t.send(packetRequestFacilitiesList, p)
+}
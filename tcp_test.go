@@ -0,0 +1,251 @@
+package simconnect
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"net"
+	"testing"
+	"time"
+)
+
+// standInServer accept one client and replay captured packets when the client send the expected packet type
+type standInServer struct {
+	t        *testing.T
+	listener net.Listener
+	conn     net.Conn
+}
+
+func newStandInServer(t *testing.T) *standInServer {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &standInServer{t: t, listener: listener}
+}
+
+func (s *standInServer) accept() {
+	conn, err := s.listener.Accept()
+	if err != nil {
+		s.t.Error(err)
+		return
+	}
+	s.conn = conn
+}
+
+// waitPacket read the packets of the client until packetType and return his parameters
+func (s *standInServer) waitPacket(packetType uint32) []byte {
+	for {
+		buf, err := readPacket(s.conn)
+		if err != nil {
+			s.t.Errorf("read packet 0x%X: %v", packetType, err)
+			return nil
+		}
+		if binary.LittleEndian.Uint32(buf[4:]) != ProtocolFSXSP2.Protocol {
+			s.t.Errorf("bad protocol %d", binary.LittleEndian.Uint32(buf[4:]))
+		}
+		if binary.LittleEndian.Uint32(buf[8:]) == 0xF0000000|packetType {
+			return buf[packetHeaderSize:]
+		}
+	}
+}
+
+func (s *standInServer) replay(file string) {
+	buf, err := ioutil.ReadFile(file)
+	if err != nil {
+		s.t.Error(err)
+		return
+	}
+	s.conn.Write(buf)
+}
+
+func (s *standInServer) close() {
+	if s.conn != nil {
+		s.conn.Close()
+	}
+	s.listener.Close()
+}
+
+func TestTCPTransportSimVar(t *testing.T) {
+	server := newStandInServer(t)
+	defer server.close()
+	go func() {
+		server.accept()
+		params := server.waitPacket(packetOpen)
+		if convStrToGoString(params[:256]) != "TestApp" {
+			t.Errorf("bad application name %q", convStrToGoString(params[:256]))
+		}
+		server.replay("testdata/recv_open.bin")
+		params = server.waitPacket(packetAddToDataDefinition)
+		if convStrToGoString(params[4:260]) != "PLANE ALTITUDE" {
+			t.Errorf("bad datum name %q", convStrToGoString(params[4:260]))
+		}
+		server.waitPacket(packetRequestDataOnSimObjectType)
+		server.replay("testdata/recv_simobject_data.bin")
+	}()
+
+	esc := NewEasySimConnectWithTransport(NewTCPTransport(server.listener.Addr().String()))
+	c, err := esc.Connect("TestApp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !<-c {
+		t.Fatal("not connected")
+	}
+	cSimVar, err := esc.ConnectToSimVar(SimVarPlaneAltitude())
+	if err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case result := <-cSimVar:
+		f, err := result[0].GetFloat64()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if f != 1543.5 {
+			t.Errorf("PLANE ALTITUDE = %f, want 1543.5", f)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting SimVar")
+	}
+	<-esc.Close()
+}
+
+func TestTCPTransportConnectionLost(t *testing.T) {
+	server := newStandInServer(t)
+	defer server.close()
+	go func() {
+		server.accept()
+		server.waitPacket(packetOpen)
+		server.replay("testdata/recv_open.bin")
+		server.conn.Close()
+	}()
+
+	esc := NewEasySimConnectWithTransport(NewTCPTransport(server.listener.Addr().String()))
+	c, err := esc.Connect("TestApp")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !<-c {
+		t.Fatal("not connected")
+	}
+	select {
+	case open := <-c:
+		if open {
+			t.Error("want false after connection lost")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting quit")
+	}
+}
+
+func TestTCPTransportPacketHeader(t *testing.T) {
+	server := newStandInServer(t)
+	defer server.close()
+	done := make(chan []byte)
+	go func() {
+		server.accept()
+		server.waitPacket(packetOpen)
+		buf, _ := readPacket(server.conn)
+		done <- buf
+	}()
+	transport := NewTCPTransport(server.listener.Addr().String())
+	if err := transport.Open("TestApp"); err != nil {
+		t.Fatal(err)
+	}
+	defer transport.Close()
+	if err := transport.SubscribeToSystemEvent(7, SystemEventSim); err != nil {
+		t.Fatal(err)
+	}
+	buf := <-done
+	want := new(packetWriter).
+		putUint32(packetHeaderSize+4+256).
+		putUint32(ProtocolFSXSP2.Protocol).
+		putUint32(0xF0000000|packetSubscribeToSystemEvent).
+		putUint32(2).
+		putUint32(7).
+		putString("Sim", 256)
+	if !bytes.Equal(buf, want.Bytes()) {
+		t.Errorf("packet = %x, want %x", buf, want.Bytes())
+	}
+	id, _ := transport.GetLastSentPacketID()
+	if id != 2 {
+		t.Errorf("GetLastSentPacketID = %d, want 2", id)
+	}
+}
+
+func TestTCPTransportReopen(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	cConn := make(chan net.Conn)
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			cConn <- conn
+		}
+	}()
+	open, err := ioutil.ReadFile("testdata/recv_open.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	transport := NewTCPTransport(listener.Addr().String())
+	// waitOpen return the packets received until the SIMCONNECT_RECV_OPEN
+	waitOpen := func() []uint32 {
+		var ids []uint32
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			buf, err := transport.GetNextDispatch()
+			if err != nil {
+				time.Sleep(time.Millisecond)
+				continue
+			}
+			id := binary.LittleEndian.Uint32(buf[8:])
+			ids = append(ids, id)
+			if id == SIMCONNECT_RECV_ID_OPEN {
+				return ids
+			}
+		}
+		t.Fatalf("timeout waiting open, received %v", ids)
+		return nil
+	}
+
+	// Close and Open
+	if err := transport.Open("TestApp"); err != nil {
+		t.Fatal(err)
+	}
+	first := <-cConn
+	defer first.Close()
+	if err := transport.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := transport.Open("TestApp"); err != nil {
+		t.Fatal(err)
+	}
+	second := <-cConn
+	defer second.Close()
+	second.Write(open)
+	if ids := waitOpen(); len(ids) != 1 {
+		t.Errorf("received %v, want only the open", ids)
+	}
+
+	// Open on a live connection, the old connection lost after must not quit the new
+	if err := transport.Open("TestApp"); err != nil {
+		t.Fatal(err)
+	}
+	third := <-cConn
+	defer third.Close()
+	second.Close()
+	// the old reader see the end of the connection before the next packet
+	time.Sleep(20 * time.Millisecond)
+	third.Write(open)
+	if ids := waitOpen(); len(ids) != 1 {
+		t.Errorf("received %v, want only the open", ids)
+	}
+	transport.Close()
+}
@@ -0,0 +1,225 @@
+package simconnect
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"sync"
+)
+
+// Thermal is a thermal of WeatherCreateThermal, the optional parameters at nil use the default of the SDK
+type Thermal struct {
+	Latitude           float32  `json:"latitude"`  // degrees
+	Longitude          float32  `json:"longitude"` // degrees
+	Altitude           float32  `json:"altitude"`  // feet, the base of the thermal
+	Radius             float32  `json:"radius"`    // meters, MAX_THERMAL_SIZE at most
+	Height             float32  `json:"height"`    // meters
+	CoreRate           *float32 `json:"coreRate"`  // meters per second, default 3
+	CoreTurbulence     *float32 `json:"coreTurbulence"`
+	SinkRate           *float32 `json:"sinkRate"` // meters per second, default 3
+	SinkTurbulence     *float32 `json:"sinkTurbulence"`
+	CoreSize           *float32 `json:"coreSize"` // part of the radius, default 0.4
+	CoreTransitionSize *float32 `json:"coreTransitionSize"`
+	SinkLayerSize      *float32 `json:"sinkLayerSize"`
+	SinkTransitionSize *float32 `json:"sinkTransitionSize"`
+}
+
+// Float32 return a pointer to v, for the optional parameters of Thermal
+//
+//	thermal := Thermal{Latitude: 47, Longitude: 11, Altitude: 1500, Radius: 500, Height: 1200, SinkRate: Float32(0)}
+func Float32(v float32) *float32 {
+	return &v
+}
+
+// withDefault return a copy of the thermal with all the optional parameters set, the nil parameters take the
+// default of the SDK
+func (t Thermal) withDefault() Thermal {
+	defaults := []struct {
+		value **float32
+		def   float32
+	}{
+		{&t.CoreRate, 3},
+		{&t.CoreTurbulence, 0.05},
+		{&t.SinkRate, 3},
+		{&t.SinkTurbulence, 0.2},
+		{&t.CoreSize, 0.4},
+		{&t.CoreTransitionSize, 0.1},
+		{&t.SinkLayerSize, 0.4},
+		{&t.SinkTransitionSize, 0.1},
+	}
+	for _, d := range defaults {
+		// a new pointer, the thermal don't share the values of the caller
+		v := d.def
+		if *d.value != nil {
+			v = **d.value
+		}
+		*d.value = &v
+	}
+	return t
+}
+
+// CreateThermal create a thermal and return his object ID, the thermal is removed by Close
+func (w *WeatherService) CreateThermal(thermal Thermal) (uint32, error) {
+	t := thermal.withDefault()
+	if err := w.startCreation(); err != nil {
+		return 0, err
+	}
+	defer w.creating.Done()
+	detail := fmt.Sprintf("%g %g", t.Latitude, t.Longitude)
+	recv, err := w.esc.callRequest("WeatherCreateThermal", detail, createObjectTimeout, func(requestID uint32) (error, uint32) {
+		return w.esc.sc.WeatherCreateThermal(requestID, t.Latitude, t.Longitude, t.Altitude, t.Radius, t.Height, *t.CoreRate, *t.CoreTurbulence,
+			*t.SinkRate, *t.SinkTurbulence, *t.CoreSize, *t.CoreTransitionSize, *t.SinkLayerSize, *t.SinkTransitionSize)
+	})
+	if err != nil {
+		return 0, err
+	}
+	objectID := recv.(*AssignedObjectIDMessage).ObjectID
+	w.mutex.Lock()
+	w.thermals[objectID] = true
+	w.mutex.Unlock()
+	return objectID, nil
+}
+
+// RemoveThermal remove a thermal created by CreateThermal
+func (w *WeatherService) RemoveThermal(objectID uint32) error {
+	err := w.esc.callWait("WeatherRemoveThermal", fmt.Sprint(objectID), func() (error, uint32) {
+		return w.esc.sc.WeatherRemoveThermal(objectID)
+	})
+	if err != nil {
+		// the thermal is kept for Close
+		return err
+	}
+	w.mutex.Lock()
+	delete(w.thermals, objectID)
+	w.mutex.Unlock()
+	return nil
+}
+
+// Thermals return the object IDs of the thermals created and not removed
+func (w *WeatherService) Thermals() []uint32 {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	objectIDs := make([]uint32, 0, len(w.thermals))
+	for objectID := range w.thermals {
+		objectIDs = append(objectIDs, objectID)
+	}
+	sort.Slice(objectIDs, func(i, j int) bool { return objectIDs[i] < objectIDs[j] })
+	return objectIDs
+}
+
+// ThermalConfig is a thermal of a ThermalFieldConfig, the position is North and East in meters from the center of the
+// field (Latitude and Longitude of Thermal are ignored)
+type ThermalConfig struct {
+	North float64 `json:"north"`
+	East  float64 `json:"east"`
+	Thermal
+}
+
+// ThermalFieldConfig is the layout of a ThermalField, read by ReadThermalFieldConfig:
+//
+//	{
+//		"thermals": [
+//			{"north": 0, "east": 0, "altitude": 1500, "radius": 800, "height": 1500, "coreRate": 2.5},
+//			{"north": 3000, "east": -1500, "altitude": 1500, "radius": 500, "height": 1200}
+//		]
+//	}
+type ThermalFieldConfig struct {
+	Thermals []ThermalConfig `json:"thermals"`
+}
+
+// ReadThermalFieldConfig read a JSON ThermalFieldConfig and control the thermals
+func ReadThermalFieldConfig(r io.Reader) (ThermalFieldConfig, error) {
+	var config ThermalFieldConfig
+	if err := json.NewDecoder(r).Decode(&config); err != nil {
+		return ThermalFieldConfig{}, fmt.Errorf("thermal field config : %w", err)
+	}
+	if len(config.Thermals) == 0 {
+		return ThermalFieldConfig{}, errors.New("thermal field config : no thermal")
+	}
+	for i, c := range config.Thermals {
+		t := c.withDefault()
+		if t.Radius <= 0 || t.Radius > MAX_THERMAL_SIZE {
+			return ThermalFieldConfig{}, fmt.Errorf("thermal field config : thermal %d radius %g not in ]0, %d]", i, t.Radius, MAX_THERMAL_SIZE)
+		}
+		if t.Height <= 0 {
+			return ThermalFieldConfig{}, fmt.Errorf("thermal field config : thermal %d height %g must be positive", i, t.Height)
+		}
+		if *t.CoreRate > MAX_THERMAL_RATE || *t.SinkRate > MAX_THERMAL_RATE {
+			return ThermalFieldConfig{}, fmt.Errorf("thermal field config : thermal %d rate bigger than %d", i, MAX_THERMAL_RATE)
+		}
+	}
+	return config, nil
+}
+
+// ThermalField is a set of thermals around a point, Close remove them
+//
+//	file, _ := os.Open("field.json")
+//	config, err := ReadThermalFieldConfig(file)
+//	field, err := esc.NewThermalField(config, 47.25, 11.35)
+//	defer field.Close()
+type ThermalField struct {
+	weather  *WeatherService
+	mutex    sync.Mutex
+	thermals map[uint32]Thermal
+}
+
+// NewThermalField create the thermals of the config around the point (degrees). If a thermal fail, the thermals
+// already created are removed.
+func (esc *EasySimConnect) NewThermalField(config ThermalFieldConfig, latitude float32, longitude float32) (*ThermalField, error) {
+	f := &ThermalField{
+		weather:  esc.NewWeatherService(),
+		thermals: make(map[uint32]Thermal),
+	}
+	for i, c := range config.Thermals {
+		thermal := c.Thermal
+		thermal.Latitude, thermal.Longitude = offsetPosition(latitude, longitude, c.North, c.East)
+		objectID, err := f.weather.CreateThermal(thermal)
+		if err != nil {
+			f.Close()
+			return nil, fmt.Errorf("thermal %d : %w", i, err)
+		}
+		f.thermals[objectID] = thermal.withDefault()
+	}
+	return f, nil
+}
+
+// Thermals return the thermals of the field by object ID
+func (f *ThermalField) Thermals() map[uint32]Thermal {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	thermals := make(map[uint32]Thermal, len(f.thermals))
+	for objectID, thermal := range f.thermals {
+		thermals[objectID] = thermal.withDefault()
+	}
+	return thermals
+}
+
+// Close remove the thermals of the field. Call Close again retry the thermals not removed.
+func (f *ThermalField) Close() error {
+	err := f.weather.Close()
+	left := make(map[uint32]bool)
+	for _, objectID := range f.weather.Thermals() {
+		left[objectID] = true
+	}
+	f.mutex.Lock()
+	for objectID := range f.thermals {
+		if !left[objectID] {
+			delete(f.thermals, objectID)
+		}
+	}
+	f.mutex.Unlock()
+	return err
+}
+
+// offsetPosition return the position at north and east meters of the point
+func offsetPosition(latitude float32, longitude float32, north float64, east float64) (float32, float32) {
+	lat := float64(latitude) + north/earthRadius*180/math.Pi
+	lon := float64(longitude)
+	if cos := math.Cos(float64(latitude) * math.Pi / 180); cos > 1e-6 {
+		lon += east / (earthRadius * cos) * 180 / math.Pi
+	}
+	return float32(lat), float32(lon)
+}
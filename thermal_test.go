@@ -0,0 +1,62 @@
+package simconnect
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestReadThermalFieldConfig(t *testing.T) {
+	file, err := os.Open("testdata/thermals.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	config, err := ReadThermalFieldConfig(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(config.Thermals) != 3 {
+		t.Fatalf("Thermals = %#v", config.Thermals)
+	}
+	if c := config.Thermals[1]; c.North != 3000 || c.East != -1500 || c.Radius != 500 || c.Height != 1200 || c.CoreRate != nil {
+		t.Errorf("thermal 1 = %#v", c)
+	}
+	thermal := config.Thermals[2].withDefault()
+	if *thermal.CoreRate != 3 || *thermal.SinkRate != 1.5 || *thermal.CoreSize != 0.3 || *thermal.CoreTurbulence != 0 ||
+		*thermal.SinkTurbulence != 0.2 || *thermal.CoreTransitionSize != 0.1 || *thermal.SinkLayerSize != 0.4 || *thermal.SinkTransitionSize != 0.1 {
+		t.Errorf("withDefault = %#v", thermal)
+	}
+	// withDefault don't share the values of the config
+	*thermal.SinkRate = 2
+	if *config.Thermals[2].SinkRate != 1.5 {
+		t.Errorf("config SinkRate = %g", *config.Thermals[2].SinkRate)
+	}
+
+	for _, s := range []string{
+		`{}`,
+		`{"thermals": [{"radius": 0, "height": 100}]}`,
+		`{"thermals": [{"radius": 200000, "height": 100}]}`,
+		`{"thermals": [{"radius": 500, "height": 0}]}`,
+		`{"thermals": [{"radius": 500, "height": 100, "coreRate": 5000}]}`,
+		`{"thermals": [`,
+	} {
+		if _, err := ReadThermalFieldConfig(strings.NewReader(s)); err == nil {
+			t.Errorf("%s: want error", s)
+		}
+	}
+}
+
+func TestOffsetPosition(t *testing.T) {
+	// 1 degree of latitude is about 111 km
+	lat, lon := offsetPosition(0, 10, 111195, 0)
+	if math.Abs(float64(lat)-1) > 1e-3 || lon != 10 {
+		t.Errorf("north = %g, %g", lat, lon)
+	}
+	// 1 degree of longitude is half at 60°
+	lat, lon = offsetPosition(60, 10, 0, 111195/2)
+	if lat != 60 || math.Abs(float64(lon)-11) > 1e-3 {
+		t.Errorf("east = %g, %g", lat, lon)
+	}
+}
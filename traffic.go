@@ -0,0 +1,657 @@
+package simconnect
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxExtrapolation is the maximum time of the dead reckoning after the last position of a contact
+const maxExtrapolation = 30 * time.Second
+
+// earthRadius in meters
+const earthRadius = 6371000
+
+// TrafficContact is a position of an aircraft received by a TrafficFeed
+type TrafficContact struct {
+	ICAO         string // ICAO 24 bit address in hexadecimal (ex: "4CA2D6")
+	Callsign     string
+	Latitude     float64 // degrees
+	Longitude    float64 // degrees
+	Altitude     float64 // feet
+	Track        float64 // degrees
+	GroundSpeed  float64 // knots
+	VerticalRate float64 // feet per minute
+	OnGround     bool
+	Time         time.Time // time of the last message
+	PositionTime time.Time // time of Latitude and Longitude, the dead reckoning start from it (zero is Time)
+}
+
+// TrafficFeed read the contacts of a traffic source, Next return io.EOF at the end of the source
+type TrafficFeed interface {
+	Next() (TrafficContact, error)
+}
+
+// TrafficLineError is a line of a feed not understood, the next lines can be read
+type TrafficLineError struct {
+	Line int
+	Err  error
+}
+
+func (e *TrafficLineError) Error() string {
+	return fmt.Sprintf("traffic feed line %d : %v", e.Line, e.Err)
+}
+
+func (e *TrafficLineError) Unwrap() error {
+	return e.Err
+}
+
+// jsonContact is a line of NewJSONTrafficFeed
+type jsonContact struct {
+	Hex          string  `json:"hex"`
+	Flight       string  `json:"flight"`
+	Latitude     float64 `json:"lat"`
+	Longitude    float64 `json:"lon"`
+	Altitude     float64 `json:"altitude"`
+	Track        float64 `json:"track"`
+	Speed        float64 `json:"speed"`
+	VerticalRate float64 `json:"vert_rate"`
+	Ground       bool    `json:"ground"`
+	Time         float64 `json:"time"`
+}
+
+// JSONTrafficFeed read a JSON object by line like:
+//
+//	{"hex":"4ca2d6","flight":"RYR1234","lat":53.35,"lon":-6.26,"altitude":35000,"track":90,"speed":450,"vert_rate":-64,"ground":false,"time":1615723200.1}
+//
+// time is in unix seconds, without time the contact is dated at the reading.
+type JSONTrafficFeed struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// NewJSONTrafficFeed return a TrafficFeed reading the JSON lines of r
+func NewJSONTrafficFeed(r io.Reader) *JSONTrafficFeed {
+	return &JSONTrafficFeed{scanner: bufio.NewScanner(r)}
+}
+
+// Next return the contact of the next line
+func (f *JSONTrafficFeed) Next() (TrafficContact, error) {
+	for f.scanner.Scan() {
+		f.line++
+		line := strings.TrimSpace(f.scanner.Text())
+		if line == "" {
+			continue
+		}
+		var c jsonContact
+		if err := json.Unmarshal([]byte(line), &c); err != nil {
+			return TrafficContact{}, &TrafficLineError{f.line, err}
+		}
+		if c.Hex == "" {
+			return TrafficContact{}, &TrafficLineError{f.line, errors.New("no hex")}
+		}
+		contact := TrafficContact{
+			ICAO:         strings.ToUpper(c.Hex),
+			Callsign:     strings.TrimSpace(c.Flight),
+			Latitude:     c.Latitude,
+			Longitude:    c.Longitude,
+			Altitude:     c.Altitude,
+			Track:        c.Track,
+			GroundSpeed:  c.Speed,
+			VerticalRate: c.VerticalRate,
+			OnGround:     c.Ground,
+			Time:         time.Now(),
+		}
+		if c.Time != 0 {
+			sec, frac := math.Modf(c.Time)
+			contact.Time = time.Unix(int64(sec), int64(frac*1e9))
+		}
+		contact.PositionTime = contact.Time
+		return contact, nil
+	}
+	if err := f.scanner.Err(); err != nil {
+		return TrafficContact{}, err
+	}
+	return TrafficContact{}, io.EOF
+}
+
+// SBSTrafficFeed read the messages MSG of the BaseStation (SBS-1) format, the port 30003 of dump1090.
+// The messages of an aircraft are merged, a contact is returned for each message once the position is known.
+type SBSTrafficFeed struct {
+	scanner  *bufio.Scanner
+	line     int
+	contacts map[string]*sbsContact
+}
+
+type sbsContact struct {
+	contact     TrafficContact
+	hasPosition bool
+}
+
+// NewSBSTrafficFeed return a TrafficFeed reading the SBS-1 lines of r
+func NewSBSTrafficFeed(r io.Reader) *SBSTrafficFeed {
+	return &SBSTrafficFeed{scanner: bufio.NewScanner(r), contacts: make(map[string]*sbsContact)}
+}
+
+// Next return the contact updated by the next message with a known position
+func (f *SBSTrafficFeed) Next() (TrafficContact, error) {
+	for f.scanner.Scan() {
+		f.line++
+		line := strings.TrimSpace(f.scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, ",")
+		if fields[0] != "MSG" {
+			continue
+		}
+		if len(fields) < 22 {
+			return TrafficContact{}, &TrafficLineError{f.line, fmt.Errorf("%d fields, want 22", len(fields))}
+		}
+		hex := strings.ToUpper(fields[4])
+		if hex == "" {
+			return TrafficContact{}, &TrafficLineError{f.line, errors.New("no hex ident")}
+		}
+		c, found := f.contacts[hex]
+		if !found {
+			c = &sbsContact{contact: TrafficContact{ICAO: hex}}
+			f.contacts[hex] = c
+		}
+		if err := c.update(fields); err != nil {
+			return TrafficContact{}, &TrafficLineError{f.line, err}
+		}
+		if c.hasPosition {
+			return c.contact, nil
+		}
+	}
+	if err := f.scanner.Err(); err != nil {
+		return TrafficContact{}, err
+	}
+	return TrafficContact{}, io.EOF
+}
+
+// update the contact with the fields not empty of the message
+func (c *sbsContact) update(fields []string) error {
+	float := func(i int, v *float64) error {
+		if fields[i] == "" {
+			return nil
+		}
+		f, err := strconv.ParseFloat(fields[i], 64)
+		if err != nil {
+			return fmt.Errorf("field %d : %v", i, err)
+		}
+		*v = f
+		return nil
+	}
+	contact := c.contact
+	if callsign := strings.TrimSpace(fields[10]); callsign != "" {
+		contact.Callsign = callsign
+	}
+	for i, v := range map[int]*float64{11: &contact.Altitude, 12: &contact.GroundSpeed, 13: &contact.Track, 16: &contact.VerticalRate} {
+		if err := float(i, v); err != nil {
+			return err
+		}
+	}
+	if fields[21] != "" {
+		contact.OnGround = fields[21] != "0"
+	}
+	contact.Time = time.Now()
+	if t, err := time.ParseInLocation("2006/01/02 15:04:05.000", fields[6]+" "+fields[7], time.Local); err == nil {
+		contact.Time = t
+	}
+	// the messages without position don't move the start of the dead reckoning
+	if fields[14] != "" && fields[15] != "" {
+		if err := float(14, &contact.Latitude); err != nil {
+			return err
+		}
+		if err := float(15, &contact.Longitude); err != nil {
+			return err
+		}
+		contact.PositionTime = contact.Time
+		c.hasPosition = true
+	}
+	c.contact = contact
+	return nil
+}
+
+// position return the contact moved by dead reckoning after the duration
+func (c TrafficContact) position(elapsed time.Duration) TrafficContact {
+	if elapsed > maxExtrapolation {
+		elapsed = maxExtrapolation
+	}
+	if elapsed < 0 {
+		elapsed = 0
+	}
+	seconds := elapsed.Seconds()
+	distance := c.GroundSpeed * 1852 / 3600 * seconds
+	track := c.Track * math.Pi / 180
+	latitude := c.Latitude * math.Pi / 180
+	c.Latitude += distance * math.Cos(track) / earthRadius * 180 / math.Pi
+	if math.Cos(latitude) > 1e-6 {
+		c.Longitude += distance * math.Sin(track) / (earthRadius * math.Cos(latitude)) * 180 / math.Pi
+	}
+	if !c.OnGround {
+		c.Altitude += c.VerticalRate * seconds / 60
+	}
+	return c
+}
+
+// velocity set the ground speed and the track of c with the previous position if the feed don't give them
+func (c *TrafficContact) velocity(previous TrafficContact) {
+	if c.GroundSpeed != 0 || c.OnGround {
+		return
+	}
+	if c.PositionTime.Equal(previous.PositionTime) {
+		// no new position, the velocity is the previous
+		c.GroundSpeed, c.Track = previous.GroundSpeed, previous.Track
+		if c.VerticalRate == 0 {
+			c.VerticalRate = previous.VerticalRate
+		}
+		return
+	}
+	seconds := c.PositionTime.Sub(previous.PositionTime).Seconds()
+	if seconds <= 0 {
+		return
+	}
+	latitude := previous.Latitude * math.Pi / 180
+	north := (c.Latitude - previous.Latitude) * math.Pi / 180 * earthRadius
+	east := (c.Longitude - previous.Longitude) * math.Pi / 180 * earthRadius * math.Cos(latitude)
+	c.GroundSpeed = math.Hypot(north, east) / seconds * 3600 / 1852
+	c.Track = math.Mod(math.Atan2(east, north)*180/math.Pi+360, 360)
+	if c.VerticalRate == 0 {
+		c.VerticalRate = (c.Altitude - previous.Altitude) / seconds * 60
+	}
+}
+
+// TrafficOptions configure a TrafficInjector, a zero value use the default
+type TrafficOptions struct {
+	// Title is the container title of the created aircraft (default "Airbus A320 Neo Asobo")
+	Title string
+	// Interval is the period of the positions sent to the simulator (default 200ms)
+	Interval time.Duration
+	// StaleAfter remove a contact without position during this duration, by the time of the feed (default 60s)
+	StaleAfter time.Duration
+	// MaxContacts is the maximum number of aircraft created, 0 for no limit
+	MaxContacts int
+}
+
+func (o TrafficOptions) withDefault() TrafficOptions {
+	if o.Title == "" {
+		o.Title = "Airbus A320 Neo Asobo"
+	}
+	if o.Interval <= 0 {
+		o.Interval = 200 * time.Millisecond
+	}
+	if o.StaleAfter <= 0 {
+		o.StaleAfter = 60 * time.Second
+	}
+	return o
+}
+
+// trafficTarget is a contact and his object in the simulator
+type trafficTarget struct {
+	contact  TrafficContact
+	objectID uint32 // 0 during the creation
+	// the gap between the drawn position and the new contact at blendStart, reduced to 0 during blend
+	gap        TrafficContact
+	blendStart time.Time
+	blend      time.Duration // the interval of the positions of the feed
+}
+
+// drawn return the position of the aircraft at now, the dead reckoning of the contact plus the rest of the gap
+func (target *trafficTarget) drawn(now time.Time) TrafficContact {
+	p := target.contact.position(now.Sub(target.contact.PositionTime))
+	if target.blend <= 0 {
+		return p
+	}
+	rest := 1 - float64(now.Sub(target.blendStart))/float64(target.blend)
+	if rest <= 0 {
+		return p
+	}
+	if rest > 1 {
+		rest = 1
+	}
+	p.Latitude += target.gap.Latitude * rest
+	p.Longitude += target.gap.Longitude * rest
+	p.Altitude += target.gap.Altitude * rest
+	p.Track = math.Mod(p.Track+target.gap.Track*rest+360, 360)
+	return p
+}
+
+// receive replace the contact at now, the drawn position go from the current one to the new contact during the
+// interval of the positions of the feed
+func (target *trafficTarget) receive(contact TrafficContact, now time.Time) {
+	from := target.drawn(now)
+	if interval := contact.PositionTime.Sub(target.contact.PositionTime); interval > 0 {
+		target.blend = interval
+		if target.blend > maxExtrapolation {
+			target.blend = maxExtrapolation
+		}
+	}
+	target.contact = contact
+	to := contact.position(now.Sub(contact.PositionTime))
+	target.gap = TrafficContact{
+		Latitude:  from.Latitude - to.Latitude,
+		Longitude: from.Longitude - to.Longitude,
+		Altitude:  from.Altitude - to.Altitude,
+		// the shortest turn
+		Track: math.Mod(from.Track-to.Track+540, 360) - 180,
+	}
+	target.blendStart = now
+}
+
+// TrafficInjector create an AI aircraft for each contact of a TrafficFeed and move it at each Interval between the
+// positions of the feed, a new position is reached during the interval of the positions of the feed. The contacts
+// without position during StaleAfter are removed. The clock of the injector is the local time, or the time of the
+// feed during Replay.
+//
+//	file, _ := os.Open("traffic.sbs")
+//	injector := esc.NewTrafficInjector(TrafficOptions{})
+//	defer injector.Close()
+//	injector.Replay(ctx, NewSBSTrafficFeed(file))
+type TrafficInjector struct {
+	esc       *EasySimConnect
+	ai        *AIManager
+	options   TrafficOptions
+	mutex     sync.Mutex
+	targets   map[string]*trafficTarget
+	defineID  uint32
+	defined   bool
+	restoreID uint32
+	offset    time.Duration  // the time of the feed less the local time during Replay
+	updating  sync.WaitGroup // the calls of Update in progress
+	closed    bool
+	done      chan struct{}
+	once      sync.Once
+}
+
+// NewTrafficInjector return a TrafficInjector, Close remove the aircraft created
+func (esc *EasySimConnect) NewTrafficInjector(options TrafficOptions) *TrafficInjector {
+	t := &TrafficInjector{
+		esc:     esc,
+		ai:      esc.NewAIManager(),
+		options: options.withDefault(),
+		targets: make(map[string]*trafficTarget),
+		done:    make(chan struct{}),
+	}
+	go t.run()
+	return t
+}
+
+// Run read a live feed until the end or the cancellation of ctx. The lines not understood are logged and ignored.
+func (t *TrafficInjector) Run(ctx context.Context, feed TrafficFeed) error {
+	return t.read(ctx, feed, false)
+}
+
+// Replay read a recorded feed like Run, each contact is injected at his Time from the first contact. The clock of the
+// injector is the time of the feed, the contacts must be dated by the feed.
+func (t *TrafficInjector) Replay(ctx context.Context, feed TrafficFeed) error {
+	return t.read(ctx, feed, true)
+}
+
+func (t *TrafficInjector) read(ctx context.Context, feed TrafficFeed, replay bool) error {
+	if !replay {
+		// the local time after a Replay
+		t.mutex.Lock()
+		t.offset = 0
+		t.mutex.Unlock()
+	}
+	started := false
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		select {
+		case <-t.done:
+			return errors.New("TrafficInjector is closed")
+		default:
+		}
+		contact, err := feed.Next()
+		if err == io.EOF {
+			return nil
+		}
+		var lineErr *TrafficLineError
+		if errors.As(err, &lineErr) {
+			t.esc.logf(LogInfo, "%v", err)
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if replay {
+			if !started {
+				// the clock start at the first contact
+				started = true
+				t.mutex.Lock()
+				t.offset = contact.Time.Sub(time.Now())
+				t.mutex.Unlock()
+			}
+			if err := t.wait(ctx, contact.Time); err != nil {
+				return err
+			}
+		}
+		if err := t.Update(contact); err != nil {
+			t.esc.logf(LogInfo, "Error traffic contact %s : %v", contact.ICAO, err)
+		}
+	}
+}
+
+// wait the time of the feed during Replay
+func (t *TrafficInjector) wait(ctx context.Context, feedTime time.Time) error {
+	delay := feedTime.Sub(t.now())
+	if delay <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.done:
+		return errors.New("TrafficInjector is closed")
+	}
+}
+
+// now return the clock of the injector
+func (t *TrafficInjector) now() time.Time {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	return time.Now().Add(t.offset)
+}
+
+// Update move the aircraft of the contact, the aircraft is created for a new contact. A contact without Time is dated
+// by the clock of the injector.
+func (t *TrafficInjector) Update(contact TrafficContact) error {
+	t.mutex.Lock()
+	if t.closed {
+		t.mutex.Unlock()
+		return errors.New("TrafficInjector is closed")
+	}
+	// Close wait the aircraft in creation
+	t.updating.Add(1)
+	t.mutex.Unlock()
+	defer t.updating.Done()
+	if err := t.define(); err != nil {
+		return err
+	}
+	if contact.Time.IsZero() {
+		contact.Time = t.now()
+	}
+	if contact.PositionTime.IsZero() {
+		contact.PositionTime = contact.Time
+	}
+	now := t.now()
+	t.mutex.Lock()
+	target, found := t.targets[contact.ICAO]
+	if found {
+		contact.velocity(target.contact)
+		target.receive(contact, now)
+		t.mutex.Unlock()
+		return nil
+	}
+	if t.options.MaxContacts > 0 && len(t.targets) >= t.options.MaxContacts {
+		t.mutex.Unlock()
+		return fmt.Errorf("the maximum of %d contacts is reached", t.options.MaxContacts)
+	}
+	target = &trafficTarget{contact: contact}
+	t.targets[contact.ICAO] = target
+	t.mutex.Unlock()
+
+	tailNumber := contact.Callsign
+	if tailNumber == "" {
+		tailNumber = contact.ICAO
+	}
+	onGround := uint32(0)
+	if contact.OnGround {
+		onGround = 1
+	}
+	objectID, err := t.ai.CreateNonATCAircraft(t.options.Title, tailNumber, SIMCONNECT_DATA_INITPOSITION{
+		Latitude:  contact.Latitude,
+		Longitude: contact.Longitude,
+		Altitude:  contact.Altitude,
+		Heading:   contact.Track,
+		OnGround:  onGround,
+		Airspeed:  uint32(contact.GroundSpeed),
+	})
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if err != nil {
+		delete(t.targets, contact.ICAO)
+		return err
+	}
+	target.objectID = objectID
+	return nil
+}
+
+// Contacts return the object IDs of the aircraft created by ICAO address
+func (t *TrafficInjector) Contacts() map[string]uint32 {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	contacts := make(map[string]uint32, len(t.targets))
+	for icao, target := range t.targets {
+		if target.objectID != 0 {
+			contacts[icao] = target.objectID
+		}
+	}
+	return contacts
+}
+
+// Close remove all the aircraft created. Call Close more than once do nothing.
+func (t *TrafficInjector) Close() error {
+	var err error
+	t.once.Do(func() {
+		t.mutex.Lock()
+		t.closed = true
+		t.mutex.Unlock()
+		close(t.done)
+		t.updating.Wait()
+		err = t.ai.Close()
+		t.mutex.Lock()
+		defined := t.defined
+		t.targets = make(map[string]*trafficTarget)
+		t.mutex.Unlock()
+		if defined {
+			t.esc.removeRestore(t.restoreID)
+			t.esc.clearDataDefinition(t.defineID)
+		}
+	})
+	return err
+}
+
+// trafficSimVars are the SimVars of the definition written for each aircraft
+func trafficSimVars() []SimVar {
+	return []SimVar{
+		SimVarPlaneLatitude(UnitDegrees),
+		SimVarPlaneLongitude(UnitDegrees),
+		SimVarPlaneAltitude(UnitFeet),
+		SimVarPlaneHeadingDegreesTrue(UnitDegrees),
+	}
+}
+
+// define create the definition of the positions on the first contact
+func (t *TrafficInjector) define() error {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if t.defined {
+		return nil
+	}
+	simVars := trafficSimVars()
+	defineID, err := t.esc.addDataDefinition(context.Background(), simVars)
+	if err != nil {
+		return err
+	}
+	t.defineID = defineID
+	t.defined = true
+	t.restoreID = t.esc.addRestore(func() {
+		t.esc.defineSimVars(defineID, simVars)
+	})
+	return nil
+}
+
+// run send the positions and remove the stale contacts at each Interval
+func (t *TrafficInjector) run() {
+	ticker := time.NewTicker(t.options.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+		case <-t.done:
+			return
+		case <-t.esc.ctx.Done():
+			return
+		}
+		t.update(t.now())
+	}
+}
+
+func (t *TrafficInjector) update(now time.Time) {
+	type position struct {
+		objectID uint32
+		contact  TrafficContact
+	}
+	var positions []position
+	var stale []uint32
+	t.mutex.Lock()
+	for icao, target := range t.targets {
+		if target.objectID == 0 {
+			continue
+		}
+		elapsed := now.Sub(target.contact.PositionTime)
+		if elapsed > t.options.StaleAfter {
+			delete(t.targets, icao)
+			stale = append(stale, target.objectID)
+			continue
+		}
+		positions = append(positions, position{target.objectID, target.drawn(now)})
+	}
+	defineID := t.defineID
+	t.mutex.Unlock()
+
+	for _, objectID := range stale {
+		if err := t.ai.Remove(objectID); err != nil {
+			t.esc.logf(LogInfo, "Error remove stale traffic %d : %v", objectID, err)
+		}
+	}
+	for _, p := range positions {
+		data := make([]byte, 32)
+		binary.LittleEndian.PutUint64(data, math.Float64bits(p.contact.Latitude))
+		binary.LittleEndian.PutUint64(data[8:], math.Float64bits(p.contact.Longitude))
+		binary.LittleEndian.PutUint64(data[16:], math.Float64bits(p.contact.Altitude))
+		binary.LittleEndian.PutUint64(data[24:], math.Float64bits(p.contact.Track))
+		objectID := p.objectID
+		t.esc.call("SetDataOnSimObject", p.contact.ICAO, nil, func() (error, uint32) {
+			return t.esc.sc.SetDataOnSimObject(defineID, objectID, 0, 0, uint32(len(data)), data)
+		})
+	}
+}
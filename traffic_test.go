@@ -0,0 +1,155 @@
+package simconnect
+
+import (
+	"errors"
+	"io"
+	"math"
+	"os"
+	"testing"
+	"time"
+)
+
+// readFeed return the contacts and the number of lines not understood
+func readFeed(t *testing.T, feed TrafficFeed) ([]TrafficContact, int) {
+	var contacts []TrafficContact
+	lineErrors := 0
+	for {
+		contact, err := feed.Next()
+		if err == io.EOF {
+			return contacts, lineErrors
+		}
+		var lineErr *TrafficLineError
+		if errors.As(err, &lineErr) {
+			lineErrors++
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		contacts = append(contacts, contact)
+	}
+}
+
+func TestSBSTrafficFeed(t *testing.T) {
+	file, err := os.Open("testdata/traffic.sbs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	contacts, lineErrors := readFeed(t, NewSBSTrafficFeed(file))
+	if lineErrors != 1 {
+		t.Errorf("line errors = %d, want 1", lineErrors)
+	}
+	if len(contacts) != 4 {
+		t.Fatalf("contacts = %#v", contacts)
+	}
+	ryr := contacts[1]
+	if ryr.ICAO != "4CA2D6" || ryr.Callsign != "RYR1234" || ryr.Latitude != 53.3498 || ryr.Longitude != -6.2603 ||
+		ryr.Altitude != 35000 || ryr.GroundSpeed != 450 || ryr.Track != 90 || ryr.VerticalRate != -64 || ryr.OnGround {
+		t.Errorf("contact = %#v", ryr)
+	}
+	if want := time.Date(2021, 3, 14, 12, 0, 0, 200e6, time.Local); !ryr.Time.Equal(want) {
+		t.Errorf("time = %v, want %v", ryr.Time, want)
+	}
+	// the message 4 has no position
+	if want := time.Date(2021, 3, 14, 12, 0, 0, 100e6, time.Local); !ryr.PositionTime.Equal(want) {
+		t.Errorf("position time = %v, want %v", ryr.PositionTime, want)
+	}
+	ground := contacts[3]
+	if ground.ICAO != "3C6586" || !ground.OnGround || ground.Altitude != 0 || ground.Latitude != 53.4214 {
+		t.Errorf("contact = %#v", ground)
+	}
+}
+
+func TestJSONTrafficFeed(t *testing.T) {
+	file, err := os.Open("testdata/traffic.jsonl")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	contacts, lineErrors := readFeed(t, NewJSONTrafficFeed(file))
+	if lineErrors != 2 {
+		t.Errorf("line errors = %d, want 2", lineErrors)
+	}
+	if len(contacts) != 2 {
+		t.Fatalf("contacts = %#v", contacts)
+	}
+	ryr := contacts[0]
+	if ryr.ICAO != "4CA2D6" || ryr.Callsign != "RYR1234" || ryr.GroundSpeed != 450 || ryr.VerticalRate != -64 ||
+		!ryr.Time.Equal(time.Unix(1615723200, 500e6)) {
+		t.Errorf("contact = %#v", ryr)
+	}
+	if ground := contacts[1]; !ground.OnGround || time.Since(ground.Time) > time.Minute {
+		t.Errorf("contact = %#v", ground)
+	}
+}
+
+func TestTrafficDeadReckoning(t *testing.T) {
+	// 360 knots to the east during 10s is 1 nautical mile
+	c := TrafficContact{Latitude: 0, Longitude: 0, Altitude: 1000, Track: 90, GroundSpeed: 360, VerticalRate: 600}
+	p := c.position(10 * time.Second)
+	if math.Abs(p.Longitude-1852.0/earthRadius*180/math.Pi) > 1e-9 || math.Abs(p.Latitude) > 1e-9 || p.Altitude != 1100 {
+		t.Errorf("position = %#v", p)
+	}
+	// the extrapolation is limited
+	if far := c.position(time.Hour); far.Altitude != 1000+600*maxExtrapolation.Minutes() {
+		t.Errorf("altitude = %v", far.Altitude)
+	}
+
+	// the velocity come from the previous position without speed in the feed
+	now := time.Now()
+	previous := TrafficContact{Latitude: 0, Longitude: 0, Altitude: 1000, Time: now, PositionTime: now}
+	next := TrafficContact{Latitude: 1852.0 / earthRadius * 180 / math.Pi, Longitude: 0, Altitude: 1100, Time: now.Add(10 * time.Second), PositionTime: now.Add(10 * time.Second)}
+	next.velocity(previous)
+	if math.Abs(next.GroundSpeed-360) > 1e-6 || math.Abs(next.Track) > 1e-6 || math.Abs(next.VerticalRate-600) > 1e-6 {
+		t.Errorf("velocity = %#v", next)
+	}
+	// a message without position keep the velocity
+	same := next
+	same.GroundSpeed, same.Track, same.VerticalRate, same.Time = 0, 0, 0, next.Time.Add(time.Second)
+	same.velocity(next)
+	if same.GroundSpeed != next.GroundSpeed || same.VerticalRate != next.VerticalRate {
+		t.Errorf("velocity without position = %#v", same)
+	}
+
+	// no dead reckoning before the position
+	if p := c.position(-time.Second); p != c {
+		t.Errorf("position before = %#v", p)
+	}
+}
+
+func TestTrafficBlend(t *testing.T) {
+	// 360 knots to the east, a position every 10s
+	start := time.Now()
+	target := trafficTarget{contact: TrafficContact{Latitude: 0, Longitude: 0, Altitude: 1000, Track: 90, GroundSpeed: 360, PositionTime: start}}
+	if p := target.drawn(start.Add(5 * time.Second)); math.Abs(p.Longitude-926.0/earthRadius*180/math.Pi) > 1e-9 {
+		t.Errorf("drawn = %#v", p)
+	}
+
+	// the new position is north of the dead reckoning and the track turn to 80
+	update := start.Add(10 * time.Second)
+	before := target.drawn(update)
+	next := TrafficContact{Latitude: 0.01, Longitude: before.Longitude, Altitude: 1200, Track: 80, GroundSpeed: 360, PositionTime: update}
+	target.receive(next, update)
+	after := target.drawn(update)
+	if math.Abs(after.Latitude-before.Latitude) > 1e-9 || math.Abs(after.Longitude-before.Longitude) > 1e-9 ||
+		math.Abs(after.Altitude-before.Altitude) > 1e-9 || math.Abs(after.Track-before.Track) > 1e-9 {
+		t.Errorf("drawn jump at the update = %#v, want %#v", after, before)
+	}
+	// halfway the half of the gap remain
+	half := target.drawn(update.Add(5 * time.Second))
+	if want := next.position(5 * time.Second); math.Abs(half.Latitude-(want.Latitude-0.005)) > 1e-9 || math.Abs(half.Track-85) > 1e-9 {
+		t.Errorf("drawn halfway = %#v", half)
+	}
+	// the contact is reached after the interval of the feed
+	end := update.Add(10 * time.Second)
+	if got, want := target.drawn(end), next.position(10*time.Second); got != want {
+		t.Errorf("drawn after the interval = %#v, want %#v", got, want)
+	}
+	// the track cross the north by the shortest turn
+	north := trafficTarget{contact: TrafficContact{Track: 350, PositionTime: start}}
+	north.receive(TrafficContact{Track: 10, PositionTime: start.Add(time.Second)}, start.Add(time.Second))
+	if p := north.drawn(start.Add(1500 * time.Millisecond)); math.Abs(p.Track) > 1e-9 {
+		t.Errorf("track = %v, want 0", p.Track)
+	}
+}
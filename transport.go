@@ -0,0 +1,237 @@
+package simconnect
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Transport is the link between SimConnect and the simulator.
+//
+// On Windows NewSimConnect use a Transport loading SimConnect.dll. You can give your own implementation
+// with NewSimConnectWithTransport or NewEasySimConnectWithTransport (ex: for tests or for running on linux).
+//
+// The arguments are the same as SimConnect functions without the handle. GetNextDispatch must not block and
+// return an error when no message is waiting.
+//
+// The other functions are in the optional interfaces DataTransport, SystemTransport, NotificationGroupTransport,
+// InputTransport, AITransport, WeatherTransport and FacilitiesTransport. SimConnect return ErrNotSupported when the
+// Transport don't implement the interface of a function. A Transport wrapping another Transport (ex: Recorder) can
+// give it with a method Unwrap() Transport, the optional functions are then called on the wrapped Transport.
+type Transport interface {
+	// Open SimConnect_Open(HANDLE * phSimConnect, LPCSTR szName, HWND hWnd, DWORD UserEventWin32, HANDLE hEventHandle, DWORD ConfigIndex);
+	Open(appTitle string) error
+	// Close SimConnect_Close(HANDLE hSimConnect);
+	Close() error
+	// GetLastSentPacketID SimConnect_GetLastSentPacketID(HANDLE hSimConnect, DWORD * pdwError);
+	GetLastSentPacketID() (uint32, error)
+	// GetNextDispatch SimConnect_GetNextDispatch(HANDLE hSimConnect, SIMCONNECT_RECV ** ppData, DWORD * pcbData);
+	GetNextDispatch() ([]byte, error)
+	// MapClientEventToSimEvent SimConnect_MapClientEventToSimEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * EventName = "")
+	MapClientEventToSimEvent(EventID uint32, EventName string) error
+	// TransmitClientEvent SimConnect_TransmitClientEvent(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_CLIENT_EVENT_ID EventID, DWORD dwData, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_EVENT_FLAG Flags);
+	TransmitClientEvent(ObjectID uint32, EventID uint32, dwData int, GroupID GroupPriority, Flags EventFlag) error
+	// AddClientEventToNotificationGroup SimConnect_AddClientEventToNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_CLIENT_EVENT_ID EventID, BOOL bMaskable = FALSE);
+	AddClientEventToNotificationGroup(GroupID uint32, EventID uint32, bMaskable bool) error
+	// AddToDataDefinition SimConnect_AddToDataDefinition(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID, const char * DatumName, const char * UnitsName, SIMCONNECT_DATATYPE DatumType = SIMCONNECT_DATATYPE_FLOAT64, float fEpsilon = 0, DWORD DatumID = SIMCONNECT_UNUSED);
+	AddToDataDefinition(DefineID uint32, DatumName string, UnitsName string, DatumType uint32, fEpsilon float32, DatumID uint32) error
+	// ClearDataDefinition SimConnect_ClearDataDefinition(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID);
+	ClearDataDefinition(DefineID uint32) error
+	// RequestDataOnSimObjectType SimConnect_RequestDataOnSimObjectType(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, SIMCONNECT_DATA_DEFINITION_ID DefineID, DWORD dwRadiusMeters, SIMCONNECT_SIMOBJECT_TYPE type);
+	RequestDataOnSimObjectType(RequestID uint32, DefineID uint32, dwRadiusMeters uint32, t uint32) error
+	// SetDataOnSimObject SimConnect_SetDataOnSimObject(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_SET_FLAG Flags, DWORD ArrayCount, DWORD cbUnitSize, void * pDataSet);
+	SetDataOnSimObject(DefineID uint32, ObjectID uint32, Flags uint32, ArrayCount uint32, cbUnitSize uint32, pDataSet []byte) error
+	// MapInputEventToClientEvent SimConnect_MapInputEventToClientEvent(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, const char * szInputDefinition, SIMCONNECT_CLIENT_EVENT_ID DownEventID, DWORD DownValue = 0, SIMCONNECT_CLIENT_EVENT_ID UpEventID = (SIMCONNECT_CLIENT_EVENT_ID)SIMCONNECT_UNUSED, DWORD UpValue = 0, BOOL bMaskable = FALSE);
+	MapInputEventToClientEvent(GroupID uint32, szInputDefinition string, DownEventID uint32, DownValue uint32, UpEventID uint32, UpValue uint32, bMaskable bool) error
+	// SetInputGroupState SimConnect_SetInputGroupState(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, DWORD dwState);
+	SetInputGroupState(GroupID uint32, dwState SimConnectStat) error
+	// SubscribeToSystemEvent SimConnect_SubscribeToSystemEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * SystemEventName);
+	SubscribeToSystemEvent(EventID uint32, SystemEventName SystemEvent) error
+	// Text SimConnect_Text(HANDLE hSimConnect, SIMCONNECT_TEXT_TYPE type, float fTimeSeconds, SIMCONNECT_CLIENT_EVENT_ID EventID, DWORD cbUnitSize, void * pDataSet);
+	Text(t uint32, fTimeSeconds float32, EventID uint32, pDataSet string) error
+}
+
+// DataTransport is the optional Transport of RequestDataOnSimObject
+type DataTransport interface {
+	// RequestDataOnSimObject SimConnect_RequestDataOnSimObject(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, SIMCONNECT_DATA_DEFINITION_ID DefineID, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_PERIOD Period, SIMCONNECT_DATA_REQUEST_FLAG Flags = 0, DWORD origin = 0, DWORD interval = 0, DWORD limit = 0);
+	RequestDataOnSimObject(RequestID uint32, DefineID uint32, ObjectID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) error
+}
+
+// SystemTransport is the optional Transport of the system state, the unsubscription of the system events and the response times
+type SystemTransport interface {
+	// UnsubscribeFromSystemEvent SimConnect_UnsubscribeFromSystemEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID);
+	UnsubscribeFromSystemEvent(EventID uint32) error
+	// RequestSystemState SimConnect_RequestSystemState(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szState);
+	RequestSystemState(RequestID uint32, szState string) error
+	// RequestResponseTimes SimConnect_RequestResponseTimes(HANDLE hSimConnect, DWORD nCount, float * fElapsedSeconds);
+	RequestResponseTimes(nCount uint32, fElapsedSeconds []float32) error
+}
+
+// NotificationGroupTransport is the optional Transport of the notification groups
+type NotificationGroupTransport interface {
+	// RemoveClientEvent SimConnect_RemoveClientEvent(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_CLIENT_EVENT_ID EventID);
+	RemoveClientEvent(GroupID uint32, EventID uint32) error
+	// SetNotificationGroupPriority SimConnect_SetNotificationGroupPriority(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, DWORD uPriority);
+	SetNotificationGroupPriority(GroupID uint32, uPriority GroupPriority) error
+	// ClearNotificationGroup SimConnect_ClearNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID);
+	ClearNotificationGroup(GroupID uint32) error
+	// RequestNotificationGroup SimConnect_RequestNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, DWORD dwReserved = 0, DWORD Flags = 0);
+	RequestNotificationGroup(GroupID uint32, dwReserved uint32, Flags uint32) error
+}
+
+// InputTransport is the optional Transport of the input groups and the reserved keys
+type InputTransport interface {
+	// SetInputGroupPriority SimConnect_SetInputGroupPriority(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, DWORD uPriority);
+	SetInputGroupPriority(GroupID uint32, uPriority uint32) error
+	// RemoveInputEvent SimConnect_RemoveInputEvent(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, const char * szInputDefinition);
+	RemoveInputEvent(GroupID uint32, szInputDefinition string) error
+	// ClearInputGroup SimConnect_ClearInputGroup(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID);
+	ClearInputGroup(GroupID uint32) error
+	// RequestReservedKey SimConnect_RequestReservedKey(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * szKeyChoice1 = "", const char * szKeyChoice2 = "", const char * szKeyChoice3 = "");
+	RequestReservedKey(EventID uint32, szKeyChoice1 string, szKeyChoice2 string, szKeyChoice3 string) error
+}
+
+// AITransport is the optional Transport of the AI objects
+type AITransport interface {
+	// AICreateParkedATCAircraft SimConnect_AICreateParkedATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, const char * szAirportID, SIMCONNECT_DATA_REQUEST_ID RequestID);
+	AICreateParkedATCAircraft(szContainerTitle string, szTailNumber string, szAirportID string, RequestID uint32) error
+	// AICreateEnrouteATCAircraft SimConnect_AICreateEnrouteATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, int iFlightNumber, const char * szFlightPlanPath, double dFlightPlanPosition, BOOL bTouchAndGo, SIMCONNECT_DATA_REQUEST_ID RequestID);
+	AICreateEnrouteATCAircraft(szContainerTitle string, szTailNumber string, iFlightNumber int, szFlightPlanPath string, dFlightPlanPosition float64, bTouchAndGo uint32, RequestID uint32) error
+	// AICreateNonATCAircraft SimConnect_AICreateNonATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, SIMCONNECT_DATA_INITPOSITION InitPos, SIMCONNECT_DATA_REQUEST_ID RequestID);
+	AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) error
+	// AICreateSimulatedObject SimConnect_AICreateSimulatedObject(HANDLE hSimConnect, const char * szContainerTitle, SIMCONNECT_DATA_INITPOSITION InitPos, SIMCONNECT_DATA_REQUEST_ID RequestID);
+	AICreateSimulatedObject(szContainerTitle string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) error
+	// AIReleaseControl SimConnect_AIReleaseControl(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_REQUEST_ID RequestID);
+	AIReleaseControl(ObjectID uint32, RequestID uint32) error
+	// AIRemoveObject SimConnect_AIRemoveObject(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_REQUEST_ID RequestID);
+	AIRemoveObject(ObjectID uint32, RequestID uint32) error
+	// AISetAircraftFlightPlan SimConnect_AISetAircraftFlightPlan(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, const char * szFlightPlanPath, SIMCONNECT_DATA_REQUEST_ID RequestID);
+	AISetAircraftFlightPlan(ObjectID uint32, szFlightPlanPath string, RequestID uint32) error
+}
+
+// WeatherTransport is the optional Transport of the weather
+type WeatherTransport interface {
+	// WeatherRequestInterpolatedObservation SimConnect_WeatherRequestInterpolatedObservation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float lat, float lon, float alt);
+	WeatherRequestInterpolatedObservation(RequestID uint32, lat float32, lon float32, alt float32) error
+	// WeatherRequestObservationAtStation SimConnect_WeatherRequestObservationAtStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szICAO);
+	WeatherRequestObservationAtStation(RequestID uint32, szICAO string) error
+	// WeatherRequestObservationAtNearestStation SimConnect_WeatherRequestObservationAtNearestStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float lat, float lon);
+	WeatherRequestObservationAtNearestStation(RequestID uint32, lat float32, lon float32) error
+	// WeatherCreateStation SimConnect_WeatherCreateStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szICAO, const char * szName, float lat, float lon, float alt);
+	WeatherCreateStation(RequestID uint32, szICAO string, szName string, lat float32, lon float32, alt float32) error
+	// WeatherRemoveStation SimConnect_WeatherRemoveStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szICAO);
+	WeatherRemoveStation(RequestID uint32, szICAO string) error
+	// WeatherSetObservation SimConnect_WeatherSetObservation(HANDLE hSimConnect, DWORD Seconds, const char * szMETAR);
+	WeatherSetObservation(Seconds uint32, szMETAR string) error
+	// WeatherSetModeServer SimConnect_WeatherSetModeServer(HANDLE hSimConnect, DWORD dwPort, DWORD dwSeconds);
+	WeatherSetModeServer(dwPort uint32, dwSeconds uint32) error
+	// WeatherSetModeTheme SimConnect_WeatherSetModeTheme(HANDLE hSimConnect, const char * szThemeName);
+	WeatherSetModeTheme(szThemeName string) error
+	// WeatherSetModeGlobal SimConnect_WeatherSetModeGlobal(HANDLE hSimConnect);
+	WeatherSetModeGlobal() error
+	// WeatherSetModeCustom SimConnect_WeatherSetModeCustom(HANDLE hSimConnect);
+	WeatherSetModeCustom() error
+	// WeatherSetDynamicUpdateRate SimConnect_WeatherSetDynamicUpdateRate(HANDLE hSimConnect, DWORD dwRate);
+	WeatherSetDynamicUpdateRate(dwRate uint32) error
+	// WeatherRequestCloudState SimConnect_WeatherRequestCloudState(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float minLat, float minLon, float minAlt, float maxLat, float maxLon, float maxAlt, DWORD dwFlags = 0);
+	WeatherRequestCloudState(RequestID uint32, minLat float32, minLon float32, minAlt float32, maxLat float32, maxLon float32, maxAlt float32, dwFlags uint32) error
+	// WeatherCreateThermal SimConnect_WeatherCreateThermal(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float lat, float lon, float alt, float radius, float height, float coreRate = 3.0f, float coreTurbulence = 0.05f, float sinkRate = 3.0f, float sinkTurbulence = 0.2f, float coreSize = 0.4f, float coreTransitionSize = 0.1f, float sinkLayerSize = 0.4f, float sinkTransitionSize = 0.1f);
+	WeatherCreateThermal(RequestID uint32, lat float32, lon float32, alt float32, radius float32, height float32, coreRate float32, coreTurbulence float32, sinkRate float32, sinkTurbulence float32, coreSize float32, coreTransitionSize float32, sinkLayerSize float32, sinkTransitionSize float32) error
+	// WeatherRemoveThermal SimConnect_WeatherRemoveThermal(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID);
+	WeatherRemoveThermal(ObjectID uint32) error
+}
+
+// FacilitiesTransport is the optional Transport of the facilities
+type FacilitiesTransport interface {
+	// SubscribeToFacilities SimConnect_SubscribeToFacilities(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type, SIMCONNECT_DATA_REQUEST_ID RequestID);
+	SubscribeToFacilities(listType uint32, RequestID uint32) error
+	// UnsubscribeToFacilities SimConnect_UnsubscribeToFacilities(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type);
+	UnsubscribeToFacilities(listType uint32) error
+	// RequestFacilitiesList SimConnect_RequestFacilitiesList(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type, SIMCONNECT_DATA_REQUEST_ID RequestID);
+	RequestFacilitiesList(listType uint32, RequestID uint32) error
+}
+
+// ErrNotSupported is returned by SimConnect when the Transport don't implement the optional interface of a function
+var ErrNotSupported = errors.New("not supported by the transport")
+
+// transportChain return the transport and the transports wrapped by it with Unwrap
+func transportChain(transport Transport) []Transport {
+	chain := []Transport{transport}
+	for {
+		wrapper, ok := transport.(interface{ Unwrap() Transport })
+		if !ok {
+			return chain
+		}
+		transport = wrapper.Unwrap()
+		if transport == nil {
+			return chain
+		}
+		chain = append(chain, transport)
+	}
+}
+
+func notSupported(transport Transport, name string) error {
+	return fmt.Errorf("%w : %T don't implement %s", ErrNotSupported, transport, name)
+}
+
+func (sc *SimConnect) dataTransport() (DataTransport, error) {
+	for _, transport := range transportChain(sc.transport) {
+		if t, ok := transport.(DataTransport); ok {
+			return t, nil
+		}
+	}
+	return nil, notSupported(sc.transport, "DataTransport")
+}
+
+func (sc *SimConnect) systemTransport() (SystemTransport, error) {
+	for _, transport := range transportChain(sc.transport) {
+		if t, ok := transport.(SystemTransport); ok {
+			return t, nil
+		}
+	}
+	return nil, notSupported(sc.transport, "SystemTransport")
+}
+
+func (sc *SimConnect) notificationGroupTransport() (NotificationGroupTransport, error) {
+	for _, transport := range transportChain(sc.transport) {
+		if t, ok := transport.(NotificationGroupTransport); ok {
+			return t, nil
+		}
+	}
+	return nil, notSupported(sc.transport, "NotificationGroupTransport")
+}
+
+func (sc *SimConnect) inputTransport() (InputTransport, error) {
+	for _, transport := range transportChain(sc.transport) {
+		if t, ok := transport.(InputTransport); ok {
+			return t, nil
+		}
+	}
+	return nil, notSupported(sc.transport, "InputTransport")
+}
+
+func (sc *SimConnect) aITransport() (AITransport, error) {
+	for _, transport := range transportChain(sc.transport) {
+		if t, ok := transport.(AITransport); ok {
+			return t, nil
+		}
+	}
+	return nil, notSupported(sc.transport, "AITransport")
+}
+
+func (sc *SimConnect) weatherTransport() (WeatherTransport, error) {
+	for _, transport := range transportChain(sc.transport) {
+		if t, ok := transport.(WeatherTransport); ok {
+			return t, nil
+		}
+	}
+	return nil, notSupported(sc.transport, "WeatherTransport")
+}
+
+func (sc *SimConnect) facilitiesTransport() (FacilitiesTransport, error) {
+	for _, transport := range transportChain(sc.transport) {
+		if t, ok := transport.(FacilitiesTransport); ok {
+			return t, nil
+		}
+	}
+	return nil, notSupported(sc.transport, "FacilitiesTransport")
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+// +build !windows
+
+package simconnect
+
+import "errors"
+
+func newDefaultTransport() (Transport, error) {
+	return nil, errors.New("SimConnect.dll is only available on windows, please use NewSimConnectWithTransport")
+}
@@ -0,0 +1,42 @@
+package simconnect_test
+
+import (
+	"errors"
+	"io/ioutil"
+	"testing"
+
+	sim "github.com/micmonay/simconnect"
+	"github.com/micmonay/simconnect/simconnecttest"
+)
+
+// coreTransport hide the optional interfaces of the Transport
+type coreTransport struct {
+	sim.Transport
+}
+
+func TestOptionalTransport(t *testing.T) {
+	fake := simconnecttest.NewSimulator()
+	sc := sim.NewSimConnectWithTransport(coreTransport{fake})
+	if err, _ := sc.Open("TestApp"); err != nil {
+		t.Fatal(err)
+	}
+	if err, _ := sc.WeatherSetModeGlobal(); !errors.Is(err, sim.ErrNotSupported) {
+		t.Errorf("err = %v, want ErrNotSupported", err)
+	}
+	if err, _ := sc.SubscribeToSystemEvent(1, sim.SystemEventSimStart); err != nil {
+		t.Errorf("SubscribeToSystemEvent = %v", err)
+	}
+
+	// the optional functions of a Recorder are called on his Transport
+	recorder, err := sim.NewRecorder(fake, ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc = sim.NewSimConnectWithTransport(recorder)
+	if err, _ := sc.WeatherSetModeCustom(); err != nil {
+		t.Errorf("WeatherSetModeCustom = %v", err)
+	}
+	if mode, _, _, _ := fake.WeatherMode(); mode != sim.SIMCONNECT_WEATHER_MODE_CUSTOM {
+		t.Errorf("mode = %d, want custom", mode)
+	}
+}
@@ -0,0 +1,315 @@
+package simconnect
+
+import (
+	"math"
+	"unsafe"
+)
+
+// convert string to const char *
+func cChar(str string) uintptr {
+	b := []byte(str + "\x00")
+	return uintptr(unsafe.Pointer(&b[0]))
+}
+
+func cBool(b bool) uintptr {
+	mask := 0
+	if b {
+		mask = 1
+	}
+	return uintptr(mask)
+}
+
+var (
+	_ Transport                  = (*DLLTransport)(nil)
+	_ DataTransport              = (*DLLTransport)(nil)
+	_ SystemTransport            = (*DLLTransport)(nil)
+	_ NotificationGroupTransport = (*DLLTransport)(nil)
+	_ InputTransport             = (*DLLTransport)(nil)
+	_ AITransport                = (*DLLTransport)(nil)
+	_ WeatherTransport           = (*DLLTransport)(nil)
+	_ FacilitiesTransport        = (*DLLTransport)(nil)
+)
+
+// DLLTransport is the Transport using SimConnect.dll
+type DLLTransport struct {
+	hSimConnect uintptr
+	syscallSC   *SyscallSC
+}
+
+// NewDLLTransport load SimConnect.dll and return a Transport or error if the dll is not found
+func NewDLLTransport() (*DLLTransport, error) {
+	syscallSC, err := NewSyscallSC()
+	if err != nil {
+		return nil, err
+	}
+	return &DLLTransport{syscallSC: syscallSC}, nil
+}
+
+func newDefaultTransport() (Transport, error) {
+	return NewDLLTransport()
+}
+
+// Open SimConnect_Open(HANDLE * phSimConnect, LPCSTR szName, HWND hWnd, DWORD UserEventWin32, HANDLE hEventHandle, DWORD ConfigIndex);
+func (t *DLLTransport) Open(appTitle string) error {
+	return t.syscallSC.Open(uintptr(unsafe.Pointer(&t.hSimConnect)), cChar(appTitle), uintptr(unsafe.Pointer(nil)), 0, 0, 0)
+}
+
+// Close SimConnect_Close(HANDLE hSimConnect);
+func (t *DLLTransport) Close() error {
+	return t.syscallSC.Close(t.hSimConnect)
+}
+
+// GetLastSentPacketID SimConnect_GetLastSentPacketID(HANDLE hSimConnect, DWORD * pdwError);
+func (t *DLLTransport) GetLastSentPacketID() (uint32, error) {
+	var id uint32
+	err := t.syscallSC.GetLastSentPacketID(t.hSimConnect, uintptr(unsafe.Pointer(&id)))
+	return id, err
+}
+
+// GetNextDispatch SimConnect_GetNextDispatch(HANDLE hSimConnect, SIMCONNECT_RECV ** ppData, DWORD * pcbData);
+func (t *DLLTransport) GetNextDispatch() ([]byte, error) {
+	var ppData unsafe.Pointer
+	var pcbData uint32
+	err := t.syscallSC.GetNextDispatch(t.hSimConnect, uintptr(unsafe.Pointer(&ppData)), uintptr(unsafe.Pointer(&pcbData)))
+	if err != nil {
+		return nil, err
+	}
+	return convCBytesToGoBytes(ppData, int(pcbData))
+}
+
+// RequestResponseTimes SimConnect_RequestResponseTimes(HANDLE hSimConnect, DWORD nCount, float * fElapsedSeconds);
+func (t *DLLTransport) RequestResponseTimes(nCount uint32, fElapsedSeconds []float32) error {
+	return t.syscallSC.RequestResponseTimes(t.hSimConnect, uintptr(nCount), uintptr(unsafe.Pointer(&fElapsedSeconds[0])))
+}
+
+// MapClientEventToSimEvent SimConnect_MapClientEventToSimEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * EventName = "")
+func (t *DLLTransport) MapClientEventToSimEvent(EventID uint32, EventName string) error {
+	return t.syscallSC.MapClientEventToSimEvent(t.hSimConnect, uintptr(EventID), cChar(EventName))
+}
+
+// TransmitClientEvent SimConnect_TransmitClientEvent(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_CLIENT_EVENT_ID EventID, DWORD dwData, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_EVENT_FLAG Flags);
+func (t *DLLTransport) TransmitClientEvent(ObjectID uint32, EventID uint32, dwData int, GroupID GroupPriority, Flags EventFlag) error {
+	return t.syscallSC.TransmitClientEvent(t.hSimConnect, uintptr(ObjectID), uintptr(EventID), uintptr(dwData), uintptr(GroupID), uintptr(Flags))
+}
+
+// AddClientEventToNotificationGroup SimConnect_AddClientEventToNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_CLIENT_EVENT_ID EventID, BOOL bMaskable = FALSE);
+func (t *DLLTransport) AddClientEventToNotificationGroup(GroupID uint32, EventID uint32, bMaskable bool) error {
+	return t.syscallSC.AddClientEventToNotificationGroup(t.hSimConnect, uintptr(GroupID), uintptr(EventID), cBool(bMaskable))
+}
+
+// RemoveClientEvent SimConnect_RemoveClientEvent(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, SIMCONNECT_CLIENT_EVENT_ID EventID);
+func (t *DLLTransport) RemoveClientEvent(GroupID uint32, EventID uint32) error {
+	return t.syscallSC.RemoveClientEvent(t.hSimConnect, uintptr(GroupID), uintptr(EventID))
+}
+
+// SetNotificationGroupPriority SimConnect_SetNotificationGroupPriority(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, DWORD uPriority);
+func (t *DLLTransport) SetNotificationGroupPriority(GroupID uint32, uPriority GroupPriority) error {
+	return t.syscallSC.SetNotificationGroupPriority(t.hSimConnect, uintptr(GroupID), uintptr(uPriority))
+}
+
+// ClearNotificationGroup SimConnect_ClearNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID);
+func (t *DLLTransport) ClearNotificationGroup(GroupID uint32) error {
+	return t.syscallSC.ClearNotificationGroup(t.hSimConnect, uintptr(GroupID))
+}
+
+// RequestNotificationGroup SimConnect_RequestNotificationGroup(HANDLE hSimConnect, SIMCONNECT_NOTIFICATION_GROUP_ID GroupID, DWORD dwReserved = 0, DWORD Flags = 0);
+func (t *DLLTransport) RequestNotificationGroup(GroupID uint32, dwReserved uint32, Flags uint32) error {
+	return t.syscallSC.RequestNotificationGroup(t.hSimConnect, uintptr(GroupID), uintptr(dwReserved), uintptr(Flags))
+}
+
+// AddToDataDefinition SimConnect_AddToDataDefinition(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID, const char * DatumName, const char * UnitsName, SIMCONNECT_DATATYPE DatumType = SIMCONNECT_DATATYPE_FLOAT64, float fEpsilon = 0, DWORD DatumID = SIMCONNECT_UNUSED);
+func (t *DLLTransport) AddToDataDefinition(DefineID uint32, DatumName string, UnitsName string, DatumType uint32, fEpsilon float32, DatumID uint32) error {
+	return t.syscallSC.AddToDataDefinition(t.hSimConnect, uintptr(DefineID), cChar(DatumName), cChar(UnitsName), uintptr(DatumType), uintptr(fEpsilon), uintptr(DatumID))
+}
+
+// ClearDataDefinition SimConnect_ClearDataDefinition(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID);
+func (t *DLLTransport) ClearDataDefinition(DefineID uint32) error {
+	return t.syscallSC.ClearDataDefinition(t.hSimConnect, uintptr(DefineID))
+}
+
+// RequestDataOnSimObject SimConnect_RequestDataOnSimObject(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, SIMCONNECT_DATA_DEFINITION_ID DefineID, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_PERIOD Period, SIMCONNECT_DATA_REQUEST_FLAG Flags = 0, DWORD origin = 0, DWORD interval = 0, DWORD limit = 0);
+func (t *DLLTransport) RequestDataOnSimObject(RequestID uint32, DefineID uint32, ObjectID uint32, Period uint32, Flags uint32, origin uint32, interval uint32, limit uint32) error {
+	return t.syscallSC.RequestDataOnSimObject(t.hSimConnect, uintptr(RequestID), uintptr(DefineID), uintptr(ObjectID), uintptr(Period), uintptr(Flags), uintptr(origin), uintptr(interval), uintptr(limit))
+}
+
+// RequestDataOnSimObjectType SimConnect_RequestDataOnSimObjectType(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, SIMCONNECT_DATA_DEFINITION_ID DefineID, DWORD dwRadiusMeters, SIMCONNECT_SIMOBJECT_TYPE type);
+func (t *DLLTransport) RequestDataOnSimObjectType(RequestID uint32, DefineID uint32, dwRadiusMeters uint32, ty uint32) error {
+	return t.syscallSC.RequestDataOnSimObjectType(t.hSimConnect, uintptr(RequestID), uintptr(DefineID), uintptr(dwRadiusMeters), uintptr(ty))
+}
+
+// SetDataOnSimObject SimConnect_SetDataOnSimObject(HANDLE hSimConnect, SIMCONNECT_DATA_DEFINITION_ID DefineID, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_SET_FLAG Flags, DWORD ArrayCount, DWORD cbUnitSize, void * pDataSet);
+func (t *DLLTransport) SetDataOnSimObject(DefineID uint32, ObjectID uint32, Flags uint32, ArrayCount uint32, cbUnitSize uint32, pDataSet []byte) error {
+	return t.syscallSC.SetDataOnSimObject(t.hSimConnect, uintptr(DefineID), uintptr(ObjectID), uintptr(Flags), uintptr(ArrayCount), uintptr(cbUnitSize), uintptr(unsafe.Pointer(&pDataSet[0])))
+}
+
+// MapInputEventToClientEvent SimConnect_MapInputEventToClientEvent(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, const char * szInputDefinition, SIMCONNECT_CLIENT_EVENT_ID DownEventID, DWORD DownValue = 0, SIMCONNECT_CLIENT_EVENT_ID UpEventID = (SIMCONNECT_CLIENT_EVENT_ID)SIMCONNECT_UNUSED, DWORD UpValue = 0, BOOL bMaskable = FALSE);
+func (t *DLLTransport) MapInputEventToClientEvent(GroupID uint32, szInputDefinition string, DownEventID uint32, DownValue uint32, UpEventID uint32, UpValue uint32, bMaskable bool) error {
+	return t.syscallSC.MapInputEventToClientEvent(t.hSimConnect, uintptr(GroupID), cChar(szInputDefinition), uintptr(DownEventID), uintptr(DownValue), uintptr(UpEventID), uintptr(UpValue), cBool(bMaskable))
+}
+
+// SetInputGroupPriority SimConnect_SetInputGroupPriority(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, DWORD uPriority);
+func (t *DLLTransport) SetInputGroupPriority(GroupID uint32, uPriority uint32) error {
+	return t.syscallSC.SetInputGroupPriority(t.hSimConnect, uintptr(GroupID), uintptr(uPriority))
+}
+
+// RemoveInputEvent SimConnect_RemoveInputEvent(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, const char * szInputDefinition);
+func (t *DLLTransport) RemoveInputEvent(GroupID uint32, szInputDefinition string) error {
+	return t.syscallSC.RemoveInputEvent(t.hSimConnect, uintptr(GroupID), cChar(szInputDefinition))
+}
+
+// ClearInputGroup SimConnect_ClearInputGroup(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID);
+func (t *DLLTransport) ClearInputGroup(GroupID uint32) error {
+	return t.syscallSC.ClearInputGroup(t.hSimConnect, uintptr(GroupID))
+}
+
+// SetInputGroupState SimConnect_SetInputGroupState(HANDLE hSimConnect, SIMCONNECT_INPUT_GROUP_ID GroupID, DWORD dwState);
+func (t *DLLTransport) SetInputGroupState(GroupID uint32, dwState SimConnectStat) error {
+	return t.syscallSC.SetInputGroupState(t.hSimConnect, uintptr(GroupID), uintptr(dwState))
+}
+
+// RequestReservedKey SimConnect_RequestReservedKey(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * szKeyChoice1 = "", const char * szKeyChoice2 = "", const char * szKeyChoice3 = "");
+func (t *DLLTransport) RequestReservedKey(EventID uint32, szKeyChoice1 string, szKeyChoice2 string, szKeyChoice3 string) error {
+	return t.syscallSC.RequestReservedKey(t.hSimConnect, uintptr(EventID), cChar(szKeyChoice1), cChar(szKeyChoice2), cChar(szKeyChoice3))
+}
+
+// SubscribeToSystemEvent SimConnect_SubscribeToSystemEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID, const char * SystemEventName);
+func (t *DLLTransport) SubscribeToSystemEvent(EventID uint32, SystemEventName SystemEvent) error {
+	return t.syscallSC.SubscribeToSystemEvent(t.hSimConnect, uintptr(EventID), cChar(string(SystemEventName)))
+}
+
+// UnsubscribeFromSystemEvent SimConnect_UnsubscribeFromSystemEvent(HANDLE hSimConnect, SIMCONNECT_CLIENT_EVENT_ID EventID);
+func (t *DLLTransport) UnsubscribeFromSystemEvent(EventID uint32) error {
+	return t.syscallSC.UnsubscribeFromSystemEvent(t.hSimConnect, uintptr(EventID))
+}
+
+// WeatherRequestInterpolatedObservation SimConnect_WeatherRequestInterpolatedObservation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float lat, float lon, float alt);
+func (t *DLLTransport) WeatherRequestInterpolatedObservation(RequestID uint32, lat float32, lon float32, alt float32) error {
+	return t.syscallSC.WeatherRequestInterpolatedObservation(t.hSimConnect, uintptr(RequestID), uintptr(math.Float32bits(lat)), uintptr(math.Float32bits(lon)), uintptr(math.Float32bits(alt)))
+}
+
+// WeatherRequestObservationAtStation SimConnect_WeatherRequestObservationAtStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szICAO);
+func (t *DLLTransport) WeatherRequestObservationAtStation(RequestID uint32, szICAO string) error {
+	return t.syscallSC.WeatherRequestObservationAtStation(t.hSimConnect, uintptr(RequestID), cChar(szICAO))
+}
+
+// WeatherRequestObservationAtNearestStation SimConnect_WeatherRequestObservationAtNearestStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float lat, float lon);
+func (t *DLLTransport) WeatherRequestObservationAtNearestStation(RequestID uint32, lat float32, lon float32) error {
+	return t.syscallSC.WeatherRequestObservationAtNearestStation(t.hSimConnect, uintptr(RequestID), uintptr(math.Float32bits(lat)), uintptr(math.Float32bits(lon)))
+}
+
+// WeatherCreateStation SimConnect_WeatherCreateStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szICAO, const char * szName, float lat, float lon, float alt);
+func (t *DLLTransport) WeatherCreateStation(RequestID uint32, szICAO string, szName string, lat float32, lon float32, alt float32) error {
+	return t.syscallSC.WeatherCreateStation(t.hSimConnect, uintptr(RequestID), cChar(szICAO), cChar(szName), uintptr(math.Float32bits(lat)), uintptr(math.Float32bits(lon)), uintptr(math.Float32bits(alt)))
+}
+
+// WeatherRemoveStation SimConnect_WeatherRemoveStation(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szICAO);
+func (t *DLLTransport) WeatherRemoveStation(RequestID uint32, szICAO string) error {
+	return t.syscallSC.WeatherRemoveStation(t.hSimConnect, uintptr(RequestID), cChar(szICAO))
+}
+
+// WeatherSetObservation SimConnect_WeatherSetObservation(HANDLE hSimConnect, DWORD Seconds, const char * szMETAR);
+func (t *DLLTransport) WeatherSetObservation(Seconds uint32, szMETAR string) error {
+	return t.syscallSC.WeatherSetObservation(t.hSimConnect, uintptr(Seconds), cChar(szMETAR))
+}
+
+// WeatherSetModeServer SimConnect_WeatherSetModeServer(HANDLE hSimConnect, DWORD dwPort, DWORD dwSeconds);
+func (t *DLLTransport) WeatherSetModeServer(dwPort uint32, dwSeconds uint32) error {
+	return t.syscallSC.WeatherSetModeServer(t.hSimConnect, uintptr(dwPort), uintptr(dwSeconds))
+}
+
+// WeatherSetModeTheme SimConnect_WeatherSetModeTheme(HANDLE hSimConnect, const char * szThemeName);
+func (t *DLLTransport) WeatherSetModeTheme(szThemeName string) error {
+	return t.syscallSC.WeatherSetModeTheme(t.hSimConnect, cChar(szThemeName))
+}
+
+// WeatherSetModeGlobal SimConnect_WeatherSetModeGlobal(HANDLE hSimConnect);
+func (t *DLLTransport) WeatherSetModeGlobal() error {
+	return t.syscallSC.WeatherSetModeGlobal(t.hSimConnect)
+}
+
+// WeatherSetModeCustom SimConnect_WeatherSetModeCustom(HANDLE hSimConnect);
+func (t *DLLTransport) WeatherSetModeCustom() error {
+	return t.syscallSC.WeatherSetModeCustom(t.hSimConnect)
+}
+
+// WeatherSetDynamicUpdateRate SimConnect_WeatherSetDynamicUpdateRate(HANDLE hSimConnect, DWORD dwRate);
+func (t *DLLTransport) WeatherSetDynamicUpdateRate(dwRate uint32) error {
+	return t.syscallSC.WeatherSetDynamicUpdateRate(t.hSimConnect, uintptr(dwRate))
+}
+
+// WeatherRequestCloudState SimConnect_WeatherRequestCloudState(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float minLat, float minLon, float minAlt, float maxLat, float maxLon, float maxAlt, DWORD dwFlags = 0);
+func (t *DLLTransport) WeatherRequestCloudState(RequestID uint32, minLat float32, minLon float32, minAlt float32, maxLat float32, maxLon float32, maxAlt float32, dwFlags uint32) error {
+	return t.syscallSC.WeatherRequestCloudState(t.hSimConnect, uintptr(RequestID), uintptr(math.Float32bits(minLat)), uintptr(math.Float32bits(minLon)), uintptr(math.Float32bits(minAlt)), uintptr(math.Float32bits(maxLat)), uintptr(math.Float32bits(maxLon)), uintptr(math.Float32bits(maxAlt)), uintptr(dwFlags))
+}
+
+// WeatherCreateThermal SimConnect_WeatherCreateThermal(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, float lat, float lon, float alt, float radius, float height, float coreRate = 3.0f, float coreTurbulence = 0.05f, float sinkRate = 3.0f, float sinkTurbulence = 0.2f, float coreSize = 0.4f, float coreTransitionSize = 0.1f, float sinkLayerSize = 0.4f, float sinkTransitionSize = 0.1f);
+func (t *DLLTransport) WeatherCreateThermal(RequestID uint32, lat float32, lon float32, alt float32, radius float32, height float32, coreRate float32, coreTurbulence float32, sinkRate float32, sinkTurbulence float32, coreSize float32, coreTransitionSize float32, sinkLayerSize float32, sinkTransitionSize float32) error {
+	return t.syscallSC.WeatherCreateThermal(t.hSimConnect, uintptr(RequestID), uintptr(math.Float32bits(lat)), uintptr(math.Float32bits(lon)), uintptr(math.Float32bits(alt)), uintptr(math.Float32bits(radius)), uintptr(math.Float32bits(height)), uintptr(math.Float32bits(coreRate)), uintptr(math.Float32bits(coreTurbulence)), uintptr(math.Float32bits(sinkRate)), uintptr(math.Float32bits(sinkTurbulence)), uintptr(math.Float32bits(coreSize)), uintptr(math.Float32bits(coreTransitionSize)), uintptr(math.Float32bits(sinkLayerSize)), uintptr(math.Float32bits(sinkTransitionSize)))
+}
+
+// WeatherRemoveThermal SimConnect_WeatherRemoveThermal(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID);
+func (t *DLLTransport) WeatherRemoveThermal(ObjectID uint32) error {
+	return t.syscallSC.WeatherRemoveThermal(t.hSimConnect, uintptr(ObjectID))
+}
+
+// RequestSystemState SimConnect_RequestSystemState(HANDLE hSimConnect, SIMCONNECT_DATA_REQUEST_ID RequestID, const char * szState);
+func (t *DLLTransport) RequestSystemState(RequestID uint32, szState string) error {
+	return t.syscallSC.RequestSystemState(t.hSimConnect, uintptr(RequestID), cChar(szState))
+}
+
+// Text SimConnect_Text(HANDLE hSimConnect, SIMCONNECT_TEXT_TYPE type, float fTimeSeconds, SIMCONNECT_CLIENT_EVENT_ID EventID, DWORD cbUnitSize, void * pDataSet);
+func (t *DLLTransport) Text(ty uint32, fTimeSeconds float32, EventID uint32, pDataSet string) error {
+	str := convGoStringtoBytes(pDataSet)
+	size := len(str)
+	return t.syscallSC.Text(t.hSimConnect, uintptr(ty), uintptr(fTimeSeconds), uintptr(EventID), uintptr(size), uintptr(unsafe.Pointer(&str[0])))
+}
+
+// AICreateParkedATCAircraft SimConnect_AICreateParkedATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, const char * szAirportID, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *DLLTransport) AICreateParkedATCAircraft(szContainerTitle string, szTailNumber string, szAirportID string, RequestID uint32) error {
+	return t.syscallSC.AICreateParkedATCAircraft(t.hSimConnect, cChar(szContainerTitle), cChar(szTailNumber), cChar(szAirportID), uintptr(RequestID))
+}
+
+// AICreateEnrouteATCAircraft SimConnect_AICreateEnrouteATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, int iFlightNumber, const char * szFlightPlanPath, double dFlightPlanPosition, BOOL bTouchAndGo, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *DLLTransport) AICreateEnrouteATCAircraft(szContainerTitle string, szTailNumber string, iFlightNumber int, szFlightPlanPath string, dFlightPlanPosition float64, bTouchAndGo uint32, RequestID uint32) error {
+	return t.syscallSC.AICreateEnrouteATCAircraft(t.hSimConnect, cChar(szContainerTitle), cChar(szTailNumber), uintptr(iFlightNumber), cChar(szFlightPlanPath), uintptr(math.Float64bits(dFlightPlanPosition)), uintptr(bTouchAndGo), uintptr(RequestID))
+}
+
+// AICreateNonATCAircraft SimConnect_AICreateNonATCAircraft(HANDLE hSimConnect, const char * szContainerTitle, const char * szTailNumber, SIMCONNECT_DATA_INITPOSITION InitPos, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *DLLTransport) AICreateNonATCAircraft(szContainerTitle string, szTailNumber string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) error {
+	return t.syscallSC.AICreateNonATCAircraft(t.hSimConnect, cChar(szContainerTitle), cChar(szTailNumber), uintptr(unsafe.Pointer(&InitPos)), uintptr(RequestID))
+}
+
+// AICreateSimulatedObject SimConnect_AICreateSimulatedObject(HANDLE hSimConnect, const char * szContainerTitle, SIMCONNECT_DATA_INITPOSITION InitPos, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *DLLTransport) AICreateSimulatedObject(szContainerTitle string, InitPos SIMCONNECT_DATA_INITPOSITION, RequestID uint32) error {
+	return t.syscallSC.AICreateSimulatedObject(t.hSimConnect, cChar(szContainerTitle), uintptr(unsafe.Pointer(&InitPos)), uintptr(RequestID))
+}
+
+// AIReleaseControl SimConnect_AIReleaseControl(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *DLLTransport) AIReleaseControl(ObjectID uint32, RequestID uint32) error {
+	return t.syscallSC.AIReleaseControl(t.hSimConnect, uintptr(ObjectID), uintptr(RequestID))
+}
+
+// AIRemoveObject SimConnect_AIRemoveObject(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *DLLTransport) AIRemoveObject(ObjectID uint32, RequestID uint32) error {
+	return t.syscallSC.AIRemoveObject(t.hSimConnect, uintptr(ObjectID), uintptr(RequestID))
+}
+
+// AISetAircraftFlightPlan SimConnect_AISetAircraftFlightPlan(HANDLE hSimConnect, SIMCONNECT_OBJECT_ID ObjectID, const char * szFlightPlanPath, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *DLLTransport) AISetAircraftFlightPlan(ObjectID uint32, szFlightPlanPath string, RequestID uint32) error {
+	return t.syscallSC.AISetAircraftFlightPlan(t.hSimConnect, uintptr(ObjectID), cChar(szFlightPlanPath), uintptr(RequestID))
+}
+
+// SubscribeToFacilities SimConnect_SubscribeToFacilities(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *DLLTransport) SubscribeToFacilities(listType uint32, RequestID uint32) error {
+	return t.syscallSC.SubscribeToFacilities(t.hSimConnect, uintptr(listType), uintptr(RequestID))
+}
+
+// UnsubscribeToFacilities SimConnect_UnsubscribeToFacilities(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type);
+func (t *DLLTransport) UnsubscribeToFacilities(listType uint32) error {
+	return t.syscallSC.UnsubscribeToFacilities(t.hSimConnect, uintptr(listType))
+}
+
+// RequestFacilitiesList SimConnect_RequestFacilitiesList(HANDLE hSimConnect, SIMCONNECT_FACILITY_LIST_TYPE type, SIMCONNECT_DATA_REQUEST_ID RequestID);
+func (t *DLLTransport) RequestFacilitiesList(listType uint32, RequestID uint32) error {
+	return t.syscallSC.RequestFacilitiesList(t.hSimConnect, uintptr(listType), uintptr(RequestID))
+}
@@ -0,0 +1,214 @@
+package simconnect
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// weatherTimeout is the maximum time for waiting a weather observation
+const weatherTimeout = 10 * time.Second
+
+// WeatherService read and change the weather of the simulator with METARs. The stations and the thermals created are
+// removed by Close.
+//
+//	weather := esc.NewWeatherService()
+//	defer weather.Close()
+//	metar, err := weather.ObservationAtStation("LFPG")
+//	weather.SetObservation(0, "GLOB 121200Z 27010KT 9999 SCT030 15/10 Q1013")
+type WeatherService struct {
+	esc      *EasySimConnect
+	mutex    sync.Mutex
+	stations map[string]bool
+	thermals map[uint32]bool
+	modes    []<-chan uint32
+	creating sync.WaitGroup // the creations of stations and thermals in progress
+	closed   bool
+}
+
+// NewWeatherService return a WeatherService, Close remove the stations and the thermals created
+func (esc *EasySimConnect) NewWeatherService() *WeatherService {
+	return &WeatherService{esc: esc, stations: make(map[string]bool), thermals: make(map[uint32]bool)}
+}
+
+// Observation return the METAR interpolated at the position (degrees and meters)
+func (w *WeatherService) Observation(latitude float32, longitude float32, altitude float32) (string, error) {
+	return w.observation("WeatherRequestInterpolatedObservation", fmt.Sprintf("%g %g %g", latitude, longitude, altitude), func(requestID uint32) (error, uint32) {
+		return w.esc.sc.WeatherRequestInterpolatedObservation(requestID, latitude, longitude, altitude)
+	})
+}
+
+// ObservationAtStation return the METAR of the station (ex: "KSEA")
+func (w *WeatherService) ObservationAtStation(icao string) (string, error) {
+	return w.observation("WeatherRequestObservationAtStation", icao, func(requestID uint32) (error, uint32) {
+		return w.esc.sc.WeatherRequestObservationAtStation(requestID, icao)
+	})
+}
+
+// ObservationAtNearestStation return the METAR of the nearest station of the position (degrees)
+func (w *WeatherService) ObservationAtNearestStation(latitude float32, longitude float32) (string, error) {
+	return w.observation("WeatherRequestObservationAtNearestStation", fmt.Sprintf("%g %g", latitude, longitude), func(requestID uint32) (error, uint32) {
+		return w.esc.sc.WeatherRequestObservationAtNearestStation(requestID, latitude, longitude)
+	})
+}
+
+// CloudState return the cloud densities of the zone (degrees and feet)
+func (w *WeatherService) CloudState(minLatitude float32, minLongitude float32, minAltitude float32, maxLatitude float32, maxLongitude float32, maxAltitude float32) (*CloudGrid, error) {
+	detail := fmt.Sprintf("%g %g %g %g %g %g", minLatitude, minLongitude, minAltitude, maxLatitude, maxLongitude, maxAltitude)
+	recv, err := w.esc.callRequest("WeatherRequestCloudState", detail, weatherTimeout, func(requestID uint32) (error, uint32) {
+		return w.esc.sc.WeatherRequestCloudState(requestID, minLatitude, minLongitude, minAltitude, maxLatitude, maxLongitude, maxAltitude, 0)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return newCloudGrid(recv.(*CloudStateMessage).Data, minLatitude, minLongitude, minAltitude, maxLatitude, maxLongitude, maxAltitude)
+}
+
+// SetObservation set the METAR of a station, the station GLOB set the global weather. The weather change during
+// seconds, 0 is immediately. The mode must be custom (SetModeCustom) for the stations.
+func (w *WeatherService) SetObservation(seconds uint32, metar string) error {
+	return w.esc.callWait("WeatherSetObservation", metar, func() (error, uint32) {
+		return w.esc.sc.WeatherSetObservation(seconds, metar)
+	})
+}
+
+// CreateStation create a weather station at the position (degrees and meters), the station is removed by Close
+func (w *WeatherService) CreateStation(icao string, name string, latitude float32, longitude float32, altitude float32) error {
+	icao = strings.ToUpper(icao)
+	if err := w.startCreation(); err != nil {
+		return err
+	}
+	defer w.creating.Done()
+	err := w.esc.callWait("WeatherCreateStation", icao, func() (error, uint32) {
+		return w.esc.sc.WeatherCreateStation(0, icao, name, latitude, longitude, altitude)
+	})
+	if err != nil {
+		return err
+	}
+	w.mutex.Lock()
+	w.stations[icao] = true
+	w.mutex.Unlock()
+	return nil
+}
+
+// RemoveStation remove a weather station created by CreateStation
+func (w *WeatherService) RemoveStation(icao string) error {
+	icao = strings.ToUpper(icao)
+	err := w.esc.callWait("WeatherRemoveStation", icao, func() (error, uint32) {
+		return w.esc.sc.WeatherRemoveStation(0, icao)
+	})
+	if err != nil {
+		// the station is kept for Close
+		return err
+	}
+	w.mutex.Lock()
+	delete(w.stations, icao)
+	w.mutex.Unlock()
+	return nil
+}
+
+// Stations return the ICAO of the stations created and not removed
+func (w *WeatherService) Stations() []string {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	stations := make([]string, 0, len(w.stations))
+	for icao := range w.stations {
+		stations = append(stations, icao)
+	}
+	sort.Strings(stations)
+	return stations
+}
+
+// SetModeServer use the weather server on the port, the weather is updated every seconds
+func (w *WeatherService) SetModeServer(port uint32, seconds uint32) error {
+	return w.esc.callWait("WeatherSetModeServer", fmt.Sprint(port), func() (error, uint32) {
+		return w.esc.sc.WeatherSetModeServer(port, seconds)
+	})
+}
+
+// SetModeTheme use a weather theme (ex: "Stormy")
+func (w *WeatherService) SetModeTheme(theme string) error {
+	return w.esc.callWait("WeatherSetModeTheme", theme, func() (error, uint32) {
+		return w.esc.sc.WeatherSetModeTheme(theme)
+	})
+}
+
+// SetModeGlobal use the same weather everywhere, set by the station GLOB
+func (w *WeatherService) SetModeGlobal() error {
+	return w.esc.callWait("WeatherSetModeGlobal", "", func() (error, uint32) {
+		return w.esc.sc.WeatherSetModeGlobal()
+	})
+}
+
+// SetModeCustom use the weather of the stations set by SetObservation
+func (w *WeatherService) SetModeCustom() error {
+	return w.esc.callWait("WeatherSetModeCustom", "", func() (error, uint32) {
+		return w.esc.sc.WeatherSetModeCustom()
+	})
+}
+
+// SetDynamicUpdateRate set the rate of the weather changes, 0 stop the changes and 5 is the fastest
+func (w *WeatherService) SetDynamicUpdateRate(rate uint32) error {
+	return w.esc.callWait("WeatherSetDynamicUpdateRate", fmt.Sprint(rate), func() (error, uint32) {
+		return w.esc.sc.WeatherSetDynamicUpdateRate(rate)
+	})
+}
+
+// ModeChanges return a chan receiving the new mode (SIMCONNECT_WEATHER_MODE_*) at each change, the chan is unsubscribed by Close
+func (w *WeatherService) ModeChanges() <-chan uint32 {
+	c := w.esc.ConnectSysEventWeatherModeChanged()
+	w.mutex.Lock()
+	w.modes = append(w.modes, c)
+	w.mutex.Unlock()
+	return c
+}
+
+// Close remove the stations and the thermals created and unsubscribe the chans of ModeChanges. The creations in
+// progress are waited. Call Close again retry the stations and the thermals not removed.
+func (w *WeatherService) Close() error {
+	w.mutex.Lock()
+	w.closed = true
+	modes := w.modes
+	w.modes = nil
+	w.mutex.Unlock()
+	w.creating.Wait()
+	var err error
+	for _, icao := range w.Stations() {
+		if e := w.RemoveStation(icao); e != nil && err == nil {
+			err = e
+		}
+	}
+	for _, objectID := range w.Thermals() {
+		if e := w.RemoveThermal(objectID); e != nil && err == nil {
+			err = e
+		}
+	}
+	for _, c := range modes {
+		if sub, e := w.esc.Subscription(c); e == nil {
+			sub.Unsubscribe()
+		}
+	}
+	return err
+}
+
+// startCreation count a creation waited by Close, return an error if the WeatherService is closed
+func (w *WeatherService) startCreation() error {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.closed {
+		return errors.New("WeatherService is closed")
+	}
+	w.creating.Add(1)
+	return nil
+}
+
+func (w *WeatherService) observation(op string, detail string, f func(requestID uint32) (error, uint32)) (string, error) {
+	recv, err := w.esc.callRequest(op, detail, weatherTimeout, f)
+	if err != nil {
+		return "", err
+	}
+	return recv.(*WeatherObservationMessage).Metar, nil
+}